@@ -0,0 +1,31 @@
+package main
+
+// Application review states
+const (
+	ApplicationStatusPending     = "pending"
+	ApplicationStatusShortlisted = "shortlisted"
+	ApplicationStatusApproved    = "approved"
+	ApplicationStatusRejected    = "rejected"
+	ApplicationStatusWithdrawn   = "withdrawn" // Set by the creator; final
+)
+
+// applicationTransitions lists the states each application state may move to.
+// A rejected application must be reconsidered (pending or shortlisted) before
+// it can be approved.
+var applicationTransitions = map[string][]string{
+	ApplicationStatusPending:     {ApplicationStatusShortlisted, ApplicationStatusApproved, ApplicationStatusRejected, ApplicationStatusWithdrawn},
+	ApplicationStatusShortlisted: {ApplicationStatusPending, ApplicationStatusApproved, ApplicationStatusRejected, ApplicationStatusWithdrawn},
+	ApplicationStatusApproved:    {ApplicationStatusPending, ApplicationStatusRejected, ApplicationStatusWithdrawn},
+	ApplicationStatusRejected:    {ApplicationStatusPending, ApplicationStatusShortlisted},
+	ApplicationStatusWithdrawn:   {},
+}
+
+// canTransitionApplication reports whether the state machine allows from -> to
+func canTransitionApplication(from, to string) bool {
+	for _, next := range applicationTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestCanTransitionApplication(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{ApplicationStatusPending, ApplicationStatusShortlisted, true},
+		{ApplicationStatusPending, ApplicationStatusApproved, true},
+		{ApplicationStatusPending, ApplicationStatusRejected, true},
+		{ApplicationStatusPending, ApplicationStatusWithdrawn, true},
+		{ApplicationStatusShortlisted, ApplicationStatusApproved, true},
+		{ApplicationStatusShortlisted, ApplicationStatusPending, true},
+		{ApplicationStatusApproved, ApplicationStatusPending, true},
+		{ApplicationStatusApproved, ApplicationStatusRejected, true},
+		{ApplicationStatusApproved, ApplicationStatusShortlisted, false},
+		{ApplicationStatusRejected, ApplicationStatusApproved, false},
+		{ApplicationStatusRejected, ApplicationStatusPending, true},
+		{ApplicationStatusRejected, ApplicationStatusShortlisted, true},
+		{ApplicationStatusRejected, ApplicationStatusWithdrawn, false},
+		{ApplicationStatusWithdrawn, ApplicationStatusPending, false},
+		{ApplicationStatusWithdrawn, ApplicationStatusApproved, false},
+		{"unknown", ApplicationStatusPending, false},
+	}
+	for _, tt := range tests {
+		if got := canTransitionApplication(tt.from, tt.to); got != tt.want {
+			t.Errorf("canTransitionApplication(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
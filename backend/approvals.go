@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DeliverableStatusRejected is the terminal state of a rejected deliverable
+const DeliverableStatusRejected = "rejected"
+
+// Review actions
+const (
+	ReviewActionApprove        = "approve"
+	ReviewActionRequestChanges = "request_changes"
+	ReviewActionReject         = "reject"
+)
+
+// defaultApprovalStep is used when a campaign requires approval but lists no steps
+const defaultApprovalStep = "Brand review"
+
+// deliverableApprovalSteps returns the ordered review steps for a campaign's
+// deliverables, or nil if the campaign does not require creative approval
+func deliverableApprovalSteps(campaign *Campaign) []string {
+	var steps []string
+	for _, step := range campaign.ApprovalSteps {
+		if step = strings.TrimSpace(step); step != "" {
+			steps = append(steps, step)
+		}
+	}
+
+	if len(steps) == 0 && (campaign.CreativeApprovalNeeded || campaign.ApprovalRequired) {
+		steps = []string{defaultApprovalStep}
+	}
+	return steps
+}
+
+// hasApprovalWorkflow reports whether a deliverable must pass approval steps
+func hasApprovalWorkflow(deliverable *Deliverable) bool {
+	return len(deliverable.ApprovalSteps) > 0
+}
+
+// allApprovalStepsPassed reports whether the latest submission passed every step
+func allApprovalStepsPassed(deliverable *Deliverable) bool {
+	return deliverable.Version > 0 && deliverable.CurrentStep >= len(deliverable.ApprovalSteps)
+}
+
+// reviewDeliverableHandler records the brand's decision on the current
+// approval step of a deliverable's latest submission. Approving the last
+// step approves the deliverable; requesting changes sends it back to the
+// creator, whose next submission restarts review from the first step.
+func reviewDeliverableHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	deliverableId := vars["deliverableId"]
+
+	var req struct {
+		Action  string `json:"action"`
+		Comment string `json:"comment"`
+		Version int    `json:"version"` // Optional guard against reviewing a stale submission
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Action != ReviewActionApprove && req.Action != ReviewActionRequestChanges && req.Action != ReviewActionReject {
+		http.Error(w, "Invalid action. Must be 'approve', 'request_changes', or 'reject'", http.StatusBadRequest)
+		return
+	}
+	if req.Action != ReviewActionApprove && strings.TrimSpace(req.Comment) == "" {
+		http.Error(w, "A comment is required when requesting changes or rejecting", http.StatusBadRequest)
+		return
+	}
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	deliverableOID, err := primitive.ObjectIDFromHex(deliverableId)
+	if err != nil {
+		http.Error(w, "Invalid deliverable ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID := getUserIDFromClerkUser(user)
+	deliverable, status, msg := loadDeliverableForParticipant(ctx, deliverableOID, userID)
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	if deliverable.BrandID != userID {
+		http.Error(w, "Only the brand can review a deliverable", http.StatusForbidden)
+		return
+	}
+	if status, msg := checkDeliverableApplication(ctx, deliverable); status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+	if !hasApprovalWorkflow(deliverable) {
+		http.Error(w, "This deliverable has no approval steps; use the status endpoint", http.StatusConflict)
+		return
+	}
+	if deliverable.Status != DeliverableStatusSubmitted {
+		http.Error(w, "Only submitted deliverables can be reviewed", http.StatusConflict)
+		return
+	}
+	if req.Version != 0 && req.Version != deliverable.Version {
+		http.Error(w, fmt.Sprintf("Version %d is not the latest submission (latest is %d)", req.Version, deliverable.Version), http.StatusConflict)
+		return
+	}
+	if deliverable.CurrentStep >= len(deliverable.ApprovalSteps) {
+		http.Error(w, "All approval steps have already passed", http.StatusConflict)
+		return
+	}
+
+	now := time.Now()
+	review := DeliverableReview{
+		Version:    deliverable.Version,
+		Step:       deliverable.ApprovalSteps[deliverable.CurrentStep],
+		Action:     req.Action,
+		Comment:    strings.TrimSpace(req.Comment),
+		ReviewerID: userID,
+		CreatedAt:  now,
+	}
+
+	set := bson.M{"updatedAt": now}
+	switch req.Action {
+	case ReviewActionApprove:
+		set["currentStep"] = deliverable.CurrentStep + 1
+		if deliverable.CurrentStep+1 >= len(deliverable.ApprovalSteps) {
+			set["status"] = DeliverableStatusApproved
+		}
+	case ReviewActionRequestChanges:
+		set["status"] = DeliverableStatusRevisionNeeded
+		set["feedback"] = review.Comment
+	case ReviewActionReject:
+		set["status"] = DeliverableStatusRejected
+		set["feedback"] = review.Comment
+	}
+
+	// Guard on step and version so concurrent reviews cannot both apply
+	var updated Deliverable
+	err = database.Collection("deliverables").FindOneAndUpdate(ctx,
+		bson.M{
+			"_id":         deliverableOID,
+			"status":      DeliverableStatusSubmitted,
+			"version":     deliverable.Version,
+			"currentStep": deliverable.CurrentStep,
+		},
+		bson.M{"$set": set, "$push": bson.M{"reviews": review}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			http.Error(w, "Deliverable changed concurrently, please retry", http.StatusConflict)
+		} else {
+			http.Error(w, "Error updating deliverable", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	publishEvent(EventDeliverableUpdated, updated, updated.BrandID, updated.CreatorID)
+
+	// Tell the creator once the review reaches a decision they need to act on
+	if updated.Status != DeliverableStatusSubmitted {
+		notificationService.Notify(updated.CreatorID, NotificationDeliverableReviewed,
+			fmt.Sprintf("%s: %s", updated.Title, strings.ReplaceAll(updated.Status, "_", " ")),
+			fmt.Sprintf("%s for %s was reviewed at step %q. %s", updated.Title, updated.CampaignName, review.Step, review.Comment),
+			"/creator/dashboard",
+			map[string]interface{}{"deliverableId": updated.ID.Hex(), "status": updated.Status},
+		)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
@@ -30,16 +29,16 @@ type contextKey string
 
 const userContextKey = contextKey("user")
 
-// getClerkConfig returns Clerk configuration from environment variables
+// getClerkConfig returns the Clerk settings from the app configuration
 func getClerkConfig() (*ClerkConfig, error) {
-	secretKey := os.Getenv("CLERK_SECRET_KEY")
+	secretKey := appConfig.Clerk.SecretKey
 	if secretKey == "" {
-		return nil, fmt.Errorf("CLERK_SECRET_KEY environment variable is required")
+		return nil, fmt.Errorf("clerk.secretKey (CLERK_SECRET_KEY) is required")
 	}
 
-	publishableKey := os.Getenv("CLERK_PUBLISHABLE_KEY")
+	publishableKey := appConfig.Clerk.PublishableKey
 	if publishableKey == "" {
-		return nil, fmt.Errorf("CLERK_PUBLISHABLE_KEY environment variable is required")
+		return nil, fmt.Errorf("clerk.publishableKey (CLERK_PUBLISHABLE_KEY) is required")
 	}
 
 	return &ClerkConfig{
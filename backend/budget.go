@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BudgetSummary reports a campaign's budget use in minor units (e.g. cents)
+type BudgetSummary struct {
+	Currency  string `json:"currency"`
+	Total     int64  `json:"total"`
+	Reserved  int64  `json:"reserved"`
+	Remaining int64  `json:"remaining"`
+	Limited   bool   `json:"limited"` // False when the campaign has no numeric budget
+}
+
+// campaignBudgetLimit parses Campaign.Budget, reporting false when there is no
+// enforceable budget. A range such as "$1,000-$2,500" is limited by its upper
+// bound, and an open-ended one such as "$25,000+" is not limited at all.
+func campaignBudgetLimit(campaign *Campaign) (int64, bool) {
+	if strings.TrimSpace(campaign.Budget) == "" {
+		return 0, false
+	}
+	if isMoneyRange(campaign.Budget) {
+		_, high, err := parseMoneyRange(campaign.Budget, campaignCurrency(campaign))
+		if err != nil || high == 0 {
+			return 0, false
+		}
+		return high, true
+	}
+	limit, err := parseMoney(campaign.Budget, campaignCurrency(campaign))
+	if err != nil {
+		return 0, false
+	}
+	return limit, true
+}
+
+// campaignBudgetSummary describes how much of a campaign's budget is reserved
+func campaignBudgetSummary(campaign *Campaign) *BudgetSummary {
+	limit, limited := campaignBudgetLimit(campaign)
+	summary := &BudgetSummary{
+		Currency: campaignCurrency(campaign),
+		Total:    limit,
+		Reserved: campaign.ReservedBudget,
+		Limited:  limited,
+	}
+	if limited {
+		summary.Remaining = limit - campaign.ReservedBudget
+	}
+	return summary
+}
+
+// applicationFee returns the fee agreed with a creator, in minor units: the
+// negotiated rate if terms were agreed, otherwise the campaign's PaymentAmount.
+// Only Fixed Payment campaigns have a fee; other compensation types describe
+// the payment in words ("5% commission"). A PaymentAmount that is a range,
+// such as the campaign form's "$1,000-$2,500", is not a fee until a rate is
+// agreed, so it counts as no fee.
+func applicationFee(campaign *Campaign, application *Application) int64 {
+	if campaign.CompensationType != compensationFixedPayment {
+		return 0
+	}
+
+	if application.AgreedTerms != nil {
+		if fee, err := parseAmount(application.AgreedTerms.Rate, campaignCurrency(campaign)); err == nil && fee > 0 {
+			return fee
+		}
+	}
+
+	fee, err := parseAmount(campaign.PaymentAmount, campaignCurrency(campaign))
+	if err != nil || fee < 0 {
+		return 0
+	}
+	return fee
+}
+
+// errBudgetExceeded is returned when a reservation does not fit in the remaining budget
+var errBudgetExceeded = fmt.Errorf("campaign budget exceeded")
+
+// reserveBudget atomically adds fee to the campaign's reserved budget. It
+// fails with errBudgetExceeded if the reservation would exceed the budget,
+// unless override is set.
+func reserveBudget(ctx context.Context, campaign *Campaign, fee int64, override bool) error {
+	if fee <= 0 {
+		return nil
+	}
+
+	filter := bson.M{"_id": campaign.ID}
+	if limit, limited := campaignBudgetLimit(campaign); limited && !override {
+		if fee > limit {
+			return errBudgetExceeded
+		}
+		filter["$or"] = []bson.M{
+			{"reservedBudget": bson.M{"$exists": false}},
+			{"reservedBudget": bson.M{"$lte": limit - fee}},
+		}
+	}
+
+	var updated Campaign
+	err := database.Collection("campaigns").FindOneAndUpdate(ctx, filter,
+		bson.M{"$inc": bson.M{"reservedBudget": fee}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		return errBudgetExceeded
+	}
+	if err != nil {
+		return err
+	}
+
+	campaign.ReservedBudget = updated.ReservedBudget
+	return nil
+}
+
+// releaseBudget returns a reservation to the campaign's budget
+func releaseBudget(ctx context.Context, campaign *Campaign, amount int64) error {
+	if amount <= 0 {
+		return nil
+	}
+
+	_, err := database.Collection("campaigns").UpdateOne(ctx,
+		bson.M{"_id": campaign.ID},
+		bson.M{"$inc": bson.M{"reservedBudget": -amount}},
+	)
+	if err == nil {
+		campaign.ReservedBudget -= amount
+	}
+	return err
+}
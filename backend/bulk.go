@@ -0,0 +1,390 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxBulkApplications caps how many applications one bulk request may touch
+const maxBulkApplications = 500
+
+// followersPattern reads follower counts such as "12,500", "12.5K" or "1.2M"
+var followersPattern = regexp.MustCompile(`(?i)(\d[\d,]*(?:\.\d+)?)\s*([km])?`)
+
+// parseFollowers converts a free-text follower count into a number
+func parseFollowers(s string) (int64, bool) {
+	m := followersPattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", ""), 64)
+	if err != nil {
+		return 0, false
+	}
+	switch strings.ToLower(m[2]) {
+	case "k":
+		value *= 1_000
+	case "m":
+		value *= 1_000_000
+	}
+	return int64(value), true
+}
+
+// BulkApplicationFilter selects a campaign's applications by their fields
+type BulkApplicationFilter struct {
+	Status        string `json:"status"`
+	Platform      string `json:"platform"`
+	MinFollowers  int64  `json:"minFollowers"`
+	MaxFollowers  int64  `json:"maxFollowers"`
+	AppliedAfter  string `json:"appliedAfter"`  // YYYY-MM-DD, inclusive
+	AppliedBefore string `json:"appliedBefore"` // YYYY-MM-DD, exclusive
+}
+
+// query builds the Mongo filter for the fields Mongo can match directly.
+// Follower ranges are checked in Go since Followers is free text.
+func (f *BulkApplicationFilter) query(campaignID primitive.ObjectID) (bson.M, error) {
+	query := bson.M{"campaignId": campaignID}
+	if f.Status != "" {
+		query["status"] = f.Status
+	}
+	if f.Platform != "" {
+		query["platform"] = bson.M{"$regex": "^" + regexp.QuoteMeta(f.Platform) + "$", "$options": "i"}
+	}
+
+	applied := bson.M{}
+	if f.AppliedAfter != "" {
+		after, err := time.Parse(campaignDateLayout, f.AppliedAfter)
+		if err != nil {
+			return nil, fmt.Errorf("appliedAfter must be YYYY-MM-DD")
+		}
+		applied["$gte"] = after
+	}
+	if f.AppliedBefore != "" {
+		before, err := time.Parse(campaignDateLayout, f.AppliedBefore)
+		if err != nil {
+			return nil, fmt.Errorf("appliedBefore must be YYYY-MM-DD")
+		}
+		applied["$lt"] = before
+	}
+	if len(applied) > 0 {
+		query["appliedDate"] = applied
+	}
+	return query, nil
+}
+
+// matchesFollowers applies the follower range to an application
+func (f *BulkApplicationFilter) matchesFollowers(application *Application) bool {
+	if f.MinFollowers == 0 && f.MaxFollowers == 0 {
+		return true
+	}
+	followers, ok := parseFollowers(application.Followers)
+	if !ok {
+		return false
+	}
+	return followers >= f.MinFollowers && (f.MaxFollowers == 0 || followers <= f.MaxFollowers)
+}
+
+// BulkApplicationResult reports what happened to one application
+type BulkApplicationResult struct {
+	ApplicationID  string `json:"applicationId"`
+	CreatorName    string `json:"creatorName,omitempty"`
+	PreviousStatus string `json:"previousStatus,omitempty"`
+	Status         string `json:"status,omitempty"`
+	OK             bool   `json:"ok"`
+	Error          string `json:"error,omitempty"`
+}
+
+// bulkUpdateApplicationsHandler moves many of a campaign's applications to one
+// status. By default it is all-or-nothing: every item is checked first
+// (ownership, state machine, budget) and nothing changes if any check fails;
+// the changes then run in one transaction, so an item failing while applying
+// leaves nothing behind. With allOrNothing set to false, each valid item is
+// applied on its own.
+func bulkUpdateApplicationsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	campaignId := vars["campaignId"]
+
+	var req struct {
+		ApplicationIDs []string               `json:"applicationIds"`
+		Filter         *BulkApplicationFilter `json:"filter"`
+		Status         string                 `json:"status"`
+		OverrideBudget bool                   `json:"overrideBudget"`
+		AllOrNothing   *bool                  `json:"allOrNothing"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Validate status
+	if req.Status != ApplicationStatusApproved && req.Status != ApplicationStatusShortlisted &&
+		req.Status != ApplicationStatusRejected && req.Status != ApplicationStatusPending {
+		http.Error(w, "Invalid status. Must be 'approved', 'shortlisted', 'rejected', or 'pending'", http.StatusBadRequest)
+		return
+	}
+	if (len(req.ApplicationIDs) == 0) == (req.Filter == nil) {
+		http.Error(w, "Send either applicationIds or filter", http.StatusBadRequest)
+		return
+	}
+	if len(req.ApplicationIDs) > maxBulkApplications {
+		http.Error(w, fmt.Sprintf("At most %d applications per request", maxBulkApplications), http.StatusBadRequest)
+		return
+	}
+	allOrNothing := req.AllOrNothing == nil || *req.AllOrNothing
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	if getUserTypeFromClerkUser(user) != "brand" {
+		http.Error(w, "Only brands can update application status", http.StatusForbidden)
+		return
+	}
+
+	// Convert campaign ID to ObjectID
+	campaignOID, err := primitive.ObjectIDFromHex(campaignId)
+	if err != nil {
+		http.Error(w, "Invalid campaign ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	var campaign Campaign
+	err = database.Collection("campaigns").FindOne(ctx, bson.M{"_id": campaignOID}).Decode(&campaign)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			http.Error(w, "Campaign not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error fetching campaign", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if campaign.BrandID != getUserIDFromClerkUser(user) {
+		http.Error(w, "Access denied: You can only review applications to your own campaigns", http.StatusForbidden)
+		return
+	}
+
+	// Resolve the target applications, keeping the requested order
+	var results []BulkApplicationResult
+	var targets []*Application
+	if req.Filter != nil {
+		query, err := req.Filter.query(campaignOID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		cursor, err := database.Collection("applications").Find(ctx, query)
+		if err != nil {
+			http.Error(w, "Error fetching applications", http.StatusInternalServerError)
+			return
+		}
+		var applications []Application
+		if err = cursor.All(ctx, &applications); err != nil {
+			http.Error(w, "Error decoding applications", http.StatusInternalServerError)
+			return
+		}
+		for i := range applications {
+			if req.Filter.matchesFollowers(&applications[i]) {
+				targets = append(targets, &applications[i])
+			}
+		}
+		if len(targets) > maxBulkApplications {
+			http.Error(w, fmt.Sprintf("Filter matches %d applications; at most %d per request", len(targets), maxBulkApplications), http.StatusBadRequest)
+			return
+		}
+		for _, application := range targets {
+			results = append(results, BulkApplicationResult{ApplicationID: application.ID.Hex()})
+		}
+	} else {
+		var ids []primitive.ObjectID
+		for _, id := range req.ApplicationIDs {
+			oid, err := primitive.ObjectIDFromHex(id)
+			if err != nil {
+				http.Error(w, "Invalid application ID: "+id, http.StatusBadRequest)
+				return
+			}
+			ids = append(ids, oid)
+		}
+
+		cursor, err := database.Collection("applications").Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+		if err != nil {
+			http.Error(w, "Error fetching applications", http.StatusInternalServerError)
+			return
+		}
+		var applications []Application
+		if err = cursor.All(ctx, &applications); err != nil {
+			http.Error(w, "Error decoding applications", http.StatusInternalServerError)
+			return
+		}
+		byID := make(map[primitive.ObjectID]*Application)
+		for i := range applications {
+			byID[applications[i].ID] = &applications[i]
+		}
+
+		seen := make(map[primitive.ObjectID]bool)
+		for _, oid := range ids {
+			if seen[oid] {
+				continue
+			}
+			seen[oid] = true
+			results = append(results, BulkApplicationResult{ApplicationID: oid.Hex()})
+			targets = append(targets, byID[oid]) // nil when not found
+		}
+	}
+
+	// Check every item before changing anything
+	var budgetDelta int64
+	failed := 0
+	for i, application := range targets {
+		result := &results[i]
+		switch {
+		case application == nil:
+			result.Error = "Application not found"
+		case application.CampaignID != campaignOID:
+			result.Error = "Application belongs to another campaign"
+		case application.Status == req.Status:
+			// Nothing to do; reported as a success
+		case !canTransitionApplication(application.Status, req.Status):
+			result.Error = fmt.Sprintf("Cannot move an application from %s to %s", application.Status, req.Status)
+		}
+		if application != nil {
+			result.CreatorName = application.CreatorName
+			result.PreviousStatus = application.Status
+		}
+		if result.Error != "" {
+			failed++
+			continue
+		}
+
+		if req.Status == ApplicationStatusApproved && application.Status != ApplicationStatusApproved {
+			budgetDelta += applicationFee(&campaign, application)
+		} else if application.Status == ApplicationStatusApproved && req.Status != ApplicationStatusApproved {
+			budgetDelta -= application.ReservedAmount
+		}
+	}
+
+	if limit, limited := campaignBudgetLimit(&campaign); limited && !req.OverrideBudget && budgetDelta > 0 &&
+		campaign.ReservedBudget+budgetDelta > limit && allOrNothing {
+		summary := campaignBudgetSummary(&campaign)
+		writeBulkResults(w, http.StatusConflict, results, &campaign,
+			fmt.Sprintf("Approving these creators would exceed the campaign budget (remaining %s %s, needed %s). Set overrideBudget to approve anyway.",
+				formatMoney(summary.Remaining, summary.Currency), summary.Currency, formatMoney(budgetDelta, summary.Currency)))
+		return
+	}
+
+	if failed > 0 && allOrNothing {
+		writeBulkResults(w, http.StatusUnprocessableEntity, results, &campaign,
+			fmt.Sprintf("%d of %d applications cannot be updated; nothing was changed", failed, len(results)))
+		return
+	}
+
+	var order []int
+	for i, application := range targets {
+		if application != nil && results[i].Error == "" && application.Status != req.Status {
+			order = append(order, i)
+		}
+	}
+
+	if allOrNothing {
+		// One transaction for the whole batch: if any item fails, nothing is
+		// kept, and no events or notifications go out until it commits
+		reservedBudget := campaign.ReservedBudget
+		var changes []*applicationChange
+		failedAt, failStatus, failMsg := -1, http.StatusOK, ""
+		err := withTransaction(ctx, func(sc mongo.SessionContext) error {
+			campaign.ReservedBudget = reservedBudget
+			changes = changes[:0]
+			for _, i := range order {
+				change, status, msg := changeApplicationStatus(sc, &campaign, targets[i], req.Status, req.OverrideBudget)
+				if status != http.StatusOK {
+					failedAt, failStatus, failMsg = i, status, msg
+					return errAbortTransaction
+				}
+				changes = append(changes, change)
+			}
+			return nil
+		})
+		if err != nil {
+			campaign.ReservedBudget = reservedBudget
+			if errors.Is(err, errAbortTransaction) {
+				results[failedAt].Error = failMsg
+				writeBulkResults(w, failStatus, results, &campaign,
+					fmt.Sprintf("Application %s failed (%s); nothing was changed", results[failedAt].ApplicationID, failMsg))
+			} else {
+				writeBulkResults(w, http.StatusInternalServerError, results, &campaign, "Error updating applications; nothing was changed")
+			}
+			return
+		}
+
+		for j, i := range order {
+			results[i].Status = changes[j].application.Status
+			results[i].OK = true
+			announceApplicationChange(&campaign, changes[j])
+		}
+	} else {
+		// Each item commits on its own; failures do not affect the others
+		for _, i := range order {
+			updated, status, msg := applyApplicationStatus(ctx, &campaign, targets[i], req.Status, req.OverrideBudget)
+			if status != http.StatusOK {
+				results[i].Error = msg
+				failed++
+				continue
+			}
+			results[i].Status = updated.Status
+			results[i].OK = true
+		}
+	}
+
+	// Unchanged items count as successes
+	for i, application := range targets {
+		if application != nil && results[i].Error == "" && !results[i].OK {
+			results[i].Status = application.Status
+			results[i].OK = true
+		}
+	}
+
+	writeBulkResults(w, http.StatusOK, results, &campaign, fmt.Sprintf("%d of %d applications updated", len(results)-failed, len(results)))
+}
+
+// writeBulkResults sends the per-item results with the campaign's budget
+func writeBulkResults(w http.ResponseWriter, status int, results []BulkApplicationResult, campaign *Campaign, message string) {
+	if results == nil {
+		results = []BulkApplicationResult{}
+	}
+	succeeded := 0
+	for _, result := range results {
+		if result.OK {
+			succeeded++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":       message,
+		"total":         len(results),
+		"succeeded":     succeeded,
+		"failed":        len(results) - succeeded,
+		"results":       results,
+		"budgetSummary": campaignBudgetSummary(campaign),
+	})
+}
@@ -0,0 +1,378 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// initCampaignTemplates creates the index that keeps template names unique per owner
+func initCampaignTemplates() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := database.Collection("campaign_templates").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "ownerId", Value: 1}, {Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create campaign template indexes: %w", err)
+	}
+	return nil
+}
+
+// campaignTemplateScope filters the templates a user can see and use. For
+// now that is only the templates they saved themselves.
+func campaignTemplateScope(userID string) bson.M {
+	return bson.M{"ownerId": userID}
+}
+
+// campaignRequestFromCampaign copies a campaign's form fields into a request.
+// The JSON field names of Campaign and CampaignRequest match, so a JSON round
+// trip picks up every form field and drops the stored-only ones.
+func campaignRequestFromCampaign(campaign *Campaign) (CampaignRequest, error) {
+	var req CampaignRequest
+	encoded, err := json.Marshal(campaign)
+	if err != nil {
+		return req, err
+	}
+	if err := json.Unmarshal(encoded, &req); err != nil {
+		return req, err
+	}
+	req.Status = ""
+	return req, nil
+}
+
+// applyCampaignOverrides decodes a partial campaign from body over req. Only
+// the fields present in the body change; an empty body changes nothing.
+func applyCampaignOverrides(req *CampaignRequest, body io.Reader) error {
+	err := json.NewDecoder(body).Decode(req)
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+// loadOwnedCampaign fetches a campaign owned by the brand
+func loadOwnedCampaign(ctx context.Context, campaignId, userID string) (*Campaign, int, string) {
+	campaignOID, err := primitive.ObjectIDFromHex(campaignId)
+	if err != nil {
+		return nil, http.StatusBadRequest, "Invalid campaign ID"
+	}
+
+	var campaign Campaign
+	err = database.Collection("campaigns").FindOne(ctx, bson.M{"_id": campaignOID}).Decode(&campaign)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, http.StatusNotFound, "Campaign not found"
+		}
+		return nil, http.StatusInternalServerError, "Error fetching campaign"
+	}
+
+	if campaign.BrandID != userID {
+		return nil, http.StatusForbidden, "Access denied: You can only use your own campaigns"
+	}
+	return &campaign, http.StatusOK, ""
+}
+
+// loadOwnedCampaignTemplate fetches a campaign template owned by the brand
+func loadOwnedCampaignTemplate(ctx context.Context, templateId, userID string) (*CampaignTemplate, int, string) {
+	templateOID, err := primitive.ObjectIDFromHex(templateId)
+	if err != nil {
+		return nil, http.StatusBadRequest, "Invalid template ID"
+	}
+
+	var campaignTemplate CampaignTemplate
+	filter := campaignTemplateScope(userID)
+	filter["_id"] = templateOID
+	err = database.Collection("campaign_templates").FindOne(ctx, filter).Decode(&campaignTemplate)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, http.StatusNotFound, "Template not found"
+		}
+		return nil, http.StatusInternalServerError, "Error fetching template"
+	}
+	return &campaignTemplate, http.StatusOK, ""
+}
+
+// createDraftCampaign validates req and stores it as a new draft campaign for the brand
+func createDraftCampaign(ctx context.Context, req *CampaignRequest, userID string) (*Campaign, int, string) {
+	if errs := validateCampaignRequest(req); len(errs) > 0 {
+		return nil, http.StatusBadRequest, strings.Join(errs, "; ")
+	}
+
+	var dbUser User
+	err := database.Collection("users").FindOne(ctx, bson.M{"clerkId": userID}).Decode(&dbUser)
+	if err != nil {
+		return nil, http.StatusNotFound, "User not found"
+	}
+
+	campaign := newCampaignFromRequest(req, userID, dbUser.Name)
+	if _, err := database.Collection("campaigns").InsertOne(ctx, campaign); err != nil {
+		return nil, http.StatusInternalServerError, "Error creating campaign"
+	}
+	return &campaign, http.StatusCreated, ""
+}
+
+// duplicateCampaignHandler copies one of the brand's campaigns into a new draft.
+// The body may hold campaign fields to change on the copy.
+func duplicateCampaignHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	campaignId := vars["campaignId"]
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+	userID := getUserIDFromClerkUser(user)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	source, status, msg := loadOwnedCampaign(ctx, campaignId, userID)
+	if source == nil {
+		http.Error(w, msg, status)
+		return
+	}
+
+	req, err := campaignRequestFromCampaign(source)
+	if err != nil {
+		http.Error(w, "Error copying campaign", http.StatusInternalServerError)
+		return
+	}
+	req.Title = source.Title + " (copy)"
+	if err := applyCampaignOverrides(&req, r.Body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	campaign, status, msg := createDraftCampaign(ctx, &req, userID)
+	if campaign == nil {
+		http.Error(w, msg, status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(campaign)
+}
+
+// getCampaignTemplatesHandler lists the brand's campaign templates
+func getCampaignTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	if getUserTypeFromClerkUser(user) != "brand" {
+		http.Error(w, "Only brands can manage campaign templates", http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := database.Collection("campaign_templates").Find(ctx, campaignTemplateScope(getUserIDFromClerkUser(user)), opts)
+	if err != nil {
+		http.Error(w, "Error fetching templates", http.StatusInternalServerError)
+		return
+	}
+	templates := []CampaignTemplate{}
+	if err = cursor.All(ctx, &templates); err != nil {
+		http.Error(w, "Error decoding templates", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(templates)
+}
+
+// getCampaignTemplateHandler returns one of the brand's campaign templates
+func getCampaignTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	campaignTemplate, status, msg := loadOwnedCampaignTemplate(ctx, vars["templateId"], getUserIDFromClerkUser(user))
+	if campaignTemplate == nil {
+		http.Error(w, msg, status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(campaignTemplate)
+}
+
+// createCampaignTemplateHandler saves a template from one of the brand's
+// campaigns (campaignId) or from campaign fields given in the body (campaign)
+func createCampaignTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name        string           `json:"name"`
+		Description string           `json:"description"`
+		CampaignID  string           `json:"campaignId"`
+		Campaign    *CampaignRequest `json:"campaign"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+	if (req.CampaignID == "") == (req.Campaign == nil) {
+		http.Error(w, "Send either campaignId or campaign", http.StatusBadRequest)
+		return
+	}
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	if getUserTypeFromClerkUser(user) != "brand" {
+		http.Error(w, "Only brands can manage campaign templates", http.StatusForbidden)
+		return
+	}
+
+	userID := getUserIDFromClerkUser(user)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	campaignTemplate := CampaignTemplate{
+		ID:          primitive.NewObjectID(),
+		OwnerID:     userID,
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if req.CampaignID != "" {
+		source, status, msg := loadOwnedCampaign(ctx, req.CampaignID, userID)
+		if source == nil {
+			http.Error(w, msg, status)
+			return
+		}
+		fields, err := campaignRequestFromCampaign(source)
+		if err != nil {
+			http.Error(w, "Error copying campaign", http.StatusInternalServerError)
+			return
+		}
+		campaignTemplate.Campaign = fields
+		campaignTemplate.SourceCampaignID = &source.ID
+	} else {
+		campaignTemplate.Campaign = *req.Campaign
+		campaignTemplate.Campaign.Status = ""
+	}
+
+	// Template names are unique per owner so they can be picked from a list
+	if _, err := database.Collection("campaign_templates").InsertOne(ctx, campaignTemplate); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			http.Error(w, "A template with this name already exists", http.StatusConflict)
+			return
+		}
+		http.Error(w, "Error creating template", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(campaignTemplate)
+}
+
+// deleteCampaignTemplateHandler removes one of the brand's campaign templates
+func deleteCampaignTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	campaignTemplate, status, msg := loadOwnedCampaignTemplate(ctx, vars["templateId"], getUserIDFromClerkUser(user))
+	if campaignTemplate == nil {
+		http.Error(w, msg, status)
+		return
+	}
+
+	if _, err := database.Collection("campaign_templates").DeleteOne(ctx, bson.M{"_id": campaignTemplate.ID}); err != nil {
+		http.Error(w, "Error deleting template", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// createCampaignFromTemplateHandler creates a draft campaign from a template.
+// The body may hold campaign fields that override the template's.
+func createCampaignFromTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+	userID := getUserIDFromClerkUser(user)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	campaignTemplate, status, msg := loadOwnedCampaignTemplate(ctx, vars["templateId"], userID)
+	if campaignTemplate == nil {
+		http.Error(w, msg, status)
+		return
+	}
+
+	req := campaignTemplate.Campaign
+	if err := applyCampaignOverrides(&req, r.Body); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	campaign, status, msg := createDraftCampaign(ctx, &req, userID)
+	if campaign == nil {
+		http.Error(w, msg, status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(campaign)
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// disclosureTags are the hashtags accepted as an ad disclosure
+var disclosureTags = []string{"#ad", "#advertisement", "#sponsored", "#paidpartnership", "#paidpartner"}
+
+var (
+	hashtagPattern = regexp.MustCompile(`#[\p{L}\p{N}_]+`)
+	mentionPattern = regexp.MustCompile(`@[\p{L}\p{N}_.]+`)
+
+	// bannedTermsPattern finds lines in ContentGuidelines such as
+	// "Avoid: cheap, knockoff" or "Banned terms: guaranteed results"
+	bannedTermsPattern = regexp.MustCompile(`(?im)^\s*(?:banned(?:\s+(?:terms|words))?|avoid|do\s+not\s+(?:use|mention|say)|don't\s+(?:use|mention|say))\s*:\s*(.+)$`)
+)
+
+// parseTagList splits a free-form list like "#summer, #style  brandname"
+// into normalised tags with the given prefix ("#" or "@")
+func parseTagList(list, prefix string) []string {
+	fields := strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\n' || r == '\t'
+	})
+
+	seen := make(map[string]bool)
+	var tags []string
+	for _, field := range fields {
+		tag := strings.ToLower(strings.TrimLeft(strings.TrimSpace(field), "#@"))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, prefix+tag)
+	}
+	return tags
+}
+
+// parseBannedTerms extracts banned terms from a campaign's ContentGuidelines
+func parseBannedTerms(guidelines string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, match := range bannedTermsPattern.FindAllStringSubmatch(guidelines, -1) {
+		for _, term := range strings.Split(match[1], ",") {
+			term = strings.Trim(strings.TrimSpace(term), `."'`)
+			if term == "" || seen[strings.ToLower(term)] {
+				continue
+			}
+			seen[strings.ToLower(term)] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// tokenSet collects the lower-cased matches of pattern in text
+func tokenSet(pattern *regexp.Regexp, text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, token := range pattern.FindAllString(text, -1) {
+		set[strings.ToLower(strings.TrimRight(token, "."))] = true
+	}
+	return set
+}
+
+// checkContentCompliance checks submitted caption/transcript text against
+// the campaign's required hashtags and mentions, the ad disclosure rule and
+// any banned terms listed in its ContentGuidelines
+func checkContentCompliance(campaign *Campaign, text string, version int) *ComplianceReport {
+	hashtags := tokenSet(hashtagPattern, text)
+	mentions := tokenSet(mentionPattern, text)
+
+	report := &ComplianceReport{
+		Version:         version,
+		MissingHashtags: []string{},
+		MissingMentions: []string{},
+		BannedTerms:     []string{},
+		CheckedAt:       time.Now(),
+	}
+
+	for _, tag := range parseTagList(campaign.HashtagsToUse, "#") {
+		if !hashtags[tag] {
+			report.MissingHashtags = append(report.MissingHashtags, tag)
+		}
+	}
+	for _, mention := range parseTagList(campaign.MentionsRequired, "@") {
+		if !mentions[mention] {
+			report.MissingMentions = append(report.MissingMentions, mention)
+		}
+	}
+
+	report.MissingDisclosure = true
+	for _, tag := range disclosureTags {
+		if hashtags[tag] {
+			report.MissingDisclosure = false
+			break
+		}
+	}
+
+	for _, term := range parseBannedTerms(campaign.ContentGuidelines) {
+		pattern, err := regexp.Compile(`(?i)(?:^|[^\p{L}\p{N}_])` + regexp.QuoteMeta(term) + `(?:$|[^\p{L}\p{N}_])`)
+		if err == nil && pattern.MatchString(text) {
+			report.BannedTerms = append(report.BannedTerms, term)
+		}
+	}
+
+	report.Passed = len(report.MissingHashtags) == 0 &&
+		len(report.MissingMentions) == 0 &&
+		!report.MissingDisclosure &&
+		len(report.BannedTerms) == 0
+	return report
+}
+
+// deliverableComplianceText returns the text of a deliverable's latest submission
+func deliverableComplianceText(deliverable *Deliverable) string {
+	if len(deliverable.Submissions) == 0 {
+		return deliverable.Caption
+	}
+	latest := deliverable.Submissions[len(deliverable.Submissions)-1]
+	return latest.Caption + "\n" + latest.Transcript
+}
+
+// checkDeliverableComplianceHandler re-runs the compliance check on a
+// deliverable's latest submission, e.g. after the campaign's rules change
+func checkDeliverableComplianceHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	deliverableId := vars["deliverableId"]
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	deliverableOID, err := primitive.ObjectIDFromHex(deliverableId)
+	if err != nil {
+		http.Error(w, "Invalid deliverable ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	deliverable, status, msg := loadDeliverableForParticipant(ctx, deliverableOID, getUserIDFromClerkUser(user))
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	if deliverable.Version == 0 {
+		http.Error(w, "Deliverable has no submission to check", http.StatusConflict)
+		return
+	}
+
+	var campaign Campaign
+	if err := database.Collection("campaigns").FindOne(ctx, bson.M{"_id": deliverable.CampaignID}).Decode(&campaign); err != nil {
+		http.Error(w, "Error fetching campaign", http.StatusInternalServerError)
+		return
+	}
+
+	report := checkContentCompliance(&campaign, deliverableComplianceText(deliverable), deliverable.Version)
+
+	var updated Deliverable
+	err = database.Collection("deliverables").FindOneAndUpdate(ctx,
+		bson.M{"_id": deliverableOID},
+		bson.M{"$set": bson.M{"compliance": report, "updatedAt": time.Now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		http.Error(w, "Error updating deliverable", http.StatusInternalServerError)
+		return
+	}
+
+	publishEvent(EventDeliverableUpdated, updated, updated.BrandID, updated.CreatorID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
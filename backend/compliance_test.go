@@ -0,0 +1,92 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTagList(t *testing.T) {
+	tests := []struct {
+		list, prefix string
+		want         []string
+	}{
+		{"#Summer, #style  brandname", "#", []string{"#summer", "#style", "#brandname"}},
+		{"@Brand;@brand\n@other.co", "@", []string{"@brand", "@other.co"}},
+		{"  ,, ", "#", nil},
+		{"##double", "#", []string{"#double"}},
+	}
+	for _, tt := range tests {
+		if got := parseTagList(tt.list, tt.prefix); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseTagList(%q, %q) = %v, want %v", tt.list, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestParseBannedTerms(t *testing.T) {
+	tests := []struct {
+		guidelines string
+		want       []string
+	}{
+		{"Keep it upbeat.\nAvoid: cheap, knockoff.\n", []string{"cheap", "knockoff"}},
+		{"Banned terms: \"guaranteed results\", Cheap\nbanned words: cheap", []string{"guaranteed results", "Cheap"}},
+		{"Do not mention: competitors\nDon't say: free", []string{"competitors", "free"}},
+		{"We avoid clichés in general.", nil},
+	}
+	for _, tt := range tests {
+		if got := parseBannedTerms(tt.guidelines); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseBannedTerms(%q) = %v, want %v", tt.guidelines, got, tt.want)
+		}
+	}
+}
+
+func TestCheckContentCompliance(t *testing.T) {
+	campaign := &Campaign{
+		HashtagsToUse:     "#SummerDrop, #style",
+		MentionsRequired:  "@acme",
+		ContentGuidelines: "Avoid: cheap, free shipping",
+	}
+
+	tests := []struct {
+		name              string
+		text              string
+		passed            bool
+		missingHashtags   []string
+		missingMentions   []string
+		missingDisclosure bool
+		bannedTerms       []string
+	}{
+		{
+			name:            "compliant",
+			text:            "Loving my new look from @Acme. #summerdrop #Style #ad",
+			passed:          true,
+			missingHashtags: []string{}, missingMentions: []string{}, bannedTerms: []string{},
+		},
+		{
+			name:            "missing tags, mention and disclosure",
+			text:            "Loving my new look #style",
+			missingHashtags: []string{"#summerdrop"}, missingMentions: []string{"@acme"},
+			missingDisclosure: true, bannedTerms: []string{},
+		},
+		{
+			name:            "banned terms match whole words only",
+			text:            "Not cheap at all, plus FREE SHIPPING! @acme #summerdrop #style #sponsored #cheapskate",
+			missingHashtags: []string{}, missingMentions: []string{}, bannedTerms: []string{"cheap", "free shipping"},
+		},
+		{
+			name:            "banned term inside a word is allowed",
+			text:            "Cheaper than ever @acme #summerdrop #style #paidpartnership",
+			passed:          true,
+			missingHashtags: []string{}, missingMentions: []string{}, bannedTerms: []string{},
+		},
+	}
+	for _, tt := range tests {
+		report := checkContentCompliance(campaign, tt.text, 1)
+		if report.Passed != tt.passed ||
+			!reflect.DeepEqual(report.MissingHashtags, tt.missingHashtags) ||
+			!reflect.DeepEqual(report.MissingMentions, tt.missingMentions) ||
+			report.MissingDisclosure != tt.missingDisclosure ||
+			!reflect.DeepEqual(report.BannedTerms, tt.bannedTerms) {
+			t.Errorf("%s: got %+v", tt.name, report)
+		}
+	}
+}
@@ -1,34 +1,610 @@
 package main
 
-// AppConfig holds all application configuration
-type AppConfig struct {
-	Name         string
-	DisplayName  string
-	DatabaseName string
-	APIMessage   string
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds all application configuration. Values come from defaults,
+// then an optional YAML or TOML file, then environment variables.
+//
+// Field tags: config is the key in the file, env the environment variable
+// and secret marks values that `config print` redacts.
+type Config struct {
+	App struct {
+		Name         string `config:"name"`
+		DisplayName  string `config:"displayName"`
+		APIMessage   string `config:"apiMessage"`
+		FrontendURL  string `config:"frontendUrl" env:"FRONTEND_URL"`
+		PublicAPIURL string `config:"publicApiUrl" env:"PUBLIC_API_URL"` // Where crawlers and share links reach the API
+	} `config:"app"`
+
+	Server struct {
+		Port              string        `config:"port" env:"PORT"`
+		ReadHeaderTimeout time.Duration `config:"readHeaderTimeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+		ReadTimeout       time.Duration `config:"readTimeout" env:"SERVER_READ_TIMEOUT"`
+		WriteTimeout      time.Duration `config:"writeTimeout" env:"SERVER_WRITE_TIMEOUT"` // Event streams and exports lift it per response
+		IdleTimeout       time.Duration `config:"idleTimeout" env:"SERVER_IDLE_TIMEOUT"`
+		MaxHeaderBytes    int           `config:"maxHeaderBytes" env:"SERVER_MAX_HEADER_BYTES"`
+		ShutdownTimeout   time.Duration `config:"shutdownTimeout" env:"SERVER_SHUTDOWN_TIMEOUT"` // How long to drain requests and workers
+		TrustedProxies    []string      `config:"trustedProxies" env:"SERVER_TRUSTED_PROXIES"`   // IPs or CIDRs whose X-Forwarded-For is believed
+	} `config:"server"`
+
+	CORS struct {
+		AllowedOrigins   []string `config:"allowedOrigins" env:"CORS_ALLOWED_ORIGINS"`
+		AllowedMethods   []string `config:"allowedMethods" env:"CORS_ALLOWED_METHODS"`
+		AllowCredentials bool     `config:"allowCredentials" env:"CORS_ALLOW_CREDENTIALS"`
+	} `config:"cors"`
+
+	Mongo struct {
+		URI            string        `config:"uri" env:"MONGODB_URI" secret:"true"` // May carry a password
+		Database       string        `config:"database" env:"MONGODB_DATABASE"`
+		ConnectTimeout time.Duration `config:"connectTimeout" env:"MONGODB_CONNECT_TIMEOUT"`
+	} `config:"mongo"`
+
+	Clerk struct {
+		SecretKey      string `config:"secretKey" env:"CLERK_SECRET_KEY" secret:"true"`
+		PublishableKey string `config:"publishableKey" env:"CLERK_PUBLISHABLE_KEY"`
+	} `config:"clerk"`
+
+	Notifications struct {
+		SMTPHost     string `config:"smtpHost" env:"SMTP_HOST"`
+		SMTPPort     string `config:"smtpPort" env:"SMTP_PORT"`
+		SMTPFrom     string `config:"smtpFrom" env:"SMTP_FROM"`
+		SMTPUsername string `config:"smtpUsername" env:"SMTP_USERNAME"`
+		SMTPPassword string `config:"smtpPassword" env:"SMTP_PASSWORD" secret:"true"`
+		LogFile      string `config:"logFile" env:"NOTIFICATION_LOG_FILE"`
+	} `config:"notifications"`
+
+	Payments struct {
+		Provider string `config:"provider" env:"PAYMENT_PROVIDER"`
+	} `config:"payments"`
+
+	Rates struct {
+		Provider string `config:"provider" env:"EXCHANGE_RATES_PROVIDER"`
+		File     string `config:"file" env:"EXCHANGE_RATES_FILE"`
+	} `config:"rates"`
+
+	Storage struct {
+		Provider      string `config:"provider" env:"STORAGE_PROVIDER"`
+		LocalDir      string `config:"localDir" env:"STORAGE_LOCAL_DIR"`
+		PublicURL     string `config:"publicUrl" env:"STORAGE_PUBLIC_URL"`
+		SigningSecret string `config:"signingSecret" env:"STORAGE_SIGNING_SECRET" secret:"true"`
+		S3            struct {
+			Endpoint        string `config:"endpoint" env:"S3_ENDPOINT"`
+			Region          string `config:"region" env:"S3_REGION"`
+			Bucket          string `config:"bucket" env:"S3_BUCKET"`
+			AccessKeyID     string `config:"accessKeyId" env:"S3_ACCESS_KEY_ID"`
+			SecretAccessKey string `config:"secretAccessKey" env:"S3_SECRET_ACCESS_KEY" secret:"true"`
+			PathStyle       bool   `config:"pathStyle" env:"S3_PATH_STYLE"`
+		} `config:"s3"`
+	} `config:"storage"`
+
+	RateLimits struct {
+		Disabled  bool     `config:"disabled" env:"RATE_LIMIT_DISABLED"`
+		Overrides []string `config:"overrides" env:"RATE_LIMITS"` // e.g. "apply.influencer=10/h"
+	} `config:"rateLimits"`
+
+	Features struct {
+		PublicCampaignPages bool `config:"publicCampaignPages" env:"FEATURE_PUBLIC_CAMPAIGN_PAGES"`
+		MediaUploads        bool `config:"mediaUploads" env:"FEATURE_MEDIA_UPLOADS"`
+		CampaignImport      bool `config:"campaignImport" env:"FEATURE_CAMPAIGN_IMPORT"`
+		DebugEndpoints      bool `config:"debugEndpoints" env:"FEATURE_DEBUG_ENDPOINTS"` // Dumps of every campaign and application, for any signed-in user
+	} `config:"features"`
+
+	sources map[string]string // Where each non-default value came from, for config print
 }
 
-// APP_CONFIG - Change app name here and it will reflect everywhere
-var APP_CONFIG = AppConfig{
-	Name:         "SponsorConnect",
-	DisplayName:  "SponsorConnect",
-	DatabaseName: "sponsorconnect",
-	APIMessage:   "SponsorConnect API is running",
+// appConfig is the effective configuration, replaced by loadConfig at startup
+var appConfig = defaultConfig()
+
+// defaultConfig returns the built-in defaults
+func defaultConfig() *Config {
+	c := &Config{sources: map[string]string{}}
+
+	c.App.Name = "SponsorConnect"
+	c.App.DisplayName = "SponsorConnect"
+	c.App.APIMessage = "SponsorConnect API is running"
+	c.App.FrontendURL = "http://localhost:3000"
+	c.App.PublicAPIURL = "http://localhost:8080"
+
+	c.Server.Port = "8080"
+	c.Server.ReadHeaderTimeout = 10 * time.Second
+	c.Server.ReadTimeout = 5 * time.Minute // Large media uploads
+	c.Server.WriteTimeout = time.Minute
+	c.Server.IdleTimeout = 2 * time.Minute
+	c.Server.MaxHeaderBytes = 64 << 10 // Clerk tokens and cookies fit comfortably
+	c.Server.ShutdownTimeout = 30 * time.Second
+
+	c.CORS.AllowedOrigins = []string{"http://localhost:3000", "http://frontend:3000"}
+	c.CORS.AllowedMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	c.CORS.AllowCredentials = true
+
+	c.Mongo.Database = "sponsorconnect"
+	c.Mongo.ConnectTimeout = 10 * time.Second
+
+	c.Notifications.SMTPPort = "587"
+	c.Rates.Provider = "static"
+	c.Storage.Provider = "local"
+	c.Storage.LocalDir = "uploads"
+	c.Storage.S3.Region = "us-east-1"
+
+	c.Features.PublicCampaignPages = true
+	c.Features.MediaUploads = true
+	c.Features.CampaignImport = true
+	return c
 }
 
 // Convenience functions for getting config values
 func GetAppName() string {
-	return APP_CONFIG.Name
+	return appConfig.App.Name
 }
 
 func GetDisplayName() string {
-	return APP_CONFIG.DisplayName
+	return appConfig.App.DisplayName
 }
 
 func GetDatabaseName() string {
-	return APP_CONFIG.DatabaseName
+	return appConfig.Mongo.Database
 }
 
 func GetAPIMessage() string {
-	return APP_CONFIG.APIMessage
+	return appConfig.App.APIMessage
+}
+
+// defaultConfigFiles are tried in order when no file is named
+var defaultConfigFiles = []string{"config.yaml", "config.yml", "config.toml"}
+
+// loadConfig merges defaults, the config file at path (or the first default
+// file that exists) and the environment, then validates the result
+func loadConfig(path string) (*Config, error) {
+	c := defaultConfig()
+
+	if path == "" {
+		for _, candidate := range defaultConfigFiles {
+			if _, err := os.Stat(candidate); err == nil {
+				path = candidate
+				break
+			}
+		}
+	}
+	if path != "" {
+		values, err := readConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.apply(values, path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+	return c, c.Validate()
+}
+
+// configField is one leaf of Config found by reflection
+type configField struct {
+	Path   string // Dotted file key, e.g. "cors.allowedOrigins"
+	Env    string
+	Secret bool
+	Value  reflect.Value
+}
+
+// fields lists every settable leaf of the config in declaration order
+func (c *Config) fields() []configField {
+	var out []configField
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			key := field.Tag.Get("config")
+			if key == "" {
+				continue
+			}
+			path := prefix + key
+			if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Duration(0)) {
+				walk(v.Field(i), path+".")
+				continue
+			}
+			out = append(out, configField{
+				Path:   path,
+				Env:    field.Tag.Get("env"),
+				Secret: field.Tag.Get("secret") == "true",
+				Value:  v.Field(i),
+			})
+		}
+	}
+	walk(reflect.ValueOf(c).Elem(), "")
+	return out
+}
+
+// setConfigValue parses a file or environment value into a field. Lists
+// accept a list from a file or a comma-separated string.
+func setConfigValue(v reflect.Value, value interface{}) error {
+	if list, ok := value.([]string); ok {
+		if v.Kind() != reflect.Slice {
+			return fmt.Errorf("expected a single value, got a list")
+		}
+		v.Set(reflect.ValueOf(list))
+		return nil
+	}
+
+	s := value.(string)
+	switch {
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration like 30s or 5m", s)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(s)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", s)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", s)
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported config type %s", v.Type())
+	}
+	return nil
+}
+
+// apply sets values read from a config file; unknown keys are errors so typos do not go unnoticed
+func (c *Config) apply(values map[string]interface{}, source string) error {
+	fields := make(map[string]configField)
+	for _, field := range c.fields() {
+		fields[field.Path] = field
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		field, ok := fields[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown setting %q", source, key))
+			continue
+		}
+		if err := setConfigValue(field.Value, values[key]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s: %v", source, key, err))
+			continue
+		}
+		c.sources[key] = filepath.Base(source)
+	}
+	return errors.Join(errs...)
+}
+
+// applyEnv sets values from environment variables, which take precedence over the file
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	var errs []error
+	for _, field := range c.fields() {
+		if field.Env == "" {
+			continue
+		}
+		value, ok := lookup(field.Env)
+		if !ok || value == "" {
+			continue
+		}
+		if err := setConfigValue(field.Value, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", field.Env, err))
+			continue
+		}
+		c.sources[field.Path] = "env " + field.Env
+	}
+	return errors.Join(errs...)
+}
+
+// validateBaseURL checks an absolute http(s) URL
+func validateBaseURL(name, value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s must be an http(s) URL, got %q", name, value)
+	}
+	return nil
+}
+
+// Validate reports every problem at once so they can all be fixed in one go
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		check(fmt.Errorf("server.port must be a port number, got %q", c.Server.Port))
+	}
+	for name, d := range map[string]time.Duration{
+		"server.readHeaderTimeout": c.Server.ReadHeaderTimeout,
+		"server.readTimeout":       c.Server.ReadTimeout,
+		"server.writeTimeout":      c.Server.WriteTimeout,
+		"server.idleTimeout":       c.Server.IdleTimeout,
+		"server.shutdownTimeout":   c.Server.ShutdownTimeout,
+	} {
+		if d < 0 {
+			check(fmt.Errorf("%s must not be negative", name))
+		}
+	}
+	if c.Server.MaxHeaderBytes < 4<<10 {
+		check(fmt.Errorf("server.maxHeaderBytes must be at least 4096"))
+	}
+	if c.Server.ShutdownTimeout == 0 {
+		check(fmt.Errorf("server.shutdownTimeout must be positive"))
+	}
+	if _, err := parseTrustedProxies(c.Server.TrustedProxies); err != nil {
+		check(fmt.Errorf("server.trustedProxies: %w", err))
+	}
+	if c.Mongo.ConnectTimeout <= 0 {
+		check(fmt.Errorf("mongo.connectTimeout must be positive"))
+	}
+
+	if len(c.CORS.AllowedOrigins) == 0 {
+		check(fmt.Errorf("cors.allowedOrigins must list at least one origin"))
+	}
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			if c.CORS.AllowCredentials {
+				check(fmt.Errorf("cors.allowedOrigins cannot be \"*\" while cors.allowCredentials is on"))
+			}
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			check(fmt.Errorf("cors.allowedOrigins: %q must be a scheme and host like https://app.example.com", origin))
+		}
+	}
+
+	check(validateBaseURL("app.frontendUrl", c.App.FrontendURL))
+	check(validateBaseURL("app.publicApiUrl", c.App.PublicAPIURL))
+
+	if c.Mongo.URI == "" {
+		check(fmt.Errorf("mongo.uri (MONGODB_URI) is required"))
+	}
+	if c.Mongo.Database == "" {
+		check(fmt.Errorf("mongo.database (MONGODB_DATABASE) is required"))
+	}
+	if c.Clerk.SecretKey == "" {
+		check(fmt.Errorf("clerk.secretKey (CLERK_SECRET_KEY) is required"))
+	}
+	if c.Clerk.PublishableKey == "" {
+		check(fmt.Errorf("clerk.publishableKey (CLERK_PUBLISHABLE_KEY) is required"))
+	}
+
+	if c.Notifications.SMTPHost != "" && c.Notifications.SMTPFrom == "" {
+		check(fmt.Errorf("notifications.smtpFrom (SMTP_FROM) is required when an SMTP host is set"))
+	}
+	if c.Payments.Provider == "" {
+		check(fmt.Errorf("payments.provider (PAYMENT_PROVIDER) is required; use fake for development"))
+	} else if _, ok := paymentProviders[c.Payments.Provider]; !ok {
+		check(fmt.Errorf("payments.provider must be one of %s, got %q", strings.Join(paymentProviderNames(), ", "), c.Payments.Provider))
+	}
+	if c.Rates.Provider != "static" {
+		check(fmt.Errorf("rates.provider must be static, got %q", c.Rates.Provider))
+	}
+
+	switch c.Storage.Provider {
+	case "local":
+		// A random per-process key would break links on restart and across replicas
+		if c.Storage.SigningSecret == "" {
+			check(fmt.Errorf("storage.signingSecret (STORAGE_SIGNING_SECRET) is required for the local provider"))
+		}
+		if c.Storage.PublicURL != "" {
+			check(validateBaseURL("storage.publicUrl", c.Storage.PublicURL))
+		}
+	case "s3":
+		if c.Storage.S3.Bucket == "" || c.Storage.S3.AccessKeyID == "" || c.Storage.S3.SecretAccessKey == "" {
+			check(fmt.Errorf("storage.s3 bucket, accessKeyId and secretAccessKey are required for the s3 provider"))
+		}
+		if c.Storage.S3.Endpoint != "" {
+			check(validateBaseURL("storage.s3.endpoint", c.Storage.S3.Endpoint))
+		}
+	default:
+		check(fmt.Errorf("storage.provider must be local or s3, got %q", c.Storage.Provider))
+	}
+
+	for _, override := range c.RateLimits.Overrides {
+		_, _, _, err := parseRateLimitOverride(override)
+		check(err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// writeConfig prints the configuration as YAML with secrets redacted. Values
+// that did not come from the defaults are annotated with their source.
+func writeConfig(w io.Writer, c *Config) {
+	section := ""
+	for _, field := range c.fields() {
+		parts := strings.Split(field.Path, ".")
+		parent := strings.Join(parts[:len(parts)-1], ".")
+		if parent != section {
+			// Open each section level not already open
+			common := 0
+			previous := strings.Split(section, ".")
+			for common < len(previous) && common < len(parts)-1 && previous[common] == parts[common] && section != "" {
+				common++
+			}
+			for i := common; i < len(parts)-1; i++ {
+				fmt.Fprintf(w, "%s%s:\n", strings.Repeat("  ", i), parts[i])
+			}
+			section = parent
+		}
+
+		indent := strings.Repeat("  ", len(parts)-1)
+		comment := ""
+		if source, ok := c.sources[field.Path]; ok {
+			comment = "  # from " + source
+		}
+
+		v := field.Value
+		switch {
+		case field.Secret:
+			value := `""`
+			if v.String() != "" {
+				value = `"<redacted>"`
+			}
+			fmt.Fprintf(w, "%s%s: %s%s\n", indent, parts[len(parts)-1], value, comment)
+		case v.Type() == reflect.TypeOf(time.Duration(0)):
+			fmt.Fprintf(w, "%s%s: %s%s\n", indent, parts[len(parts)-1], time.Duration(v.Int()), comment)
+		case v.Kind() == reflect.Slice:
+			if v.Len() == 0 {
+				fmt.Fprintf(w, "%s%s: []%s\n", indent, parts[len(parts)-1], comment)
+				continue
+			}
+			fmt.Fprintf(w, "%s%s:%s\n", indent, parts[len(parts)-1], comment)
+			for i := 0; i < v.Len(); i++ {
+				fmt.Fprintf(w, "%s  - %s\n", indent, strconv.Quote(v.Index(i).String()))
+			}
+		case v.Kind() == reflect.String:
+			fmt.Fprintf(w, "%s%s: %s%s\n", indent, parts[len(parts)-1], strconv.Quote(v.String()), comment)
+		default:
+			fmt.Fprintf(w, "%s%s: %v%s\n", indent, parts[len(parts)-1], v.Interface(), comment)
+		}
+	}
+}
+
+// runConfigCommand handles `config print [-config file]`, returning the exit code
+func runConfigCommand(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("config", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configPath := flags.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML config file")
+
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(stderr, "usage: config print [-config file]")
+		return 2
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	c, err := loadConfig(*configPath)
+	if c == nil {
+		fmt.Fprintln(stderr, "Error loading config:", err)
+		return 1
+	}
+	writeConfig(stdout, c)
+	if err != nil {
+		// Still show the values so the problem can be found
+		fmt.Fprintf(stderr, "\nConfig is not valid:\n%v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// readConfigFile parses a YAML or TOML file into dotted keys
+func readConfigFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return parseYAMLConfig(string(data))
+	case ".toml":
+		return parseTOMLConfig(string(data))
+	}
+	return nil, fmt.Errorf("config file %s must end in .yaml, .yml or .toml", path)
+}
+
+// parseYAMLConfig reads a YAML config file into dotted keys
+func parseYAMLConfig(data string) (map[string]interface{}, error) {
+	var tree map[string]interface{}
+	if err := yaml.Unmarshal([]byte(data), &tree); err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{})
+	return values, flattenConfig(values, "", tree)
+}
+
+// parseTOMLConfig reads a TOML config file into dotted keys
+func parseTOMLConfig(data string) (map[string]interface{}, error) {
+	var tree map[string]interface{}
+	if err := toml.Unmarshal([]byte(data), &tree); err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{})
+	return values, flattenConfig(values, "", tree)
+}
+
+// flattenConfig turns a decoded file into the dotted keys apply expects, with
+// every value a string or a list of strings. Keys without a value are skipped.
+func flattenConfig(values map[string]interface{}, prefix string, tree map[string]interface{}) error {
+	for key, value := range tree {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case nil:
+		case map[string]interface{}:
+			if err := flattenConfig(values, path, v); err != nil {
+				return err
+			}
+		case []interface{}:
+			list := make([]string, 0, len(v))
+			for _, item := range v {
+				s, err := configScalar(item)
+				if err != nil {
+					return fmt.Errorf("%s: %v", path, err)
+				}
+				list = append(list, s)
+			}
+			values[path] = list
+		default:
+			s, err := configScalar(v)
+			if err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+			values[path] = s
+		}
+	}
+	return nil
+}
+
+// configScalar renders a decoded string, number or boolean as text for setConfigValue
+func configScalar(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	}
+	return "", fmt.Errorf("expected a string, number or boolean, got %T", value)
 }
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseYAMLConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "nested maps and scalars",
+			in:   "server:\n  port: 9090   # comment\n  readTimeout: \"1m\"\nmongo:\n  database: 'shop''s'\n",
+			want: map[string]interface{}{"server.port": "9090", "server.readTimeout": "1m", "mongo.database": "shop's"},
+		},
+		{
+			name: "block and inline lists",
+			in:   "---\ncors:\n  allowedOrigins:\n    - https://a.example\n    - \"https://b.example\"\n  allowedMethods: [GET, 'POST']\n",
+			want: map[string]interface{}{
+				"cors.allowedOrigins": []string{"https://a.example", "https://b.example"},
+				"cors.allowedMethods": []string{"GET", "POST"},
+			},
+		},
+		{
+			name: "deeper nesting returns to the parent",
+			in:   "storage:\n  s3:\n    bucket: media\n  provider: s3\n",
+			want: map[string]interface{}{"storage.s3.bucket": "media", "storage.provider": "s3"},
+		},
+		{
+			name: "hash inside quotes is kept",
+			in:   "app:\n  name: \"a # b\"\n",
+			want: map[string]interface{}{"app.name": "a # b"},
+		},
+		{
+			name: "numbers, booleans, block strings and empty keys",
+			in:   "server:\n  maxHeaderBytes: 8192\n  trustedProxies:\nfeatures:\n  mediaUploads: false\napp:\n  apiMessage: |\n    hello\n",
+			want: map[string]interface{}{"server.maxHeaderBytes": "8192", "features.mediaUploads": "false", "app.apiMessage": "hello\n"},
+		},
+		{name: "tab indent", in: "server:\n\t port: 1\n", wantErr: true},
+		{name: "list without key", in: "- a\n", wantErr: true},
+		{name: "missing colon", in: "server\n", wantErr: true},
+		{name: "unterminated list", in: "cors:\n  allowedMethods: [GET\n", wantErr: true},
+		{name: "map inside a list", in: "cors:\n  allowedOrigins:\n    - host: a.example\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseYAMLConfig(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseTOMLConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name: "tables and values",
+			in:   "# top\n[server]\nport = \"9090\"\nshutdownTimeout = \"45s\" # drain\n\n[storage.s3]\npathStyle = true\n",
+			want: map[string]interface{}{"server.port": "9090", "server.shutdownTimeout": "45s", "storage.s3.pathStyle": "true"},
+		},
+		{
+			name: "arrays and dotted keys",
+			in:   "cors.allowedOrigins = [\"https://a.example\", \"https://b.example\"]\n[rateLimits]\noverrides = []\n",
+			want: map[string]interface{}{
+				"cors.allowedOrigins":  []string{"https://a.example", "https://b.example"},
+				"rateLimits.overrides": []string{},
+			},
+		},
+		{
+			name: "numbers and inline tables",
+			in:   "server = { port = 9090, maxHeaderBytes = 8192 }\n",
+			want: map[string]interface{}{"server.port": "9090", "server.maxHeaderBytes": "8192"},
+		},
+		{name: "array of tables", in: "[[server]]\n", wantErr: true},
+		{name: "missing equals", in: "[server]\nport\n", wantErr: true},
+		{name: "bad string", in: "name = \"unterminated\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseTOMLConfig(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConfigApplyEnv(t *testing.T) {
+	env := map[string]string{
+		"PORT":                    "9090",
+		"SERVER_READ_TIMEOUT":     "90s",
+		"CORS_ALLOWED_ORIGINS":    "https://a.example, https://b.example,",
+		"CORS_ALLOW_CREDENTIALS":  "false",
+		"SERVER_MAX_HEADER_BYTES": "8192",
+		"PAYMENT_PROVIDER":        "fake",
+		"SMTP_HOST":               "", // Empty variables are ignored
+	}
+	c := defaultConfig()
+	if err := c.applyEnv(func(key string) (string, bool) { v, ok := env[key]; return v, ok }); err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.Port != "9090" || c.Server.ReadTimeout != 90*time.Second || c.Server.MaxHeaderBytes != 8192 {
+		t.Errorf("server = %+v", c.Server)
+	}
+	if !reflect.DeepEqual(c.CORS.AllowedOrigins, []string{"https://a.example", "https://b.example"}) || c.CORS.AllowCredentials {
+		t.Errorf("cors = %+v", c.CORS)
+	}
+	if c.Payments.Provider != "fake" {
+		t.Errorf("payments.provider = %q", c.Payments.Provider)
+	}
+	if c.sources["server.port"] != "env PORT" {
+		t.Errorf("source of server.port = %q", c.sources["server.port"])
+	}
+	if _, ok := c.sources["notifications.smtpHost"]; ok {
+		t.Error("empty SMTP_HOST was applied")
+	}
+
+	bad := map[string]string{"SERVER_READ_TIMEOUT": "soon", "CORS_ALLOW_CREDENTIALS": "maybe", "SERVER_MAX_HEADER_BYTES": "8k"}
+	err := defaultConfig().applyEnv(func(key string) (string, bool) { v, ok := bad[key]; return v, ok })
+	for key := range bad {
+		if err == nil || !strings.Contains(err.Error(), key) {
+			t.Errorf("applyEnv error %v does not mention %s", err, key)
+		}
+	}
+}
+
+func TestConfigApplyFile(t *testing.T) {
+	c := defaultConfig()
+	err := c.apply(map[string]interface{}{"server.port": "7000", "server.trustedProxies": []string{"10.0.0.0/8"}}, "/etc/app/config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Server.Port != "7000" || c.sources["server.port"] != "config.yaml" {
+		t.Errorf("port = %q from %q", c.Server.Port, c.sources["server.port"])
+	}
+
+	err = defaultConfig().apply(map[string]interface{}{"server.prot": "7000", "server.port": []string{"1"}}, "config.yaml")
+	if err == nil || !strings.Contains(err.Error(), `unknown setting "server.prot"`) || !strings.Contains(err.Error(), "expected a single value") {
+		t.Errorf("apply error = %v", err)
+	}
+}
+
+// validTestConfig returns defaults plus the settings that have none
+func validTestConfig() *Config {
+	c := defaultConfig()
+	c.Mongo.URI = "mongodb://localhost:27017"
+	c.Clerk.SecretKey = "sk_test"
+	c.Clerk.PublishableKey = "pk_test"
+	c.Payments.Provider = "fake"
+	c.Storage.SigningSecret = "secret"
+	return c
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := validTestConfig().Validate(); err != nil {
+		t.Fatalf("valid config: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   string
+	}{
+		{"no payment provider", func(c *Config) { c.Payments.Provider = "" }, "payments.provider (PAYMENT_PROVIDER) is required"},
+		{"unknown payment provider", func(c *Config) { c.Payments.Provider = "stripe" }, "payments.provider must be one of fake"},
+		{"no signing secret", func(c *Config) { c.Storage.SigningSecret = "" }, "storage.signingSecret"},
+		{"bad port", func(c *Config) { c.Server.Port = "http" }, "server.port"},
+		{"wildcard with credentials", func(c *Config) { c.CORS.AllowedOrigins = []string{"*"} }, "cors.allowedOrigins"},
+		{"bad proxy", func(c *Config) { c.Server.TrustedProxies = []string{"proxy"} }, "server.trustedProxies"},
+		{"bad rate limit", func(c *Config) { c.RateLimits.Overrides = []string{"apply=10/m"} }, "group.userType"},
+		{"unknown rate limit group", func(c *Config) { c.RateLimits.Overrides = []string{"aply.brand=10/m"} }, `unknown group "aply"`},
+	}
+	for _, tt := range tests {
+		c := validTestConfig()
+		tt.change(c)
+		if err := c.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Validate() = %v, want an error mentioning %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestDefaultConfigIsSafe(t *testing.T) {
+	c := defaultConfig()
+	if c.Features.DebugEndpoints {
+		t.Error("debug endpoints are on by default")
+	}
+	if c.Payments.Provider != "" {
+		t.Errorf("payment provider defaults to %q; it must be chosen explicitly", c.Payments.Provider)
+	}
+}
@@ -0,0 +1,677 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Contract states
+const (
+	ContractStatusPending    = "pending_signatures"
+	ContractStatusSigned     = "signed"
+	ContractStatusSuperseded = "superseded" // Replaced by a newer version
+)
+
+// EventContractUpdated is pushed when a contract is generated or signed
+const EventContractUpdated = "contract.updated"
+
+// Contract notification types
+const (
+	NotificationContractReady  = "contract.ready"
+	NotificationContractSigned = "contract.signed"
+)
+
+// maxContractVersionAttempts bounds retries when concurrent requests race for a version number
+const maxContractVersionAttempts = 3
+
+// defaultContractTemplateName names the built-in template
+const defaultContractTemplateName = "default"
+
+//go:embed templates/contracts/*.tmpl
+var contractTemplateFS embed.FS
+
+// contractFuncs are the helpers available in contract templates
+var contractFuncs = template.FuncMap{"join": strings.Join}
+
+// ContractData is what contract templates are rendered with
+type ContractData struct {
+	Version       int
+	GeneratedAt   time.Time
+	Brand         InvoiceParty
+	Creator       InvoiceParty
+	Campaign      *Campaign
+	Platform      string
+	Deliverables  []Deliverable
+	ApprovalSteps []string
+	Currency      string
+	Fee           string // Formatted, empty when there is no fixed fee
+}
+
+// parseContractTemplate parses a contract template, failing on unknown fields
+func parseContractTemplate(name, body string) (*template.Template, error) {
+	return template.New(name).Funcs(contractFuncs).Option("missingkey=error").Parse(body)
+}
+
+// sampleContractData is used to check that a brand's template renders
+func sampleContractData() ContractData {
+	return ContractData{
+		Version:     1,
+		GeneratedAt: time.Now(),
+		Brand:       InvoiceParty{Name: "Brand"},
+		Creator:     InvoiceParty{Name: "Creator"},
+		Campaign:    &Campaign{Title: "Campaign", StartDate: "2026-01-01", EndDate: "2026-01-31"},
+		Platform:    "Instagram",
+		Deliverables: []Deliverable{
+			{Sequence: 1, Title: "post #1", ContentFormat: "post", DueDate: "2026-01-15"},
+		},
+		Currency: defaultCurrency,
+		Fee:      "100.00",
+	}
+}
+
+// brandContractTemplate returns the brand's default template, or the built-in one
+func brandContractTemplate(ctx context.Context, brandID string) (string, string, error) {
+	var custom ContractTemplate
+	err := database.Collection("contract_templates").FindOne(ctx, bson.M{"brandId": brandID, "isDefault": true}).Decode(&custom)
+	if err == nil {
+		return custom.Name, custom.Body, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return "", "", err
+	}
+
+	body, err := contractTemplateFS.ReadFile("templates/contracts/default.txt.tmpl")
+	if err != nil {
+		return "", "", err
+	}
+	return defaultContractTemplateName, string(body), nil
+}
+
+// documentHash fingerprints a contract body so signatures bind to its exact text
+func documentHash(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
+
+// initContracts creates the contract indexes
+func initContracts() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := database.Collection("contracts").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "applicationId", Value: 1}, {Key: "version", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create contract indexes: %w", err)
+	}
+	return nil
+}
+
+// generateContract stores a new contract version and asks both parties to sign it
+func generateContract(ctx context.Context, campaign *Campaign, application *Application) (*Contract, error) {
+	contract, err := storeContract(ctx, campaign, application)
+	if err != nil {
+		return nil, err
+	}
+	announceContract(campaign, contract)
+	return contract, nil
+}
+
+// storeContract renders a new contract version for an approved application,
+// superseding earlier versions
+func storeContract(ctx context.Context, campaign *Campaign, application *Application) (*Contract, error) {
+	name, body, err := brandContractTemplate(ctx, campaign.BrandID)
+	if err != nil {
+		return nil, fmt.Errorf("error loading contract template: %w", err)
+	}
+	tmpl, err := parseContractTemplate(name, body)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing contract template %q: %w", name, err)
+	}
+
+	deliverables, err := findDeliverables(ctx, bson.M{"applicationId": application.ID})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching deliverables: %w", err)
+	}
+
+	data := ContractData{
+		GeneratedAt:   time.Now(),
+		Brand:         invoiceParty(ctx, campaign.BrandID, campaign.BrandName, ""),
+		Creator:       invoiceParty(ctx, application.CreatorID, application.CreatorName, application.CreatorEmail),
+		Campaign:      campaign,
+		Platform:      application.Platform,
+		Deliverables:  deliverables,
+		ApprovalSteps: deliverableApprovalSteps(campaign),
+		Currency:      campaignCurrency(campaign),
+	}
+	if fee := applicationFee(campaign, application); fee > 0 {
+		data.Fee = formatMoney(fee, data.Currency)
+	}
+
+	// Versions are unique per application; if another request takes the
+	// next number first, render again with the one after it
+	collection := database.Collection("contracts")
+	var contract Contract
+	for attempt := 0; ; attempt++ {
+		var latest Contract
+		err := collection.FindOne(ctx, bson.M{"applicationId": application.ID},
+			options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}}),
+		).Decode(&latest)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return nil, err
+		}
+		data.Version = latest.Version + 1
+
+		var rendered bytes.Buffer
+		if err := tmpl.Execute(&rendered, data); err != nil {
+			return nil, fmt.Errorf("error rendering contract template %q: %w", name, err)
+		}
+
+		contract = Contract{
+			ID:            primitive.NewObjectID(),
+			ApplicationID: application.ID,
+			CampaignID:    campaign.ID,
+			BrandID:       campaign.BrandID,
+			CreatorID:     application.CreatorID,
+			Version:       data.Version,
+			TemplateName:  name,
+			Body:          rendered.String(),
+			DocumentHash:  documentHash(rendered.String()),
+			Status:        ContractStatusPending,
+			Signatures:    []ContractSignature{},
+			CreatedAt:     time.Now(),
+		}
+		_, err = collection.InsertOne(ctx, contract)
+		if err == nil {
+			break
+		}
+		if !mongo.IsDuplicateKeyError(err) || attempt >= maxContractVersionAttempts {
+			return nil, err
+		}
+	}
+
+	_, err = collection.UpdateMany(ctx,
+		bson.M{"applicationId": application.ID, "_id": bson.M{"$ne": contract.ID}, "status": bson.M{"$ne": ContractStatusSuperseded}},
+		bson.M{"$set": bson.M{"status": ContractStatusSuperseded}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &contract, nil
+}
+
+// supersedePendingContracts closes the unsigned contracts of an application
+// that is no longer approved, so neither side can sign them afterwards
+func supersedePendingContracts(ctx context.Context, applicationID primitive.ObjectID) error {
+	_, err := database.Collection("contracts").UpdateMany(ctx,
+		bson.M{"applicationId": applicationID, "status": ContractStatusPending},
+		bson.M{"$set": bson.M{"status": ContractStatusSuperseded}},
+	)
+	return err
+}
+
+// announceContract pushes a stored contract version to both parties and asks them to sign
+func announceContract(campaign *Campaign, contract *Contract) {
+	publishEvent(EventContractUpdated, *contract, contract.BrandID, contract.CreatorID)
+	notifyContractReady(campaign, contract)
+}
+
+// notifyContractReady asks both parties to review and sign a new contract version
+func notifyContractReady(campaign *Campaign, contract *Contract) {
+	title := "Contract ready to sign: " + campaign.Title
+	body := fmt.Sprintf("Version %d of the contract for %s is ready for your signature.", contract.Version, campaign.Title)
+	data := map[string]interface{}{"contractId": contract.ID.Hex(), "applicationId": contract.ApplicationID.Hex()}
+
+	notificationService.Notify(contract.BrandID, NotificationContractReady, title, body, "/brand/applications", data)
+	notificationService.Notify(contract.CreatorID, NotificationContractReady, title, body, "/creator/dashboard", data)
+}
+
+// notifyContractSigned tells both parties once everyone has signed
+func notifyContractSigned(contract *Contract) {
+	title := fmt.Sprintf("Contract v%d signed", contract.Version)
+	body := "The contract has been signed by both parties."
+	data := map[string]interface{}{"contractId": contract.ID.Hex(), "applicationId": contract.ApplicationID.Hex()}
+
+	notificationService.Notify(contract.BrandID, NotificationContractSigned, title, body, "/brand/applications", data)
+	notificationService.Notify(contract.CreatorID, NotificationContractSigned, title, body, "/creator/dashboard", data)
+}
+
+// loadContractForParticipant fetches a contract the user is a party to
+func loadContractForParticipant(ctx context.Context, contractId, userID string) (*Contract, int, string) {
+	contractOID, err := primitive.ObjectIDFromHex(contractId)
+	if err != nil {
+		return nil, http.StatusBadRequest, "Invalid contract ID"
+	}
+
+	var contract Contract
+	err = database.Collection("contracts").FindOne(ctx, bson.M{"_id": contractOID}).Decode(&contract)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, http.StatusNotFound, "Contract not found"
+		}
+		return nil, http.StatusInternalServerError, "Error fetching contract"
+	}
+
+	if contract.BrandID != userID && contract.CreatorID != userID {
+		return nil, http.StatusForbidden, "Access denied"
+	}
+	return &contract, http.StatusOK, ""
+}
+
+// contractLineWidth is how many characters fit on a contract PDF line
+const contractLineWidth = 85
+
+// wrapLine splits line into pieces of at most width runes, breaking at the
+// last space where there is one. Continuation lines are indented by two
+// spaces, which are never used as a break.
+func wrapLine(line string, width int) []string {
+	var lines []string
+	runes := []rune(line)
+	for len(runes) > width {
+		cut := width
+		for i := width - 1; i > 2; i-- {
+			if runes[i] == ' ' {
+				cut = i
+				break
+			}
+		}
+		lines = append(lines, string(runes[:cut]))
+		rest := strings.TrimLeft(string(runes[cut:]), " ")
+		runes = []rune("  " + rest)
+	}
+	return append(lines, string(runes))
+}
+
+// renderContractPDF lays a contract and its signatures out as a PDF
+func renderContractPDF(contract *Contract) []byte {
+	doc := &PDFDocument{Title: fmt.Sprintf("Contract v%d", contract.Version)}
+	for _, line := range strings.Split(contract.Body, "\n") {
+		for _, wrapped := range wrapLine(line, contractLineWidth) {
+			doc.Line("%s", wrapped)
+		}
+	}
+
+	doc.Blank()
+	doc.Heading("SIGNATURES")
+	doc.Line("Document SHA-256: %s", contract.DocumentHash)
+	if len(contract.Signatures) == 0 {
+		doc.Line("Not signed yet")
+	}
+	for _, signature := range contract.Signatures {
+		doc.Line("%s: %s, signed %s from %s", signature.Role, signature.Name,
+			signature.SignedAt.UTC().Format(time.RFC3339), signature.IP)
+	}
+	return doc.Bytes()
+}
+
+// Handlers
+
+// getApplicationContractsHandler lists every contract version of an application, newest first
+func getApplicationContractsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	applicationId := vars["applicationId"]
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	// Convert application ID to ObjectID
+	appObjID, err := primitive.ObjectIDFromHex(applicationId)
+	if err != nil {
+		http.Error(w, "Invalid application ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, _, status, msg := loadApplicationForParticipant(ctx, appObjID, getUserIDFromClerkUser(user)); status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "version", Value: -1}})
+	cursor, err := database.Collection("contracts").Find(ctx, bson.M{"applicationId": appObjID}, opts)
+	if err != nil {
+		http.Error(w, "Error fetching contracts", http.StatusInternalServerError)
+		return
+	}
+	contracts := []Contract{}
+	if err = cursor.All(ctx, &contracts); err != nil {
+		http.Error(w, "Error decoding contracts", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(contracts)
+}
+
+// regenerateContractHandler lets the brand issue a new contract version for an
+// approved application, e.g. after editing the campaign or template
+func regenerateContractHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	applicationId := vars["applicationId"]
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	// Convert application ID to ObjectID
+	appObjID, err := primitive.ObjectIDFromHex(applicationId)
+	if err != nil {
+		http.Error(w, "Invalid application ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID := getUserIDFromClerkUser(user)
+	application, campaign, status, msg := loadApplicationForParticipant(ctx, appObjID, userID)
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	if campaign.BrandID != userID {
+		http.Error(w, "Only the brand can issue contracts", http.StatusForbidden)
+		return
+	}
+	if application.Status != "approved" {
+		http.Error(w, "Contracts are only issued for approved applications", http.StatusConflict)
+		return
+	}
+
+	contract, err := generateContract(ctx, campaign, application)
+	if err != nil {
+		log.Printf("Error generating contract for application %s: %v", application.ID.Hex(), err)
+		http.Error(w, "Error generating contract", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(contract)
+}
+
+// getContractHandler returns one contract version as JSON
+func getContractHandler(w http.ResponseWriter, r *http.Request) {
+	serveContract(w, r, false)
+}
+
+// getContractPDFHandler renders one contract version as a PDF
+func getContractPDFHandler(w http.ResponseWriter, r *http.Request) {
+	serveContract(w, r, true)
+}
+
+// serveContract loads the contract named in the URL and writes it as JSON or PDF
+func serveContract(w http.ResponseWriter, r *http.Request, asPDF bool) {
+	vars := mux.Vars(r)
+	contractId := vars["contractId"]
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	contract, status, msg := loadContractForParticipant(ctx, contractId, getUserIDFromClerkUser(user))
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	if asPDF {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="contract-v%d.pdf"`, contract.Version))
+		w.Write(renderContractPDF(contract))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(contract)
+}
+
+// signContractHandler records the signed-in party's click-to-sign acceptance.
+// The client sends back the document hash it displayed, so a signature always
+// binds to the exact text the signer saw.
+func signContractHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	contractId := vars["contractId"]
+
+	var req struct {
+		Name         string `json:"name"`
+		DocumentHash string `json:"documentHash"`
+		Accept       bool   `json:"accept"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if !req.Accept || strings.TrimSpace(req.Name) == "" {
+		http.Error(w, "Signing requires accept: true and the signer's full name", http.StatusBadRequest)
+		return
+	}
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID := getUserIDFromClerkUser(user)
+	contract, status, msg := loadContractForParticipant(ctx, contractId, userID)
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	if contract.Status != ContractStatusPending {
+		http.Error(w, "This contract version can no longer be signed", http.StatusConflict)
+		return
+	}
+	if req.DocumentHash != contract.DocumentHash {
+		http.Error(w, "documentHash does not match this contract version; reload it before signing", http.StatusConflict)
+		return
+	}
+
+	role := "creator"
+	if contract.BrandID == userID {
+		role = "brand"
+	}
+
+	now := time.Now()
+	signature := ContractSignature{
+		UserID:       userID,
+		Role:         role,
+		Name:         strings.TrimSpace(req.Name),
+		DocumentHash: contract.DocumentHash,
+		IP:           clientIP(r),
+		UserAgent:    r.UserAgent(),
+		SignedAt:     now,
+	}
+
+	// Only add the signature while the version is pending and unsigned by this user
+	collection := database.Collection("contracts")
+	var updated Contract
+	err := collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": contract.ID, "status": ContractStatusPending, "signatures.userId": bson.M{"$ne": userID}},
+		bson.M{"$push": bson.M{"signatures": signature}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		http.Error(w, "You have already signed this contract, or it was superseded", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error signing contract", http.StatusInternalServerError)
+		return
+	}
+
+	// Fully signed once both roles have signed
+	signed := map[string]bool{}
+	for _, s := range updated.Signatures {
+		signed[s.Role] = true
+	}
+	if signed["brand"] && signed["creator"] {
+		err = collection.FindOneAndUpdate(ctx,
+			bson.M{"_id": contract.ID, "status": ContractStatusPending},
+			bson.M{"$set": bson.M{"status": ContractStatusSigned, "signedAt": now}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&updated)
+		if err == nil {
+			notifyContractSigned(&updated)
+		}
+	}
+
+	publishEvent(EventContractUpdated, updated, updated.BrandID, updated.CreatorID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// getContractTemplatesHandler lists the brand's contract templates and the built-in default
+func getContractTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	if getUserTypeFromClerkUser(user) != "brand" {
+		http.Error(w, "Only brands can manage contract templates", http.StatusForbidden)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := database.Collection("contract_templates").Find(ctx, bson.M{"brandId": getUserIDFromClerkUser(user)}, opts)
+	if err != nil {
+		http.Error(w, "Error fetching templates", http.StatusInternalServerError)
+		return
+	}
+	templates := []ContractTemplate{}
+	if err = cursor.All(ctx, &templates); err != nil {
+		http.Error(w, "Error decoding templates", http.StatusInternalServerError)
+		return
+	}
+
+	builtIn, _ := contractTemplateFS.ReadFile("templates/contracts/default.txt.tmpl")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"templates": templates,
+		"builtIn":   ContractTemplate{Name: defaultContractTemplateName, Body: string(builtIn)},
+	})
+}
+
+// createContractTemplateHandler saves a brand's contract template after checking that it renders
+func createContractTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name      string `json:"name"`
+		Body      string `json:"body"`
+		IsDefault bool   `json:"isDefault"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || strings.TrimSpace(req.Body) == "" {
+		http.Error(w, "name and body are required", http.StatusBadRequest)
+		return
+	}
+
+	// Reject templates that do not parse or reference unknown fields
+	tmpl, err := parseContractTemplate(req.Name, req.Body)
+	if err == nil {
+		err = tmpl.Execute(&bytes.Buffer{}, sampleContractData())
+	}
+	if err != nil {
+		http.Error(w, "Invalid template: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	if getUserTypeFromClerkUser(user) != "brand" {
+		http.Error(w, "Only brands can manage contract templates", http.StatusForbidden)
+		return
+	}
+
+	userID := getUserIDFromClerkUser(user)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	collection := database.Collection("contract_templates")
+	if req.IsDefault {
+		_, err := collection.UpdateMany(ctx, bson.M{"brandId": userID, "isDefault": true}, bson.M{"$set": bson.M{"isDefault": false}})
+		if err != nil {
+			http.Error(w, "Error updating templates", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	now := time.Now()
+	contractTemplate := ContractTemplate{
+		ID:        primitive.NewObjectID(),
+		BrandID:   userID,
+		Name:      req.Name,
+		Body:      req.Body,
+		IsDefault: req.IsDefault,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if _, err := collection.InsertOne(ctx, contractTemplate); err != nil {
+		http.Error(w, "Error creating template", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(contractTemplate)
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestWrapLine(t *testing.T) {
+	tests := []struct {
+		line  string
+		width int
+		want  []string
+	}{
+		{"short line", 20, []string{"short line"}},
+		{"the quick brown fox jumps", 10, []string{"the quick", "  brown", "  fox", "  jumps"}},
+		{"abcdefghijkl", 5, []string{"abcde", "  fgh", "  ijk", "  l"}},
+		{"Zahlung für Leistungen über", 12, []string{"Zahlung für", "  Leistungen", "  über"}},
+		{"日本語の契約書本文です", 4, []string{"日本語の", "  契約", "  書本", "  文で", "  す"}},
+	}
+	for _, tt := range tests {
+		got := wrapLine(tt.line, tt.width)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("wrapLine(%q, %d) = %q, want %q", tt.line, tt.width, got, tt.want)
+		}
+		for _, line := range got {
+			if !utf8.ValidString(line) || utf8.RuneCountInString(line) > tt.width {
+				t.Errorf("wrapLine(%q, %d) produced %q", tt.line, tt.width, line)
+			}
+		}
+	}
+}
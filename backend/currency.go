@@ -0,0 +1,488 @@
+package main
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// iso4217Codes lists the active ISO 4217 currency codes
+var iso4217Codes = map[string]bool{}
+
+func init() {
+	for _, code := range strings.Fields(`
+		AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND BOB BRL
+		BSD BTN BWP BYN BZD CAD CDF CHF CLP CNY COP CRC CUP CVE CZK DJF DKK DOP DZD EGP
+		ERN ETB EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR
+		IQD IRR ISK JMD JOD JPY KES KGS KHR KMF KPW KRW KWD KYD KZT LAK LBP LKR LRD LSL
+		LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MYR MZN NAD NGN NIO NOK NPR
+		NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF SAR SBD SCR SDG SEK SGD
+		SHP SLE SOS SRD SSP STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS UAH UGX
+		USD UYU UZS VES VND VUV WST XAF XCD XOF XPF YER ZAR ZMW ZWG`) {
+		iso4217Codes[code] = true
+	}
+}
+
+// currencyMinorUnits lists the ISO 4217 currencies whose minor unit is not
+// two decimals (cents)
+var currencyMinorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// minorUnits returns the number of decimals in a currency's minor unit
+func minorUnits(currency string) int {
+	if decimals, ok := currencyMinorUnits[currency]; ok {
+		return decimals
+	}
+	return 2
+}
+
+// normalizeCurrency upper-cases a currency code and checks it against ISO 4217
+func normalizeCurrency(code string) (string, error) {
+	currency := strings.ToUpper(strings.TrimSpace(code))
+	if !iso4217Codes[currency] {
+		return "", fmt.Errorf("invalid currency %q: must be an ISO 4217 code such as USD or EUR", code)
+	}
+	return currency, nil
+}
+
+// ExchangeRate converts amounts from one currency to another as of Date
+type ExchangeRate struct {
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Rate   float64 `json:"rate"`
+	Date   string  `json:"date"` // YYYY-MM-DD the rate applies to
+	Source string  `json:"source"`
+}
+
+// Convert applies the rate to an amount in minor units of From, returning
+// minor units of To
+func (r ExchangeRate) Convert(amount int64) int64 {
+	scale := math.Pow10(minorUnits(r.To) - minorUnits(r.From))
+	return int64(math.Round(float64(amount) * r.Rate * scale))
+}
+
+// RatesProvider supplies exchange rates for reporting
+type RatesProvider interface {
+	Rate(ctx context.Context, from, to string) (ExchangeRate, error)
+}
+
+// StaticRatesProvider serves rates from a JSON table quoted against one base
+// currency. It is used offline, in tests and as the default provider.
+type StaticRatesProvider struct {
+	Base   string             `json:"base"`
+	Date   string             `json:"date"`
+	Source string             `json:"source"`
+	Rates  map[string]float64 `json:"rates"` // Units of each currency per one unit of Base
+}
+
+//go:embed rates/static.json
+var staticRatesFS embed.FS
+
+// LoadStaticRatesProvider reads a rates table from path, or the embedded table if path is empty
+func LoadStaticRatesProvider(path string) (*StaticRatesProvider, error) {
+	var data []byte
+	var err error
+	if path == "" {
+		data, err = staticRatesFS.ReadFile("rates/static.json")
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exchange rates: %w", err)
+	}
+
+	var provider StaticRatesProvider
+	if err := json.Unmarshal(data, &provider); err != nil {
+		return nil, fmt.Errorf("failed to parse exchange rates: %w", err)
+	}
+	if provider.Base == "" || len(provider.Rates) == 0 {
+		return nil, fmt.Errorf("exchange rates file must define base and rates")
+	}
+	if provider.Source == "" {
+		provider.Source = "static"
+	}
+	provider.Rates[provider.Base] = 1
+	return &provider, nil
+}
+
+// Rate implements RatesProvider by crossing both currencies through the base
+func (p *StaticRatesProvider) Rate(ctx context.Context, from, to string) (ExchangeRate, error) {
+	fromRate, ok := p.Rates[from]
+	if !ok || fromRate <= 0 {
+		return ExchangeRate{}, fmt.Errorf("no exchange rate for %s", from)
+	}
+	toRate, ok := p.Rates[to]
+	if !ok || toRate <= 0 {
+		return ExchangeRate{}, fmt.Errorf("no exchange rate for %s", to)
+	}
+
+	return ExchangeRate{From: from, To: to, Rate: toRate / fromRate, Date: p.Date, Source: p.Source}, nil
+}
+
+// ratesProvider is the process-wide rates provider, set up by initRates
+var ratesProvider RatesProvider
+
+// initRates selects the exchange rates provider (rates.provider)
+func initRates() error {
+	switch provider := appConfig.Rates.Provider; provider {
+	case "", "static":
+		static, err := LoadStaticRatesProvider(appConfig.Rates.File)
+		if err != nil {
+			return err
+		}
+		ratesProvider = static
+		log.Printf("Exchange rates: using static rates from %s (base %s)", static.Date, static.Base)
+	default:
+		return fmt.Errorf("unknown exchange rates provider %q", provider)
+	}
+	return nil
+}
+
+// reportingCurrency picks the currency to report in: ?currency=, then the user's setting, then USD
+func reportingCurrency(ctx context.Context, r *http.Request, userID string) (string, error) {
+	if code := r.URL.Query().Get("currency"); code != "" {
+		return normalizeCurrency(code)
+	}
+
+	var user User
+	err := database.Collection("users").FindOne(ctx, bson.M{"clerkId": userID}).Decode(&user)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return "", err
+	}
+	if user.ReportingCurrency != "" {
+		return user.ReportingCurrency, nil
+	}
+	return defaultCurrency, nil
+}
+
+// checkReportingRate makes sure amounts can be converted into the reporting currency at all
+func checkReportingRate(ctx context.Context, currency string) (int, string) {
+	if _, err := ratesProvider.Rate(ctx, currency, currency); err != nil {
+		return http.StatusUnprocessableEntity, fmt.Sprintf("Cannot report in %s: %v", currency, err)
+	}
+	return http.StatusOK, ""
+}
+
+// rateCache looks each currency pair up once per report
+type rateCache struct {
+	to    string
+	rates map[string]ExchangeRate
+}
+
+// convert converts amount from currency into the report currency
+func (c *rateCache) convert(ctx context.Context, amount int64, from string) (int64, ExchangeRate, error) {
+	rate, ok := c.rates[from]
+	if !ok {
+		var err error
+		rate, err = ratesProvider.Rate(ctx, from, c.to)
+		if err != nil {
+			return 0, rate, err
+		}
+		c.rates[from] = rate
+	}
+	return rate.Convert(amount), rate, nil
+}
+
+// usedRates lists the rates applied in a report
+func (c *rateCache) usedRates() []ExchangeRate {
+	rates := []ExchangeRate{}
+	for _, rate := range c.rates {
+		rates = append(rates, rate)
+	}
+	return rates
+}
+
+// ConvertedAmounts holds amounts in minor units
+type ConvertedAmounts struct {
+	Budget     int64 `json:"budget"`
+	Reserved   int64 `json:"reserved"`
+	Committed  int64 `json:"committed"`
+	Receivable int64 `json:"receivable"`
+	Paid       int64 `json:"paid"`
+}
+
+// CampaignSpendRow is one campaign's spend in its own and the reporting currency
+type CampaignSpendRow struct {
+	CampaignID primitive.ObjectID `json:"campaignId"`
+	Title      string             `json:"title"`
+	Currency   string             `json:"currency"`
+	Amounts    ConvertedAmounts   `json:"amounts"`
+	Converted  ConvertedAmounts   `json:"converted"`
+	Rate       ExchangeRate       `json:"rate"`
+	// Why the row is left out of the totals, e.g. a legacy currency without a rate
+	Unconverted string `json:"unconverted,omitempty"`
+}
+
+// campaignLedgerTotals sums the brand's ledger balances per campaign and bucket
+func campaignLedgerTotals(ctx context.Context, brandID string) (map[primitive.ObjectID]map[string]int64, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"brandId": brandID}}},
+		{{Key: "$unwind", Value: "$entries"}},
+		{{Key: "$match", Value: bson.M{"entries.ownerType": "brand", "entries.ownerId": brandID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":    bson.M{"campaignId": "$campaignId", "bucket": "$entries.bucket"},
+			"amount": bson.M{"$sum": "$entries.amount"},
+		}}},
+	}
+
+	cursor, err := database.Collection("ledger_transactions").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		ID struct {
+			CampaignID primitive.ObjectID `bson:"campaignId"`
+			Bucket     string             `bson:"bucket"`
+		} `bson:"_id"`
+		Amount int64 `bson:"amount"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	totals := make(map[primitive.ObjectID]map[string]int64)
+	for _, row := range rows {
+		if totals[row.ID.CampaignID] == nil {
+			totals[row.ID.CampaignID] = make(map[string]int64)
+		}
+		totals[row.ID.CampaignID][row.ID.Bucket] = -row.Amount // Brand side is credited
+	}
+	return totals, nil
+}
+
+// getSpendReportHandler reports a brand's budget and spend per campaign, converted into the reporting currency
+func getSpendReportHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	if getUserTypeFromClerkUser(user) != "brand" {
+		http.Error(w, "Only brands can view spend reports", http.StatusForbidden)
+		return
+	}
+
+	userID := getUserIDFromClerkUser(user)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	currency, err := reportingCurrency(ctx, r, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cursor, err := database.Collection("campaigns").Find(ctx, bson.M{"brandId": userID})
+	if err != nil {
+		http.Error(w, "Error fetching campaigns", http.StatusInternalServerError)
+		return
+	}
+	var campaigns []Campaign
+	if err = cursor.All(ctx, &campaigns); err != nil {
+		http.Error(w, "Error decoding campaigns", http.StatusInternalServerError)
+		return
+	}
+
+	ledger, err := campaignLedgerTotals(ctx, userID)
+	if err != nil {
+		http.Error(w, "Error calculating spend", http.StatusInternalServerError)
+		return
+	}
+
+	if status, msg := checkReportingRate(ctx, currency); status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	rates := &rateCache{to: currency, rates: make(map[string]ExchangeRate)}
+	rows := []CampaignSpendRow{}
+	unconverted := map[string]string{}
+	var totals ConvertedAmounts
+	for i := range campaigns {
+		campaign := &campaigns[i]
+		budget, _ := campaignBudgetLimit(campaign)
+		amounts := ConvertedAmounts{
+			Budget:     budget,
+			Reserved:   campaign.ReservedBudget,
+			Committed:  ledger[campaign.ID][BucketCommitted],
+			Receivable: ledger[campaign.ID][BucketReceivable],
+			Paid:       ledger[campaign.ID][BucketPaid],
+		}
+
+		from := campaignCurrency(campaign)
+		row := CampaignSpendRow{
+			CampaignID: campaign.ID,
+			Title:      campaign.Title,
+			Currency:   from,
+			Amounts:    amounts,
+		}
+		var converted ConvertedAmounts
+		var rate ExchangeRate
+		for _, pair := range []struct {
+			src int64
+			dst *int64
+		}{
+			{amounts.Budget, &converted.Budget},
+			{amounts.Reserved, &converted.Reserved},
+			{amounts.Committed, &converted.Committed},
+			{amounts.Receivable, &converted.Receivable},
+			{amounts.Paid, &converted.Paid},
+		} {
+			*pair.dst, rate, err = rates.convert(ctx, pair.src, from)
+			if err != nil {
+				break
+			}
+		}
+
+		// Campaigns in a currency without a rate (e.g. a legacy code) are
+		// listed but left out of the totals
+		if err != nil {
+			row.Unconverted = fmt.Sprintf("cannot convert %s to %s: %v", from, currency, err)
+			unconverted[from] = row.Unconverted
+			rows = append(rows, row)
+			continue
+		}
+
+		totals.Budget += converted.Budget
+		totals.Reserved += converted.Reserved
+		totals.Committed += converted.Committed
+		totals.Receivable += converted.Receivable
+		totals.Paid += converted.Paid
+
+		row.Converted = converted
+		row.Rate = rate
+		rows = append(rows, row)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"currency":    currency,
+		"campaigns":   rows,
+		"totals":      totals,
+		"unconverted": unconverted,
+		"rates":       rates.usedRates(),
+		"generatedAt": time.Now(),
+	})
+}
+
+// getEarningsReportHandler reports a creator's balances converted into the reporting currency
+func getEarningsReportHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	userID := getUserIDFromClerkUser(user)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	currency, err := reportingCurrency(ctx, r, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	balances, err := ledgerBalances(ctx, bson.M{"creatorId": userID}, "creator", userID)
+	if err != nil {
+		http.Error(w, "Error calculating balances", http.StatusInternalServerError)
+		return
+	}
+
+	if status, msg := checkReportingRate(ctx, currency); status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	rates := &rateCache{to: currency, rates: make(map[string]ExchangeRate)}
+	var totals LedgerBalance
+	totals.Currency = currency
+	unconverted := map[string]string{}
+	for _, balance := range balances {
+		// Balances in a currency without a rate are listed but left out of the totals
+		committed, _, err := rates.convert(ctx, balance.Committed, balance.Currency)
+		if err != nil {
+			unconverted[balance.Currency] = fmt.Sprintf("cannot convert %s to %s: %v", balance.Currency, currency, err)
+			continue
+		}
+		receivable, _, _ := rates.convert(ctx, balance.Receivable, balance.Currency)
+		paid, _, _ := rates.convert(ctx, balance.Paid, balance.Currency)
+
+		totals.Committed += committed
+		totals.Receivable += receivable
+		totals.Paid += paid
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"currency":    currency,
+		"balances":    balances,
+		"totals":      totals,
+		"unconverted": unconverted,
+		"rates":       rates.usedRates(),
+		"generatedAt": time.Now(),
+	})
+}
+
+// updateReportingCurrencyHandler sets the signed-in user's reporting currency
+func updateReportingCurrencyHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Currency string `json:"currency"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	currency, err := normalizeCurrency(req.Currency)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := database.Collection("users").UpdateOne(ctx,
+		bson.M{"clerkId": getUserIDFromClerkUser(user)},
+		bson.M{"$set": bson.M{"reportingCurrency": currency, "updatedAt": time.Now()}},
+	)
+	if err != nil {
+		http.Error(w, "Error updating user", http.StatusInternalServerError)
+		return
+	}
+	if result.MatchedCount == 0 {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"reportingCurrency": currency})
+}
@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestExchangeRateConvert(t *testing.T) {
+	tests := []struct {
+		rate   ExchangeRate
+		amount int64
+		want   int64
+	}{
+		{ExchangeRate{From: "USD", To: "EUR", Rate: 0.5}, 1000, 500},
+		{ExchangeRate{From: "USD", To: "JPY", Rate: 150}, 1000, 1500},
+		{ExchangeRate{From: "JPY", To: "USD", Rate: 0.0066}, 1500, 990},
+		{ExchangeRate{From: "KWD", To: "USD", Rate: 3.25}, 1000, 325},
+		{ExchangeRate{From: "USD", To: "USD", Rate: 1}, 1999, 1999},
+	}
+	for _, tt := range tests {
+		if got := tt.rate.Convert(tt.amount); got != tt.want {
+			t.Errorf("%s->%s Convert(%d) = %d, want %d", tt.rate.From, tt.rate.To, tt.amount, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeCurrency(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"usd", "USD", false},
+		{" jpy ", "JPY", false},
+		{"US$", "", true},
+		{"dollars", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := normalizeCurrency(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("normalizeCurrency(%q) = %q, %v; want %q, wantErr %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
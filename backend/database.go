@@ -2,10 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
-	"os"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -14,14 +17,14 @@ var client *mongo.Client
 var database *mongo.Database
 
 func initMongoDB() {
-	mongoURI := os.Getenv("MONGODB_URI")
-	dbName := os.Getenv("MONGODB_DATABASE")
+	mongoURI := appConfig.Mongo.URI
+	dbName := appConfig.Mongo.Database
 
 	if mongoURI == "" || dbName == "" {
-		log.Fatal("MONGODB_URI and MONGODB_DATABASE environment variables are required")
+		log.Fatal("mongo.uri (MONGODB_URI) and mongo.database (MONGODB_DATABASE) are required")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), appConfig.Mongo.ConnectTimeout)
 	defer cancel()
 
 	var err error
@@ -47,3 +50,89 @@ func closeMongoDB() {
 		client.Disconnect(ctx)
 	}
 }
+
+// errAbortTransaction is returned by a transaction function to roll back
+// without a database error, e.g. when a request fails validation part way
+var errAbortTransaction = errors.New("transaction aborted")
+
+// withTransaction runs fn in a MongoDB transaction. The driver retries fn on
+// transient errors such as write conflicts, so fn must be safe to run again.
+func withTransaction(ctx context.Context, fn func(sc mongo.SessionContext) error) error {
+	session, err := client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	return err
+}
+
+// migrations are one-off data fixes, each run once per database
+var migrations = []struct {
+	name string
+	run  func(ctx context.Context) error
+}{
+	{"applicant-counts", backfillApplicantCounts},
+}
+
+// runMigrations runs the migrations this database has not seen yet. Each one
+// is claimed by inserting its name into the migrations collection first, so
+// only one replica runs it.
+func runMigrations() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	collection := database.Collection("migrations")
+	for _, migration := range migrations {
+		_, err := collection.InsertOne(ctx, bson.M{"_id": migration.name, "startedAt": time.Now()})
+		if mongo.IsDuplicateKeyError(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := migration.run(ctx); err != nil {
+			// Release the claim so the next start tries again
+			collection.DeleteOne(ctx, bson.M{"_id": migration.name})
+			return fmt.Errorf("migration %s failed: %w", migration.name, err)
+		}
+		if _, err := collection.UpdateOne(ctx, bson.M{"_id": migration.name}, bson.M{"$set": bson.M{"completedAt": time.Now()}}); err != nil {
+			return err
+		}
+		log.Println("Migration", migration.name, "done")
+	}
+	return nil
+}
+
+// backfillApplicantCounts sets each campaign's applicants counter from its
+// applications that are not withdrawn. Campaigns from before the counter
+// was maintained still show 0.
+func backfillApplicantCounts(ctx context.Context) error {
+	cursor, err := database.Collection("applications").Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"status": bson.M{"$ne": ApplicationStatusWithdrawn}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$campaignId", "count": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return err
+	}
+	var counts []struct {
+		CampaignID primitive.ObjectID `bson:"_id"`
+		Count      int                `bson:"count"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return err
+	}
+
+	campaigns := database.Collection("campaigns")
+	for _, count := range counts {
+		_, err := campaigns.UpdateOne(ctx, bson.M{"_id": count.CampaignID}, bson.M{"$set": bson.M{"applicants": count.Count}})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
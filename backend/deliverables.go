@@ -0,0 +1,757 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Deliverable states
+const (
+	DeliverableStatusPending        = "pending"
+	DeliverableStatusSubmitted      = "submitted"
+	DeliverableStatusRevisionNeeded = "revision_needed"
+	DeliverableStatusApproved       = "approved"
+	DeliverableStatusPublished      = "published"
+	DeliverableStatusCancelled      = "cancelled" // The application left "approved" before it was published
+)
+
+// openDeliverableStatuses are the states of deliverables still being worked on
+var openDeliverableStatuses = []string{
+	DeliverableStatusPending, DeliverableStatusSubmitted, DeliverableStatusRevisionNeeded, DeliverableStatusApproved,
+}
+
+// EventDeliverableUpdated is pushed when a deliverable is created or changes
+const EventDeliverableUpdated = "deliverable.updated"
+
+// Deliverable notification types
+const (
+	NotificationDeliverableSubmitted = "deliverable.submitted"
+	NotificationDeliverableReviewed  = "deliverable.reviewed"
+	NotificationDeliverableDue       = "deliverable.due"
+)
+
+// JobDeliverableReminder reminds a creator that a deliverable is due soon
+const JobDeliverableReminder = "deliverable.due_reminder"
+
+// maxDeliverablesPerApplication caps how many deliverables NumberOfPosts can generate
+const maxDeliverablesPerApplication = 50
+
+// deliverableCount parses Campaign.NumberOfPosts, defaulting to one deliverable
+func deliverableCount(campaign *Campaign) int {
+	n, err := strconv.Atoi(strings.TrimSpace(campaign.NumberOfPosts))
+	if err != nil || n < 1 {
+		return 1
+	}
+	if n > maxDeliverablesPerApplication {
+		return maxDeliverablesPerApplication
+	}
+	return n
+}
+
+// deliverableDueDates spreads n due dates evenly between the later of now
+// and the campaign start, and the campaign's EndDate, in its time zone
+func deliverableDueDates(campaign *Campaign, n int, now time.Time) []string {
+	loc := campaignLocation(campaign.TimeZone)
+	today := time.Date(now.In(loc).Year(), now.In(loc).Month(), now.In(loc).Day(), 0, 0, 0, 0, loc)
+
+	end, err := time.ParseInLocation(campaignDateLayout, campaign.EndDate, loc)
+	if err != nil || end.Before(today) {
+		end = today.AddDate(0, 0, 14)
+	}
+	start := today
+	if s, err := time.ParseInLocation(campaignDateLayout, campaign.StartDate, loc); err == nil && s.After(start) && s.Before(end) {
+		start = s
+	}
+
+	days := int(end.Sub(start).Hours() / 24)
+	dates := make([]string, n)
+	for i := 0; i < n; i++ {
+		offset := days * (i + 1) / n
+		dates[i] = start.AddDate(0, 0, offset).Format(campaignDateLayout)
+	}
+	return dates
+}
+
+// generateDeliverables creates the deliverables owed under an approved
+// application from the campaign's ContentFormat and NumberOfPosts. If the
+// application already has deliverables, from an earlier approval, the
+// cancelled ones are reopened instead.
+func generateDeliverables(ctx context.Context, campaign *Campaign, application *Application) ([]Deliverable, error) {
+	collection := database.Collection("deliverables")
+
+	existing, err := collection.CountDocuments(ctx, bson.M{"applicationId": application.ID})
+	if err != nil {
+		return nil, fmt.Errorf("error checking existing deliverables: %w", err)
+	}
+	if existing > 0 {
+		return reopenDeliverables(ctx, campaign, application)
+	}
+
+	approvalSteps := deliverableApprovalSteps(campaign)
+	if approvalSteps == nil {
+		approvalSteps = []string{}
+	}
+
+	formats := campaign.ContentFormat
+	count := deliverableCount(campaign)
+	if terms := application.AgreedTerms; terms != nil {
+		// Negotiated terms override the campaign's defaults
+		if len(terms.ContentFormats) > 0 {
+			formats = terms.ContentFormats
+		}
+		if terms.Deliverables > 0 {
+			count = terms.Deliverables
+		}
+	}
+	if len(formats) == 0 {
+		formats = []string{"post"}
+	}
+
+	now := time.Now()
+	dueDates := deliverableDueDates(campaign, count, now)
+
+	deliverables := make([]Deliverable, count)
+	docs := make([]interface{}, count)
+	for i := 0; i < count; i++ {
+		format := formats[i%len(formats)]
+		deliverables[i] = Deliverable{
+			ID:            primitive.NewObjectID(),
+			ApplicationID: application.ID,
+			CampaignID:    campaign.ID,
+			BrandID:       campaign.BrandID,
+			CreatorID:     application.CreatorID,
+			CampaignName:  campaign.Title,
+			Sequence:      i + 1,
+			Title:         fmt.Sprintf("%s #%d", format, i+1),
+			ContentFormat: format,
+			Platform:      application.Platform,
+			DueDate:       dueDates[i],
+			Status:        DeliverableStatusPending,
+			Submissions:   []DeliverableSubmission{},
+			ApprovalSteps: approvalSteps,
+			Reviews:       []DeliverableReview{},
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		docs[i] = deliverables[i]
+	}
+
+	if _, err := collection.InsertMany(ctx, docs); err != nil {
+		return nil, fmt.Errorf("error creating deliverables: %w", err)
+	}
+
+	for i := range deliverables {
+		scheduleDeliverableReminders(ctx, campaign, &deliverables[i])
+	}
+	return deliverables, nil
+}
+
+// cancelDeliverables cancels an application's unpublished deliverables and
+// their queued reminders when it leaves "approved", returning the cancelled
+// deliverables. Published deliverables have been earned and are kept.
+func cancelDeliverables(ctx context.Context, application *Application) ([]Deliverable, error) {
+	deliverables, err := findDeliverables(ctx, bson.M{
+		"applicationId": application.ID,
+		"status":        bson.M{"$in": openDeliverableStatuses},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching deliverables: %w", err)
+	}
+	if len(deliverables) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	ids := make([]primitive.ObjectID, len(deliverables))
+	hexIDs := make([]string, len(deliverables))
+	for i := range deliverables {
+		ids[i] = deliverables[i].ID
+		hexIDs[i] = deliverables[i].ID.Hex()
+		deliverables[i].Status = DeliverableStatusCancelled
+		deliverables[i].UpdatedAt = now
+	}
+
+	_, err = database.Collection("deliverables").UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$in": ids}},
+		bson.M{"$set": bson.M{"status": DeliverableStatusCancelled, "updatedAt": now}},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error cancelling deliverables: %w", err)
+	}
+
+	if jobQueue != nil {
+		if err := jobQueue.Cancel(ctx, JobDeliverableReminder, "deliverableId", hexIDs); err != nil {
+			return nil, fmt.Errorf("error cancelling deliverable reminders: %w", err)
+		}
+	}
+	return deliverables, nil
+}
+
+// reopenDeliverables moves the deliverables cancelled by cancelDeliverables
+// back to pending when the application is approved again. Each starts over,
+// so the creator resubmits it and it passes every approval step again.
+func reopenDeliverables(ctx context.Context, campaign *Campaign, application *Application) ([]Deliverable, error) {
+	deliverables, err := findDeliverables(ctx, bson.M{"applicationId": application.ID, "status": DeliverableStatusCancelled})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching cancelled deliverables: %w", err)
+	}
+	if len(deliverables) == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	_, err = database.Collection("deliverables").UpdateMany(ctx,
+		bson.M{"applicationId": application.ID, "status": DeliverableStatusCancelled},
+		bson.M{"$set": bson.M{"status": DeliverableStatusPending, "currentStep": 0, "updatedAt": now}},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error reopening deliverables: %w", err)
+	}
+
+	for i := range deliverables {
+		deliverables[i].Status = DeliverableStatusPending
+		deliverables[i].CurrentStep = 0
+		deliverables[i].UpdatedAt = now
+		scheduleDeliverableReminders(ctx, campaign, &deliverables[i])
+	}
+	return deliverables, nil
+}
+
+// checkDeliverableApplication reports whether the deliverable's application
+// is still approved. Deliverables cannot change once it has left that state.
+// On failure it returns the HTTP status and message.
+func checkDeliverableApplication(ctx context.Context, deliverable *Deliverable) (int, string) {
+	var application Application
+	err := database.Collection("applications").FindOne(ctx, bson.M{"_id": deliverable.ApplicationID}).Decode(&application)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return http.StatusNotFound, "Application not found"
+		}
+		return http.StatusInternalServerError, "Error fetching application"
+	}
+	if application.Status != ApplicationStatusApproved {
+		return http.StatusConflict, "The application for this deliverable is no longer approved"
+	}
+	return http.StatusOK, ""
+}
+
+// scheduleDeliverableReminders enqueues due date reminders for a deliverable
+func scheduleDeliverableReminders(ctx context.Context, campaign *Campaign, deliverable *Deliverable) {
+	if jobQueue == nil || !campaign.DeadlineReminders {
+		return
+	}
+
+	due, err := time.ParseInLocation(campaignDateLayout, deliverable.DueDate, campaignLocation(campaign.TimeZone))
+	if err != nil {
+		return
+	}
+
+	for days, runAt := range reminderTimes(due, time.Now()) {
+		key := fmt.Sprintf("%s:%s:%s:%d", JobDeliverableReminder, deliverable.ID.Hex(), deliverable.DueDate, days)
+		err := jobQueue.Enqueue(ctx, JobDeliverableReminder, key, runAt, map[string]interface{}{
+			"deliverableId": deliverable.ID.Hex(),
+			"dueDate":       deliverable.DueDate,
+			"daysBefore":    days,
+		})
+		if err != nil {
+			log.Printf("Error scheduling reminder for deliverable %s: %v", deliverable.ID.Hex(), err)
+		}
+	}
+}
+
+// runDeliverableReminder reminds the creator about a deliverable that is still outstanding
+func runDeliverableReminder(ctx context.Context, job *Job) error {
+	deliverableID, _ := job.Payload["deliverableId"].(string)
+	deliverableOID, err := primitive.ObjectIDFromHex(deliverableID)
+	if err != nil {
+		return nil // Malformed payload, nothing to retry
+	}
+
+	var deliverable Deliverable
+	err = database.Collection("deliverables").FindOne(ctx, bson.M{"_id": deliverableOID}).Decode(&deliverable)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Skip reminders that no longer apply
+	if deliverable.DueDate != job.Payload["dueDate"] {
+		return nil
+	}
+	if deliverable.Status != DeliverableStatusPending && deliverable.Status != DeliverableStatusRevisionNeeded {
+		return nil
+	}
+
+	// Deliver before returning so a failure is retried by the job queue
+	return notificationService.Send(ctx, job.Key, deliverable.CreatorID, NotificationDeliverableDue,
+		fmt.Sprintf("%s is due %s", deliverable.Title, deliverable.DueDate),
+		fmt.Sprintf("Your %s for %s is due on %s.", deliverable.Title, deliverable.CampaignName, deliverable.DueDate),
+		"/creator/dashboard",
+		map[string]interface{}{"deliverableId": deliverable.ID.Hex()},
+	)
+}
+
+// loadDeliverableForParticipant fetches a deliverable and checks that userID
+// is its brand or creator. On failure it returns the HTTP status and message.
+func loadDeliverableForParticipant(ctx context.Context, deliverableID primitive.ObjectID, userID string) (*Deliverable, int, string) {
+	var deliverable Deliverable
+	err := database.Collection("deliverables").FindOne(ctx, bson.M{"_id": deliverableID}).Decode(&deliverable)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, http.StatusNotFound, "Deliverable not found"
+		}
+		return nil, http.StatusInternalServerError, "Error fetching deliverable"
+	}
+
+	if deliverable.BrandID != userID && deliverable.CreatorID != userID {
+		return nil, http.StatusForbidden, "Access denied"
+	}
+
+	return &deliverable, http.StatusOK, ""
+}
+
+// findDeliverables runs a deliverables query sorted by due date
+func findDeliverables(ctx context.Context, filter bson.M) ([]Deliverable, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "dueDate", Value: 1}, {Key: "sequence", Value: 1}})
+	cursor, err := database.Collection("deliverables").Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	deliverables := []Deliverable{}
+	if err = cursor.All(ctx, &deliverables); err != nil {
+		return nil, err
+	}
+	return deliverables, nil
+}
+
+// getDeliverablesHandler lists the signed-in user's deliverables (brand: owed to them, creator: owed by them)
+func getDeliverablesHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	userID := getUserIDFromClerkUser(user)
+	filter := bson.M{"creatorId": userID}
+	if getUserTypeFromClerkUser(user) == "brand" {
+		filter = bson.M{"brandId": userID}
+	}
+
+	if campaignId := r.URL.Query().Get("campaignId"); campaignId != "" {
+		campaignOID, err := primitive.ObjectIDFromHex(campaignId)
+		if err != nil {
+			http.Error(w, "Invalid campaign ID", http.StatusBadRequest)
+			return
+		}
+		filter["campaignId"] = campaignOID
+	}
+	if status := r.URL.Query().Get("status"); status != "" {
+		filter["status"] = status
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	deliverables, err := findDeliverables(ctx, filter)
+	if err != nil {
+		http.Error(w, "Error fetching deliverables", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliverables)
+}
+
+// getApplicationDeliverablesHandler lists the deliverables of one application
+func getApplicationDeliverablesHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	applicationId := vars["applicationId"]
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	// Convert application ID to ObjectID
+	appObjID, err := primitive.ObjectIDFromHex(applicationId)
+	if err != nil {
+		http.Error(w, "Invalid application ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, _, status, msg := loadApplicationForParticipant(ctx, appObjID, getUserIDFromClerkUser(user)); status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	deliverables, err := findDeliverables(ctx, bson.M{"applicationId": appObjID})
+	if err != nil {
+		http.Error(w, "Error fetching deliverables", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliverables)
+}
+
+// submitDeliverableHandler records a creator's submission (post URL and/or draft) for a deliverable
+func submitDeliverableHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	deliverableId := vars["deliverableId"]
+
+	var req struct {
+		PostURL    string `json:"postUrl"`
+		DraftURL   string `json:"draftUrl"`
+		Caption    string `json:"caption"`
+		Transcript string `json:"transcript"`
+		Notes      string `json:"notes"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if strings.TrimSpace(req.PostURL) == "" && strings.TrimSpace(req.DraftURL) == "" {
+		http.Error(w, "A post URL or draft URL is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	deliverableOID, err := primitive.ObjectIDFromHex(deliverableId)
+	if err != nil {
+		http.Error(w, "Invalid deliverable ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID := getUserIDFromClerkUser(user)
+	deliverable, status, msg := loadDeliverableForParticipant(ctx, deliverableOID, userID)
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	if deliverable.CreatorID != userID {
+		http.Error(w, "Only the creator can submit a deliverable", http.StatusForbidden)
+		return
+	}
+	if status, msg := checkDeliverableApplication(ctx, deliverable); status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	switch deliverable.Status {
+	case DeliverableStatusPending, DeliverableStatusSubmitted, DeliverableStatusRevisionNeeded:
+	default:
+		http.Error(w, fmt.Sprintf("Cannot submit a deliverable that is %s", deliverable.Status), http.StatusConflict)
+		return
+	}
+
+	now := time.Now()
+	submission := DeliverableSubmission{
+		Version:     deliverable.Version + 1,
+		PostURL:     strings.TrimSpace(req.PostURL),
+		DraftURL:    strings.TrimSpace(req.DraftURL),
+		Caption:     req.Caption,
+		Transcript:  req.Transcript,
+		Notes:       req.Notes,
+		SubmittedAt: now,
+	}
+
+	// Check the submitted text against the campaign's content rules
+	var campaign Campaign
+	if err := database.Collection("campaigns").FindOne(ctx, bson.M{"_id": deliverable.CampaignID}).Decode(&campaign); err != nil {
+		http.Error(w, "Error fetching campaign", http.StatusInternalServerError)
+		return
+	}
+	report := checkContentCompliance(&campaign, submission.Caption+"\n"+submission.Transcript, submission.Version)
+
+	var updated Deliverable
+	err = database.Collection("deliverables").FindOneAndUpdate(ctx,
+		bson.M{"_id": deliverableOID, "status": deliverable.Status},
+		bson.M{
+			"$set": bson.M{
+				"status":      DeliverableStatusSubmitted,
+				"postUrl":     submission.PostURL,
+				"draftUrl":    submission.DraftURL,
+				"caption":     submission.Caption,
+				"submittedAt": now,
+				"updatedAt":   now,
+				"version":     submission.Version,
+				"currentStep": 0, // Each new version goes through every approval step again
+				"compliance":  report,
+			},
+			"$push": bson.M{"submissions": submission},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			http.Error(w, "Deliverable changed concurrently, please retry", http.StatusConflict)
+		} else {
+			http.Error(w, "Error updating deliverable", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	publishEvent(EventDeliverableUpdated, updated, updated.BrandID, updated.CreatorID)
+	notificationService.Notify(updated.BrandID, NotificationDeliverableSubmitted,
+		updated.Title+" submitted for "+updated.CampaignName,
+		fmt.Sprintf("A creator submitted %s for %s and it is ready for review.", updated.Title, updated.CampaignName),
+		"/brand/campaigns",
+		map[string]interface{}{"deliverableId": updated.ID.Hex()},
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// updateDeliverableStatusHandler moves a deliverable forward. The brand
+// reviews submissions (approved / revision_needed); either side may mark
+// an approved deliverable as published once it has a post URL.
+func updateDeliverableStatusHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	deliverableId := vars["deliverableId"]
+
+	var req struct {
+		Status   string `json:"status"`
+		Feedback string `json:"feedback"`
+		PostURL  string `json:"postUrl"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	deliverableOID, err := primitive.ObjectIDFromHex(deliverableId)
+	if err != nil {
+		http.Error(w, "Invalid deliverable ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID := getUserIDFromClerkUser(user)
+	deliverable, status, msg := loadDeliverableForParticipant(ctx, deliverableOID, userID)
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+	if status, msg := checkDeliverableApplication(ctx, deliverable); status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	now := time.Now()
+	set := bson.M{"status": req.Status, "updatedAt": now}
+
+	switch req.Status {
+	case DeliverableStatusApproved, DeliverableStatusRevisionNeeded:
+		if deliverable.BrandID != userID {
+			http.Error(w, "Only the brand can review a deliverable", http.StatusForbidden)
+			return
+		}
+		if hasApprovalWorkflow(deliverable) {
+			http.Error(w, "This deliverable goes through approval steps; use the reviews endpoint", http.StatusConflict)
+			return
+		}
+		if deliverable.Status != DeliverableStatusSubmitted {
+			http.Error(w, "Only submitted deliverables can be reviewed", http.StatusConflict)
+			return
+		}
+		set["feedback"] = req.Feedback
+	case DeliverableStatusPublished:
+		if deliverable.Status != DeliverableStatusApproved {
+			http.Error(w, "Only approved deliverables can be published", http.StatusConflict)
+			return
+		}
+		if hasApprovalWorkflow(deliverable) && !allApprovalStepsPassed(deliverable) {
+			http.Error(w, "All approval steps must pass before the deliverable is published", http.StatusConflict)
+			return
+		}
+		postURL := strings.TrimSpace(req.PostURL)
+		if postURL == "" {
+			postURL = deliverable.PostURL
+		}
+		if postURL == "" {
+			http.Error(w, "A post URL is required to publish a deliverable", http.StatusBadRequest)
+			return
+		}
+		set["postUrl"] = postURL
+		set["publishedAt"] = now
+	default:
+		http.Error(w, "Invalid status. Must be 'approved', 'revision_needed', or 'published'", http.StatusBadRequest)
+		return
+	}
+
+	// Publishing earns the deliverable's share of the agreed fee, booked
+	// together with the status change
+	var updated Deliverable
+	err = withTransaction(ctx, func(sc mongo.SessionContext) error {
+		err := database.Collection("deliverables").FindOneAndUpdate(sc,
+			bson.M{"_id": deliverableOID, "status": deliverable.Status},
+			bson.M{"$set": set},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&updated)
+		if err != nil {
+			return err
+		}
+		if req.Status == DeliverableStatusPublished {
+			return recordMilestone(sc, &updated)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			http.Error(w, "Deliverable changed concurrently, please retry", http.StatusConflict)
+		} else {
+			log.Printf("Error updating deliverable %s: %v", deliverable.ID.Hex(), err)
+			http.Error(w, "Error updating deliverable", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	publishEvent(EventDeliverableUpdated, updated, updated.BrandID, updated.CreatorID)
+	if req.Status != DeliverableStatusPublished {
+		notificationService.Notify(updated.CreatorID, NotificationDeliverableReviewed,
+			fmt.Sprintf("%s: %s", updated.Title, strings.ReplaceAll(updated.Status, "_", " ")),
+			fmt.Sprintf("The brand reviewed %s for %s. %s", updated.Title, updated.CampaignName, updated.Feedback),
+			"/creator/dashboard",
+			map[string]interface{}{"deliverableId": updated.ID.Hex(), "status": updated.Status},
+		)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// updateDeliverableHandler lets the brand adjust a deliverable's title or due date
+func updateDeliverableHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	deliverableId := vars["deliverableId"]
+
+	var req struct {
+		Title   string `json:"title"`
+		DueDate string `json:"dueDate"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	deliverableOID, err := primitive.ObjectIDFromHex(deliverableId)
+	if err != nil {
+		http.Error(w, "Invalid deliverable ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID := getUserIDFromClerkUser(user)
+	deliverable, status, msg := loadDeliverableForParticipant(ctx, deliverableOID, userID)
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	if deliverable.BrandID != userID {
+		http.Error(w, "Only the brand can edit a deliverable", http.StatusForbidden)
+		return
+	}
+	if status, msg := checkDeliverableApplication(ctx, deliverable); status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	set := bson.M{"updatedAt": time.Now()}
+	if title := strings.TrimSpace(req.Title); title != "" {
+		set["title"] = title
+	}
+	if req.DueDate != "" {
+		if _, err := time.Parse(campaignDateLayout, req.DueDate); err != nil {
+			http.Error(w, "Due date must be in YYYY-MM-DD format", http.StatusBadRequest)
+			return
+		}
+		set["dueDate"] = req.DueDate
+	}
+
+	// Guard on the status so a deliverable cancelled in the meantime is left alone
+	var updated Deliverable
+	err = database.Collection("deliverables").FindOneAndUpdate(ctx,
+		bson.M{"_id": deliverableOID, "status": deliverable.Status},
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			http.Error(w, "Deliverable changed concurrently, please retry", http.StatusConflict)
+		} else {
+			http.Error(w, "Error updating deliverable", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Re-schedule reminders for a new due date
+	if updated.DueDate != deliverable.DueDate {
+		var campaign Campaign
+		if err := database.Collection("campaigns").FindOne(ctx, bson.M{"_id": updated.CampaignID}).Decode(&campaign); err == nil {
+			scheduleDeliverableReminders(ctx, &campaign, &updated)
+		}
+	}
+
+	publishEvent(EventDeliverableUpdated, updated, updated.BrandID, updated.CreatorID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Event types pushed to dashboards
+const (
+	EventApplicationCreated       = "application.created"
+	EventApplicationStatusChanged = "application.status_changed"
+	EventApplicationUpdated       = "application.updated"
+	EventCampaignUpdated          = "campaign.updated"
+	EventMessageCreated           = "message.created"
+)
+
+// Event is a single notification delivered over the event stream
+type Event struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	Data       interface{} `json:"data"`
+	Recipients []string    `json:"-"` // Clerk IDs of the users who should receive the event
+	CreatedAt  time.Time   `json:"createdAt"`
+}
+
+// EventBus fans events out to in-process subscribers keyed by Clerk user ID
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan Event]struct{}
+	closing     chan struct{}
+	closeOnce   sync.Once
+}
+
+// eventBus is the process-wide bus handlers publish to
+var eventBus = NewEventBus()
+
+// eventSubscriberBuffer is how many events a slow subscriber may fall behind before events are dropped
+const eventSubscriberBuffer = 32
+
+// NewEventBus creates an empty event bus
+func NewEventBus() *EventBus {
+	return &EventBus{
+		subscribers: make(map[string]map[chan Event]struct{}),
+		closing:     make(chan struct{}),
+	}
+}
+
+// Close tells subscribers the server is shutting down so their streams end
+// instead of holding the shutdown open
+func (b *EventBus) Close() {
+	b.closeOnce.Do(func() { close(b.closing) })
+}
+
+// Closing is closed once Close has been called
+func (b *EventBus) Closing() <-chan struct{} {
+	return b.closing
+}
+
+// Subscribe registers a listener for events addressed to userID. The
+// returned function must be called to release the subscription.
+func (b *EventBus) Subscribe(userID string) (<-chan Event, func()) {
+	ch := make(chan Event, eventSubscriberBuffer)
+
+	b.mu.Lock()
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[chan Event]struct{})
+	}
+	b.subscribers[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers[userID], ch)
+			if len(b.subscribers[userID]) == 0 {
+				delete(b.subscribers, userID)
+			}
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish delivers event to every subscriber of its recipients without blocking
+func (b *EventBus) Publish(event Event) {
+	if event.ID == "" {
+		event.ID = primitive.NewObjectID().Hex()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	for _, userID := range event.Recipients {
+		if userID == "" || seen[userID] {
+			continue
+		}
+		seen[userID] = true
+
+		for ch := range b.subscribers[userID] {
+			select {
+			case ch <- event:
+			default:
+				log.Printf("Event bus: dropping %s event for slow subscriber %s", event.Type, userID)
+			}
+		}
+	}
+}
+
+// publishEvent is a shorthand for publishing on the process-wide bus
+func publishEvent(eventType string, data interface{}, recipients ...string) {
+	eventBus.Publish(Event{Type: eventType, Data: data, Recipients: recipients})
+}
+
+// campaignAudience returns the brand and every creator who applied to the campaign
+func campaignAudience(ctx context.Context, campaign *Campaign) []string {
+	recipients := []string{campaign.BrandID}
+
+	creatorIDs, err := database.Collection("applications").Distinct(ctx, "creatorId", bson.M{"campaignId": campaign.ID})
+	if err != nil {
+		log.Println("Error fetching campaign applicants:", err)
+		return recipients
+	}
+	for _, id := range creatorIDs {
+		if creatorID, ok := id.(string); ok {
+			recipients = append(recipients, creatorID)
+		}
+	}
+	return recipients
+}
+
+// publishCampaignUpdated notifies the brand and applicants that a campaign changed
+func publishCampaignUpdated(ctx context.Context, campaign *Campaign) {
+	publishEvent(EventCampaignUpdated, campaign, campaignAudience(ctx, campaign)...)
+}
+
+// eventTokenQuery lets browser EventSource clients, which cannot set
+// headers, pass their Clerk token as ?token= instead of Authorization.
+func eventTokenQuery(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			if token := r.URL.Query().Get("token"); token != "" {
+				r.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		next(w, r)
+	}
+}
+
+// eventsHandler streams events for the authenticated user as Server-Sent Events
+func eventsHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	events, unsubscribe := eventBus.Subscribe(getUserIDFromClerkUser(user))
+	defer unsubscribe()
+
+	// The stream stays open far longer than server.writeTimeout
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable nginx buffering
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 5000\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(25 * time.Second)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-eventBus.Closing():
+			// The client reconnects after the retry interval, to this or another instance
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			payload, err := json.Marshal(event)
+			if err != nil {
+				log.Println("Error encoding event:", err)
+				continue
+			}
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, payload)
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,575 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// exportFlushEvery is how many rows are written between flushes to the client
+const exportFlushEvery = 100
+
+// exportField is one flattened column value
+type exportField struct {
+	Key   string
+	Value interface{} // string, bool, int64, float64 or nil
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	objectIDType = reflect.TypeOf(primitive.ObjectID{})
+)
+
+// flattenFields flattens a struct into dotted JSON keys, e.g. nested
+// TargetAudience.Location becomes "targetAudience.location". String slices
+// are joined with "; "; other slices are JSON encoded. Nil pointers produce
+// empty columns so every row has the same columns.
+func flattenFields(v reflect.Value, t reflect.Type, prefix string, out []exportField) []exportField {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || field.Tag.Get("bson") == "-" {
+			continue // Computed fields are not stored
+		}
+		if name == "" {
+			name = field.Name
+		}
+		key := prefix + name
+
+		var fv reflect.Value
+		if v.IsValid() {
+			fv = v.Field(i)
+		}
+		out = flattenValue(fv, field.Type, key, out)
+	}
+	return out
+}
+
+// flattenValue appends the columns for one value of type t
+func flattenValue(v reflect.Value, t reflect.Type, key string, out []exportField) []exportField {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		if v.IsValid() {
+			if v.IsNil() {
+				v = reflect.Value{}
+			} else {
+				v = v.Elem()
+			}
+		}
+	}
+
+	switch {
+	case t == timeType:
+		if !v.IsValid() || v.Interface().(time.Time).IsZero() {
+			return append(out, exportField{key, nil})
+		}
+		return append(out, exportField{key, v.Interface().(time.Time).UTC().Format(time.RFC3339)})
+	case t == objectIDType:
+		if !v.IsValid() || v.Interface().(primitive.ObjectID).IsZero() {
+			return append(out, exportField{key, nil})
+		}
+		return append(out, exportField{key, v.Interface().(primitive.ObjectID).Hex()})
+	case t.Kind() == reflect.Struct:
+		return flattenFields(v, t, key+".", out)
+	}
+
+	if !v.IsValid() {
+		return append(out, exportField{key, nil})
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return append(out, exportField{key, v.String()})
+	case reflect.Bool:
+		return append(out, exportField{key, v.Bool()})
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return append(out, exportField{key, v.Int()})
+	case reflect.Float32, reflect.Float64:
+		return append(out, exportField{key, v.Float()})
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String {
+			return append(out, exportField{key, strings.Join(v.Interface().([]string), "; ")})
+		}
+		if v.Len() == 0 {
+			return append(out, exportField{key, nil})
+		}
+		encoded, _ := json.Marshal(v.Interface())
+		return append(out, exportField{key, string(encoded)})
+	}
+
+	encoded, _ := json.Marshal(v.Interface())
+	return append(out, exportField{key, string(encoded)})
+}
+
+// exportColumns lists every column a type can be exported with
+func exportColumns(t reflect.Type) []string {
+	var columns []string
+	for _, field := range flattenFields(reflect.Value{}, t, "", nil) {
+		columns = append(columns, field.Key)
+	}
+	return columns
+}
+
+// selectExportColumns resolves ?columns=a,b,c against the available columns.
+// A trailing ".*" selects a nested group, e.g. "targetAudience.*".
+func selectExportColumns(available []string, requested string) ([]string, error) {
+	if strings.TrimSpace(requested) == "" {
+		return available, nil
+	}
+
+	known := make(map[string]bool, len(available))
+	for _, column := range available {
+		known[column] = true
+	}
+
+	var selected []string
+	seen := make(map[string]bool)
+	add := func(column string) {
+		if !seen[column] {
+			seen[column] = true
+			selected = append(selected, column)
+		}
+	}
+	for _, column := range strings.Split(requested, ",") {
+		column = strings.TrimSpace(column)
+		if column == "" {
+			continue
+		}
+		if prefix, ok := strings.CutSuffix(column, ".*"); ok {
+			matched := false
+			for _, candidate := range available {
+				if strings.HasPrefix(candidate, prefix+".") {
+					add(candidate)
+					matched = true
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unknown column group %q", column)
+			}
+			continue
+		}
+		if !known[column] {
+			return nil, fmt.Errorf("unknown column %q", column)
+		}
+		add(column)
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no columns selected")
+	}
+	return selected, nil
+}
+
+// exportValueString renders a value for CSV
+func exportValueString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+// spreadsheetSafe prefixes text that a spreadsheet would read as a formula
+// with a single quote, so exported user input cannot run as a formula
+func spreadsheetSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// spreadsheetUnsafe undoes spreadsheetSafe so an edited export imports unchanged
+func spreadsheetUnsafe(s string) string {
+	if len(s) > 1 && s[0] == '\'' && strings.ContainsRune("=+-@\t\r", rune(s[1])) {
+		return s[1:]
+	}
+	return s
+}
+
+// RowWriter writes export rows in one format
+type RowWriter interface {
+	WriteHeader(columns []string) error
+	WriteRow(values []interface{}) error
+	Close() error
+}
+
+// csvRowWriter writes comma-separated values
+type csvRowWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvRowWriter) WriteHeader(columns []string) error {
+	return c.w.Write(columns)
+}
+
+func (c *csvRowWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = exportValueString(value)
+		if _, ok := value.(string); ok {
+			record[i] = spreadsheetSafe(record[i])
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvRowWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonlRowWriter writes one JSON object per line with keys in column order
+type jsonlRowWriter struct {
+	w       io.Writer
+	columns []string
+}
+
+func (j *jsonlRowWriter) WriteHeader(columns []string) error {
+	j.columns = columns
+	return nil
+}
+
+func (j *jsonlRowWriter) WriteRow(values []interface{}) error {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(j.columns[i])
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(encoded)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(j.w, b.String())
+	return err
+}
+
+func (j *jsonlRowWriter) Close() error {
+	return nil
+}
+
+// xlsxRowWriter streams a single-sheet XLSX workbook. The package parts are
+// written up front and the sheet XML is streamed row by row into the zip.
+type xlsxRowWriter struct {
+	zip   *zip.Writer
+	sheet io.Writer
+	row   int
+}
+
+// xlsxStaticParts are the workbook parts that do not depend on the data
+var xlsxStaticParts = []struct{ name, body string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Export" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+func newXLSXRowWriter(w io.Writer) (*xlsxRowWriter, error) {
+	archive := zip.NewWriter(w)
+	for _, part := range xlsxStaticParts {
+		f, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.WriteString(sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+"\n"+
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err != nil {
+		return nil, err
+	}
+	return &xlsxRowWriter{zip: archive, sheet: sheet}, nil
+}
+
+func (x *xlsxRowWriter) WriteHeader(columns []string) error {
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		values[i] = column
+	}
+	return x.WriteRow(values)
+}
+
+func (x *xlsxRowWriter) WriteRow(values []interface{}) error {
+	x.row++
+	var b strings.Builder
+	fmt.Fprintf(&b, `<row r="%d">`, x.row)
+	for i, value := range values {
+		ref := xlsxColumnName(i) + strconv.Itoa(x.row)
+		switch v := value.(type) {
+		case nil:
+			continue
+		case bool:
+			n := 0
+			if v {
+				n = 1
+			}
+			fmt.Fprintf(&b, `<c r="%s" t="b"><v>%d</v></c>`, ref, n)
+		case int64, float64:
+			fmt.Fprintf(&b, `<c r="%s"><v>%s</v></c>`, ref, exportValueString(v))
+		default:
+			fmt.Fprintf(&b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			xml.EscapeText(&b, []byte(xlsxSafeText(spreadsheetSafe(exportValueString(v)))))
+			b.WriteString(`</t></is></c>`)
+		}
+	}
+	b.WriteString("</row>")
+	_, err := io.WriteString(x.sheet, b.String())
+	return err
+}
+
+func (x *xlsxRowWriter) Close() error {
+	if _, err := io.WriteString(x.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
+
+// xlsxColumnName converts a zero-based index to a column name (0 -> A, 26 -> AA)
+func xlsxColumnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// xlsxSafeText drops control characters XML 1.0 cannot carry
+func xlsxSafeText(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// newRowWriter sets the response headers for format and returns its writer
+func newRowWriter(w http.ResponseWriter, format, filename string) (RowWriter, error) {
+	switch format {
+	case "", "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+		return &csvRowWriter{w: csv.NewWriter(w)}, nil
+	case "jsonl":
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.jsonl"`, filename))
+		return &jsonlRowWriter{w: w}, nil
+	case "xlsx":
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.xlsx"`, filename))
+		return newXLSXRowWriter(w)
+	}
+	return nil, fmt.Errorf("unknown format %q: use csv, xlsx or jsonl", format)
+}
+
+// streamExport writes every document from cursor as a row. Documents are
+// decoded one at a time into a fresh value of itemType, so memory use does
+// not grow with the result size.
+func streamExport(ctx context.Context, w http.ResponseWriter, r *http.Request, cursor *mongo.Cursor, itemType reflect.Type, filename string, prepare func(item interface{})) {
+	defer cursor.Close(ctx)
+
+	query := r.URL.Query()
+	columns, err := selectExportColumns(exportColumns(itemType), query.Get("columns"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if format := query.Get("format"); format != "" && format != "csv" && format != "xlsx" && format != "jsonl" {
+		http.Error(w, fmt.Sprintf("unknown format %q: use csv, xlsx or jsonl", format), http.StatusBadRequest)
+		return
+	}
+
+	index := make(map[string]int, len(columns))
+	for i, column := range columns {
+		index[column] = i
+	}
+
+	writer, err := newRowWriter(w, query.Get("format"), filename)
+	if err != nil {
+		http.Error(w, "Error starting export", http.StatusInternalServerError)
+		return
+	}
+	flusher, _ := w.(http.Flusher)
+
+	// Large exports can stream for longer than server.writeTimeout
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	// Once the header is written errors can only be logged; the client sees a truncated file
+	if err := writer.WriteHeader(columns); err != nil {
+		log.Printf("Error writing header for export %s: %v", filename, err)
+		return
+	}
+
+	rows := 0
+	values := make([]interface{}, len(columns))
+	for cursor.Next(ctx) {
+		item := reflect.New(itemType)
+		if err := cursor.Decode(item.Interface()); err != nil {
+			log.Printf("Error decoding row for export %s: %v", filename, err)
+			return
+		}
+		if prepare != nil {
+			prepare(item.Interface())
+		}
+
+		for i := range values {
+			values[i] = nil
+		}
+		for _, field := range flattenFields(item.Elem(), itemType, "", nil) {
+			if i, ok := index[field.Key]; ok {
+				values[i] = field.Value
+			}
+		}
+		if err := writer.WriteRow(values); err != nil {
+			log.Printf("Error writing row for export %s: %v", filename, err)
+			return
+		}
+
+		rows++
+		if rows%exportFlushEvery == 0 && flusher != nil {
+			if c, ok := writer.(*csvRowWriter); ok {
+				c.w.Flush()
+			}
+			flusher.Flush()
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		log.Printf("Error reading rows for export %s: %v", filename, err)
+	}
+	if err := writer.Close(); err != nil {
+		log.Printf("Error finishing export %s: %v", filename, err)
+	}
+}
+
+// exportCampaignsHandler streams the signed-in brand's campaigns
+func exportCampaignsHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	if getUserTypeFromClerkUser(user) != "brand" {
+		http.Error(w, "Only brands can export campaigns", http.StatusForbidden)
+		return
+	}
+
+	filter := bson.M{"brandId": getUserIDFromClerkUser(user)}
+	if status := r.URL.Query().Get("status"); status != "" {
+		filter["status"] = status
+	}
+
+	// Exports can be large; allow longer than a normal request
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetBatchSize(exportFlushEvery)
+	cursor, err := database.Collection("campaigns").Find(ctx, filter, opts)
+	if err != nil {
+		http.Error(w, "Error fetching campaigns", http.StatusInternalServerError)
+		return
+	}
+
+	filename := "campaigns-" + time.Now().Format("20060102")
+	streamExport(ctx, w, r, cursor, reflect.TypeOf(Campaign{}), filename, nil)
+}
+
+// exportCampaignApplicationsHandler streams the applications of one of the brand's campaigns
+func exportCampaignApplicationsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	campaignId := vars["campaignId"]
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	// Convert campaign ID to ObjectID
+	campaignOID, err := primitive.ObjectIDFromHex(campaignId)
+	if err != nil {
+		http.Error(w, "Invalid campaign ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	var campaign Campaign
+	err = database.Collection("campaigns").FindOne(ctx, bson.M{"_id": campaignOID}).Decode(&campaign)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			http.Error(w, "Campaign not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error fetching campaign", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if campaign.BrandID != getUserIDFromClerkUser(user) {
+		http.Error(w, "Access denied: You can only export your own campaigns", http.StatusForbidden)
+		return
+	}
+
+	filter := bson.M{"campaignId": campaignOID}
+	if status := r.URL.Query().Get("status"); status != "" {
+		filter["status"] = status
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "appliedDate", Value: 1}}).SetBatchSize(exportFlushEvery)
+	cursor, err := database.Collection("applications").Find(ctx, filter, opts)
+	if err != nil {
+		http.Error(w, "Error fetching applications", http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("applications-%s-%s", campaignOID.Hex(), time.Now().Format("20060102"))
+	streamExport(ctx, w, r, cursor, reflect.TypeOf(Application{}), filename, func(item interface{}) {
+		item.(*Application).CampaignName = campaign.Title
+	})
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestSpreadsheetSafe(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"Summer launch", "Summer launch"},
+		{"=HYPERLINK(\"http://evil\")", "'=HYPERLINK(\"http://evil\")"},
+		{"+1 555 0100", "'+1 555 0100"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"a=1", "a=1"},
+		{"'quoted", "'quoted"},
+	}
+	for _, tt := range tests {
+		got := spreadsheetSafe(tt.in)
+		if got != tt.want {
+			t.Errorf("spreadsheetSafe(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if back := spreadsheetUnsafe(got); back != tt.in {
+			t.Errorf("spreadsheetUnsafe(%q) = %q, want %q", got, back, tt.in)
+		}
+	}
+}
+
+func TestCSVRowWriterEscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	writer := &csvRowWriter{w: csv.NewWriter(&buf)}
+	if err := writer.WriteRow([]interface{}{"=cmd|' /C calc'!A0", int64(-5), -1.5, true, nil}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Numbers keep their sign; only text is escaped
+	if got, want := strings.TrimSpace(buf.String()), `'=cmd|' /C calc'!A0,-5,-1.5,true,`; got != want {
+		t.Errorf("row = %q, want %q", got, want)
+	}
+}
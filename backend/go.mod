@@ -3,11 +3,13 @@ module github.com/Dipu-36/startup
 go 1.24
 
 require (
+	github.com/BurntSushi/toml v1.5.0
 	github.com/clerk/clerk-sdk-go/v2 v2.3.1
 	github.com/gorilla/mux v1.8.1
 	github.com/joho/godotenv v1.5.1
 	github.com/rs/cors v1.11.1
 	go.mongodb.org/mongo-driver v1.17.4
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
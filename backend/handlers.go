@@ -3,13 +3,18 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // Authentication is handled by Clerk, so no signup/login handlers needed
@@ -143,40 +148,13 @@ func createProfileHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "created"})
 }
 
-func createCampaignHandler(w http.ResponseWriter, r *http.Request) {
-	var req CampaignRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
-
-	// Get user from context
-	user, ok := getUserFromContext(r.Context())
-	if !ok {
-		http.Error(w, "User not found in context", http.StatusInternalServerError)
-		return
-	}
-
-	// Get user's Clerk ID
-	userID := getUserIDFromClerkUser(user)
-
-	// Get user details for brand name
-	userCollection := database.Collection("users")
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	var dbUser User
-	err := userCollection.FindOne(ctx, bson.M{"clerkId": userID}).Decode(&dbUser)
-	if err != nil {
-		http.Error(w, "User not found", http.StatusNotFound)
-		return
-	}
-
-	// Create campaign
-	campaign := Campaign{
+// newCampaignFromRequest builds a draft campaign for the brand from a create request
+func newCampaignFromRequest(req *CampaignRequest, brandID, brandName string) Campaign {
+	now := time.Now()
+	return Campaign{
 		ID:           primitive.NewObjectID(),
-		BrandID:      userID,
-		BrandName:    dbUser.Name,
+		BrandID:      brandID,
+		BrandName:    brandName,
 		Title:        req.Title,
 		Description:  req.Description,
 		Category:     req.Category,
@@ -227,6 +205,7 @@ func createCampaignHandler(w http.ResponseWriter, r *http.Request) {
 		PerformanceBonus:     req.PerformanceBonus,
 		BonusCriteria:        req.BonusCriteria,
 		ProductDetails:       req.ProductDetails,
+		UsageRights:          req.UsageRights,
 
 		ApprovalSteps:        req.ApprovalSteps,
 		DeadlineReminders:    req.DeadlineReminders,
@@ -237,9 +216,65 @@ func createCampaignHandler(w http.ResponseWriter, r *http.Request) {
 		ReferenceLinks: req.ReferenceLinks,
 		ReferenceMedia: req.ReferenceMedia,
 
-		Status:    "active",
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		Status:    CampaignStatusDraft,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func createCampaignHandler(w http.ResponseWriter, r *http.Request) {
+	var req CampaignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Validate the request as the importer does, defaulting the currency to USD
+	if errs := validateCampaignRequest(&req); len(errs) > 0 {
+		http.Error(w, strings.Join(errs, "; "), http.StatusBadRequest)
+		return
+	}
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	// Get user's Clerk ID
+	userID := getUserIDFromClerkUser(user)
+
+	// Get user details for brand name
+	userCollection := database.Collection("users")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var dbUser User
+	err := userCollection.FindOne(ctx, bson.M{"clerkId": userID}).Decode(&dbUser)
+	if err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	// Create campaign
+	campaign := newCampaignFromRequest(&req, userID, dbUser.Name)
+
+	// Campaigns start as drafts and move to the requested initial state,
+	// which defaults to scheduled until the start date and active after it
+	status := req.Status
+	if status == "" {
+		status = CampaignStatusActive
+		if start, err := campaignStartTime(&campaign); err == nil && time.Now().Before(start) {
+			status = CampaignStatusScheduled
+		}
+	}
+	if status != CampaignStatusDraft {
+		if err := validateCampaignTransition(&campaign, status, time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		campaign.Status = status
 	}
 
 	// Insert campaign into database
@@ -250,6 +285,8 @@ func createCampaignHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	scheduleCampaignReminders(ctx, &campaign)
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(campaign)
@@ -303,6 +340,34 @@ func updateCampaignHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Status changes must follow the campaign lifecycle
+	if req.Status == "" {
+		req.Status = existingCampaign.Status
+	}
+	if req.Status != existingCampaign.Status {
+		candidate := existingCampaign
+		candidate.StartDate = req.StartDate
+		candidate.EndDate = req.EndDate
+		if err := validateCampaignTransition(&candidate, req.Status, time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+	}
+
+	// A changed currency must be ISO 4217, and cannot change once budget is reserved in the old one
+	currency := existingCampaign.Currency
+	if req.Currency != "" && req.Currency != existingCampaign.Currency {
+		currency, err = normalizeCurrency(req.Currency)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if currency != campaignCurrency(&existingCampaign) && existingCampaign.ReservedBudget != 0 {
+			http.Error(w, "Currency cannot change after creators have been approved", http.StatusConflict)
+			return
+		}
+	}
+
 	// Update campaign with new data
 	update := bson.M{
 		"$set": bson.M{
@@ -318,12 +383,14 @@ func updateCampaignHandler(w http.ResponseWriter, r *http.Request) {
 			"minRequirements":        req.MinRequirements,
 			"compensationType":       req.CompensationType,
 			"paymentAmount":          req.PaymentAmount,
+			"currency":               currency,
 			"targetAudience":         req.TargetAudience,
 			"numberOfPosts":          req.NumberOfPosts,
 			"contentGuidelines":      req.ContentGuidelines,
 			"approvalRequired":       req.ApprovalRequired,
 			"geographicRestrictions": req.GeographicRestrictions,
 			"nicheMatch":             req.NicheMatch,
+			"usageRights":            req.UsageRights,
 			"updatedAt":              time.Now(),
 		},
 	}
@@ -347,6 +414,9 @@ func updateCampaignHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	publishCampaignUpdated(context.TODO(), &updatedCampaign)
+	scheduleCampaignReminders(context.TODO(), &updatedCampaign)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(updatedCampaign)
 }
@@ -545,6 +615,11 @@ func getCampaignHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Show the owner how much budget is left
+	if campaign.BrandID == userID {
+		campaign.BudgetSummary = campaignBudgetSummary(&campaign)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(campaign)
 }
@@ -642,8 +717,12 @@ func applyCampaignHandler(w http.ResponseWriter, r *http.Request) {
 	campaignId := vars["campaignId"]
 
 	var req struct {
-		Followers string `json:"followers"`
-		Platform  string `json:"platform"`
+		Followers              string   `json:"followers"`
+		Platform               string   `json:"platform"`
+		Pitch                  string   `json:"pitch"`
+		ProposedRate           string   `json:"proposedRate"`
+		ProposedDeliverables   int      `json:"proposedDeliverables"`
+		ProposedContentFormats []string `json:"proposedContentFormats"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -651,6 +730,16 @@ func applyCampaignHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A proposed rate opens the negotiation with the creator's terms
+	var proposedTerms *ApplicationTerms
+	if req.ProposedRate != "" || req.ProposedDeliverables != 0 || len(req.ProposedContentFormats) > 0 {
+		proposedTerms = &ApplicationTerms{
+			Rate:           req.ProposedRate,
+			Deliverables:   req.ProposedDeliverables,
+			ContentFormats: req.ProposedContentFormats,
+		}
+	}
+
 	// Get user from context
 	user, ok := getUserFromContext(r.Context())
 	if !ok {
@@ -681,6 +770,14 @@ func applyCampaignHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Rates are read in the campaign's currency
+	if proposedTerms != nil {
+		if err := normalizeTerms(proposedTerms, campaignCurrency(&campaign)); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
 	// Get user's Clerk ID
 	userID := getUserIDFromClerkUser(user)
 
@@ -702,6 +799,7 @@ func applyCampaignHandler(w http.ResponseWriter, r *http.Request) {
 	existingCount, err := appsCollection.CountDocuments(ctx, bson.M{
 		"campaignId": campaignObjID,
 		"creatorId":  userID,
+		"status":     bson.M{"$ne": ApplicationStatusWithdrawn},
 	})
 	if err != nil {
 		http.Error(w, "Error checking existing applications", http.StatusInternalServerError)
@@ -726,19 +824,181 @@ func applyCampaignHandler(w http.ResponseWriter, r *http.Request) {
 		CampaignName: campaign.Title,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
+
+		Pitch:         strings.TrimSpace(req.Pitch),
+		ProposedTerms: proposedTerms,
 	}
 
-	_, err = appsCollection.InsertOne(ctx, application)
+	// Store the application, its applicant count and any opening offer together,
+	// so proposed terms never exist without an open offer
+	var offer *Offer
+	err = withTransaction(ctx, func(sc mongo.SessionContext) error {
+		if _, err := appsCollection.InsertOne(sc, application); err != nil {
+			return err
+		}
+
+		// Keep the campaign's applicant counter in step
+		_, err := campaignCollection.UpdateOne(sc, bson.M{"_id": campaignObjID}, bson.M{"$inc": bson.M{"applicants": 1}})
+		if err != nil || proposedTerms == nil {
+			return err
+		}
+
+		offer, err = storeOffer(sc, &campaign, &application, userID, *proposedTerms, application.Pitch)
+		return err
+	})
 	if err != nil {
 		http.Error(w, "Error creating application", http.StatusInternalServerError)
 		return
 	}
 
+	publishEvent(EventApplicationCreated, application, campaign.BrandID, userID)
+	notifyApplicationCreated(&campaign, &application)
+	if offer != nil {
+		announceOffer(&campaign, &application, offer)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(application)
 }
 
+// updateApplicationHandler lets the creator edit their pitch, platform and
+// follower count while the application is still pending
+func updateApplicationHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	applicationId := vars["applicationId"]
+
+	var req struct {
+		Pitch     *string `json:"pitch"`
+		Platform  *string `json:"platform"`
+		Followers *string `json:"followers"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	// Convert application ID to ObjectID
+	appObjID, err := primitive.ObjectIDFromHex(applicationId)
+	if err != nil {
+		http.Error(w, "Invalid application ID", http.StatusBadRequest)
+		return
+	}
+
+	set := bson.M{"updatedAt": time.Now()}
+	if req.Pitch != nil {
+		set["pitch"] = strings.TrimSpace(*req.Pitch)
+	}
+	if req.Platform != nil {
+		if strings.TrimSpace(*req.Platform) == "" {
+			http.Error(w, "platform cannot be empty", http.StatusBadRequest)
+			return
+		}
+		set["platform"] = strings.TrimSpace(*req.Platform)
+	}
+	if req.Followers != nil {
+		set["followers"] = strings.TrimSpace(*req.Followers)
+	}
+	if len(set) == 1 {
+		http.Error(w, "Nothing to update: send pitch, platform or followers", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID := getUserIDFromClerkUser(user)
+	application, campaign, status, msg := loadApplicationForParticipant(ctx, appObjID, userID)
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+	if application.CreatorID != userID {
+		http.Error(w, "Only the applicant can edit this application", http.StatusForbidden)
+		return
+	}
+
+	// Guard on the status so a review in the meantime wins
+	var updated Application
+	err = database.Collection("applications").FindOneAndUpdate(ctx,
+		bson.M{"_id": appObjID, "status": "pending"},
+		bson.M{"$set": set},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err == mongo.ErrNoDocuments {
+		http.Error(w, "Only pending applications can be edited", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error updating application", http.StatusInternalServerError)
+		return
+	}
+
+	publishEvent(EventApplicationUpdated, updated, campaign.BrandID, userID)
+	notifyApplicationUpdated(campaign, &updated)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// withdrawApplicationHandler lets the creator withdraw an application that has
+// not been rejected. Withdrawing an approved application releases its budget
+// reservation and unearned fee.
+func withdrawApplicationHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	applicationId := vars["applicationId"]
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	// Convert application ID to ObjectID
+	appObjID, err := primitive.ObjectIDFromHex(applicationId)
+	if err != nil {
+		http.Error(w, "Invalid application ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	userID := getUserIDFromClerkUser(user)
+	application, campaign, status, msg := loadApplicationForParticipant(ctx, appObjID, userID)
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+	if application.CreatorID != userID {
+		http.Error(w, "Only the applicant can withdraw this application", http.StatusForbidden)
+		return
+	}
+
+	if application.Status != ApplicationStatusWithdrawn && !canTransitionApplication(application.Status, ApplicationStatusWithdrawn) {
+		http.Error(w, "This application is already "+application.Status, http.StatusConflict)
+		return
+	}
+
+	application, status, msg = applyApplicationStatus(ctx, campaign, application, ApplicationStatusWithdrawn, false)
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(application)
+}
+
 // Get creator's applications
 func getCreatorApplicationsHandler(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -786,7 +1046,8 @@ func updateApplicationStatusHandler(w http.ResponseWriter, r *http.Request) {
 	applicationId := vars["applicationId"]
 
 	var req struct {
-		Status string `json:"status"`
+		Status         string `json:"status"`
+		OverrideBudget bool   `json:"overrideBudget"` // Approve even if the campaign budget would be exceeded
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -821,33 +1082,236 @@ func updateApplicationStatusHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Update application status
-	collection := database.Collection("applications")
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	filter := bson.M{"_id": appObjID}
-	update := bson.M{
-		"$set": bson.M{
-			"status":    req.Status,
-			"updatedAt": time.Now(),
-		},
+	// Only the campaign's brand may review its applications
+	userID := getUserIDFromClerkUser(user)
+	application, campaign, status, msg := loadApplicationForParticipant(ctx, appObjID, userID)
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
 	}
-
-	result, err := collection.UpdateOne(ctx, filter, update)
-	if err != nil {
-		http.Error(w, "Error updating application", http.StatusInternalServerError)
+	if campaign.BrandID != userID {
+		http.Error(w, "Access denied: You can only review applications to your own campaigns", http.StatusForbidden)
 		return
 	}
 
-	if result.MatchedCount == 0 {
-		http.Error(w, "Application not found", http.StatusNotFound)
+	application, status, msg = applyApplicationStatus(ctx, campaign, application, req.Status, req.OverrideBudget)
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"message": "Application status updated successfully",
-		"status":  req.Status,
+		"message":       "Application status updated successfully",
+		"status":        application.Status,
+		"application":   application,
+		"budgetSummary": campaignBudgetSummary(campaign),
+	})
+}
+
+// applicationChange is a status change made by changeApplicationStatus,
+// kept so its events and notifications can be sent once it is committed
+type applicationChange struct {
+	previous     string
+	application  *Application
+	deliverables []Deliverable
+	contract     *Contract
+}
+
+// changeApplicationStatus moves an application to newStatus and makes the
+// database changes that go with it: approving reserves the creator's fee
+// against the campaign budget, books the commitment and generates the
+// deliverables and contract; leaving "approved" releases the reservation and
+// the unearned commitment and cancels the unpublished deliverables. Run it in a transaction so a failure part way
+// leaves nothing behind, and announce the change once committed. On failure
+// it returns the HTTP status and message to send to the client.
+func changeApplicationStatus(ctx context.Context, campaign *Campaign, application *Application, newStatus string, overrideBudget bool) (*applicationChange, int, string) {
+	previous := application.Status
+	if previous == ApplicationStatusWithdrawn {
+		return nil, http.StatusConflict, "This application was withdrawn by the creator"
+	}
+	if !canTransitionApplication(previous, newStatus) {
+		return nil, http.StatusConflict, fmt.Sprintf("Cannot move an application from %s to %s", previous, newStatus)
+	}
+
+	// Approving reserves the agreed fee against the campaign budget, so any
+	// negotiation has to be settled first
+	var reserved int64
+	if newStatus == "approved" {
+		if status, msg := checkNoOpenOffer(ctx, application); status != http.StatusOK {
+			return nil, status, msg
+		}
+		fee := applicationFee(campaign, application)
+		if err := reserveBudget(ctx, campaign, fee, overrideBudget); err != nil {
+			if err == errBudgetExceeded {
+				summary := campaignBudgetSummary(campaign)
+				return nil, http.StatusConflict, fmt.Sprintf("Approving this creator would exceed the campaign budget (remaining %s %s, fee %s). Set overrideBudget to approve anyway.",
+					formatMoney(summary.Remaining, summary.Currency), summary.Currency, formatMoney(fee, summary.Currency))
+			}
+			return nil, http.StatusInternalServerError, "Error reserving campaign budget"
+		}
+		reserved = fee
+	}
+
+	set := bson.M{"status": newStatus, "updatedAt": time.Now()}
+	update := bson.M{"$set": set}
+	if newStatus == "approved" {
+		set["reservedAmount"] = reserved
+		update["$inc"] = bson.M{"approvalRound": 1}
+	} else if previous == "approved" {
+		set["reservedAmount"] = 0
+	}
+
+	// Guard on the previous status so concurrent reviews cannot both apply
+	var updated Application
+	err := database.Collection("applications").FindOneAndUpdate(ctx,
+		bson.M{"_id": application.ID, "status": previous},
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&updated)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, http.StatusConflict, "Application status changed concurrently, please retry"
+		}
+		return nil, http.StatusInternalServerError, "Error updating application"
+	}
+	updated.CampaignName = campaign.Title
+	change := &applicationChange{previous: previous, application: &updated}
+
+	// Leaving "approved" gives the reservation and unearned fee back and
+	// cancels the work and the unsigned contract still owed
+	if previous == "approved" {
+		if err := releaseBudget(ctx, campaign, application.ReservedAmount); err != nil {
+			return nil, http.StatusInternalServerError, "Error releasing campaign budget"
+		}
+		if err := releaseCommitment(ctx, campaign, application); err != nil {
+			return nil, http.StatusInternalServerError, "Error releasing commitment"
+		}
+		if change.deliverables, err = cancelDeliverables(ctx, application); err != nil {
+			log.Printf("Error cancelling deliverables for application %s: %v", application.ID.Hex(), err)
+			return nil, http.StatusInternalServerError, "Error cancelling deliverables"
+		}
+		if err := supersedePendingContracts(ctx, application.ID); err != nil {
+			return nil, http.StatusInternalServerError, "Error closing contract"
+		}
+	}
+
+	// Approved creators are owed the fee and owe the campaign's deliverables,
+	// both set out in a contract for the two parties to sign
+	if newStatus == "approved" {
+		if err := recordCommitment(ctx, campaign, &updated); err != nil {
+			return nil, http.StatusInternalServerError, "Error recording commitment"
+		}
+		if change.deliverables, err = generateDeliverables(ctx, campaign, &updated); err != nil {
+			log.Printf("Error generating deliverables for application %s: %v", application.ID.Hex(), err)
+			return nil, http.StatusInternalServerError, "Error generating deliverables"
+		}
+		if change.contract, err = storeContract(ctx, campaign, &updated); err != nil {
+			log.Printf("Error generating contract for application %s: %v", application.ID.Hex(), err)
+			return nil, http.StatusInternalServerError, "Error generating contract"
+		}
+	}
+
+	// A withdrawn application no longer counts as an applicant and its
+	// negotiation is over
+	if newStatus == ApplicationStatusWithdrawn {
+		_, err = database.Collection("campaigns").UpdateOne(ctx,
+			bson.M{"_id": campaign.ID, "applicants": bson.M{"$gt": 0}},
+			bson.M{"$inc": bson.M{"applicants": -1}},
+		)
+		if err != nil {
+			return nil, http.StatusInternalServerError, "Error updating applicant count"
+		}
+		_, err = database.Collection("offers").UpdateMany(ctx,
+			bson.M{"applicationId": application.ID, "status": OfferStatusOpen},
+			bson.M{"$set": bson.M{"status": OfferStatusSuperseded, "respondedAt": time.Now()}},
+		)
+		if err != nil {
+			return nil, http.StatusInternalServerError, "Error closing offers"
+		}
+	}
+
+	return change, http.StatusOK, ""
+}
+
+// announceApplicationChange lets the applicant and the brand know about a committed status change
+func announceApplicationChange(campaign *Campaign, change *applicationChange) {
+	updated := change.application
+	publishEvent(EventApplicationStatusChanged, *updated, campaign.BrandID, updated.CreatorID)
+	if updated.Status == ApplicationStatusWithdrawn {
+		notifyApplicationWithdrawn(campaign, updated)
+	} else {
+		notifyApplicationStatusChanged(updated)
+	}
+
+	if len(change.deliverables) > 0 {
+		publishEvent(EventDeliverableUpdated, change.deliverables, campaign.BrandID, updated.CreatorID)
+	}
+	if change.contract != nil {
+		announceContract(campaign, change.contract)
+	}
+}
+
+// applyApplicationStatus changes one application's status in a transaction
+// and announces it once committed. On failure nothing is changed and it
+// returns the HTTP status and message to send to the client.
+func applyApplicationStatus(ctx context.Context, campaign *Campaign, application *Application, newStatus string, overrideBudget bool) (*Application, int, string) {
+	if application.Status == newStatus {
+		return application, http.StatusOK, ""
+	}
+
+	reservedBudget := campaign.ReservedBudget
+	var change *applicationChange
+	var status int
+	var msg string
+	err := withTransaction(ctx, func(sc mongo.SessionContext) error {
+		campaign.ReservedBudget = reservedBudget
+		change, status, msg = changeApplicationStatus(sc, campaign, application, newStatus, overrideBudget)
+		if status != http.StatusOK {
+			return errAbortTransaction
+		}
+		return nil
 	})
+	if err != nil {
+		campaign.ReservedBudget = reservedBudget
+		if errors.Is(err, errAbortTransaction) {
+			return nil, status, msg
+		}
+		return nil, http.StatusInternalServerError, "Error updating application"
+	}
+
+	announceApplicationChange(campaign, change)
+	return change.application, http.StatusOK, ""
+}
+
+// loadApplicationForParticipant fetches an application and its campaign and
+// checks that userID is either the campaign's brand or the applicant. On
+// failure it returns the HTTP status and message to send to the client.
+func loadApplicationForParticipant(ctx context.Context, applicationID primitive.ObjectID, userID string) (*Application, *Campaign, int, string) {
+	var application Application
+	err := database.Collection("applications").FindOne(ctx, bson.M{"_id": applicationID}).Decode(&application)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil, http.StatusNotFound, "Application not found"
+		}
+		return nil, nil, http.StatusInternalServerError, "Error fetching application"
+	}
+
+	var campaign Campaign
+	err = database.Collection("campaigns").FindOne(ctx, bson.M{"_id": application.CampaignID}).Decode(&campaign)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil, http.StatusNotFound, "Campaign not found"
+		}
+		return nil, nil, http.StatusInternalServerError, "Error fetching campaign"
+	}
+
+	if campaign.BrandID != userID && application.CreatorID != userID {
+		return nil, nil, http.StatusForbidden, "Access denied"
+	}
+
+	return &application, &campaign, http.StatusOK, ""
 }
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2/jwks"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// Build information, set at build time with
+// -ldflags "-X main.version=1.2.0 -X main.commit=abc123 -X main.buildTime=2024-01-01T00:00:00Z".
+// Without them the VCS details Go embeds in the binary are used when present.
+var (
+	version   = "dev"
+	commit    = ""
+	buildTime = ""
+)
+
+// startedAt is when the process started, for uptime
+var startedAt = time.Now()
+
+// BuildInfo identifies the running binary
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"buildTime,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // Built from a tree with uncommitted changes
+	GoVersion string `json:"goVersion"`
+}
+
+// currentBuildInfo combines the ldflags values with what Go recorded at build time
+func currentBuildInfo() BuildInfo {
+	info := BuildInfo{Version: version, Commit: commit, BuildTime: buildTime, GoVersion: runtime.Version()}
+	if embedded, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range embedded.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+	return info
+}
+
+// Statuses of a dependency check and of the service overall
+const (
+	HealthStatusOK          = "ok"
+	HealthStatusDegraded    = "degraded"    // A non-critical dependency is failing; still serving
+	HealthStatusUnavailable = "unavailable" // Not ready for traffic
+)
+
+// HealthCheck is the outcome of checking one dependency
+type HealthCheck struct {
+	Status    string     `json:"status"`
+	Critical  bool       `json:"critical"` // Whether failure makes the instance unready
+	LatencyMs int64      `json:"latencyMs"`
+	Error     string     `json:"error,omitempty"`
+	LastRunAt *time.Time `json:"lastRunAt,omitempty"` // Background workers only
+}
+
+// HealthReport is returned by the health endpoints
+type HealthReport struct {
+	Status    string                 `json:"status"`
+	Message   string                 `json:"message,omitempty"`
+	Checks    map[string]HealthCheck `json:"checks,omitempty"`
+	Build     BuildInfo              `json:"build"`
+	StartedAt time.Time              `json:"startedAt"`
+	Uptime    string                 `json:"uptime"`
+}
+
+// healthCheckTimeout bounds each readiness check so a hung dependency cannot hang the probe
+const healthCheckTimeout = 3 * time.Second
+
+// authCheckCacheTTL spaces out calls to Clerk, which is shared by every instance and probe
+const authCheckCacheTTL = 30 * time.Second
+
+// Heartbeat records that a background worker is still looping, so a stuck
+// or crashed worker shows up in readiness
+type Heartbeat struct {
+	name    string
+	maxAge  time.Duration // Longer than this without a beat means stuck
+	mu      sync.Mutex
+	last    time.Time
+	stopped bool
+}
+
+var (
+	heartbeatsMu sync.Mutex
+	heartbeats   []*Heartbeat
+)
+
+// registerHeartbeat adds a worker to the readiness checks
+func registerHeartbeat(name string, maxAge time.Duration) *Heartbeat {
+	h := &Heartbeat{name: name, maxAge: maxAge, last: time.Now()}
+	heartbeatsMu.Lock()
+	heartbeats = append(heartbeats, h)
+	heartbeatsMu.Unlock()
+	return h
+}
+
+// Beat marks the worker alive
+func (h *Heartbeat) Beat() {
+	h.mu.Lock()
+	h.last = time.Now()
+	h.mu.Unlock()
+}
+
+// Stop marks the worker as having exited
+func (h *Heartbeat) Stop() {
+	h.mu.Lock()
+	h.stopped = true
+	h.mu.Unlock()
+}
+
+func (h *Heartbeat) check() HealthCheck {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	last := h.last
+	result := HealthCheck{Status: HealthStatusOK, Critical: true, LastRunAt: &last}
+	switch {
+	case h.stopped:
+		result.Status, result.Error = HealthStatusUnavailable, "worker has stopped"
+	case time.Since(h.last) > h.maxAge:
+		result.Status = HealthStatusUnavailable
+		result.Error = fmt.Sprintf("no progress for %s", time.Since(h.last).Round(time.Second))
+	}
+	return result
+}
+
+// timedCheck runs check and records its latency and any error
+func timedCheck(ctx context.Context, critical bool, check func(context.Context) error) HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := HealthCheck{Status: HealthStatusOK, Critical: critical, LatencyMs: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status, result.Error = HealthStatusUnavailable, err.Error()
+	}
+	return result
+}
+
+// checkMongo pings the primary
+func checkMongo(ctx context.Context) error {
+	if client == nil {
+		return fmt.Errorf("not connected")
+	}
+	return client.Ping(ctx, readpref.Primary())
+}
+
+var (
+	authCheckMu     sync.Mutex
+	authCheckResult HealthCheck
+	authCheckAt     time.Time
+)
+
+// checkAuth fetches Clerk's signing keys, which every token verification
+// needs. The result is cached briefly.
+func checkAuth(ctx context.Context) HealthCheck {
+	authCheckMu.Lock()
+	defer authCheckMu.Unlock()
+
+	if !authCheckAt.IsZero() && time.Since(authCheckAt) < authCheckCacheTTL {
+		return authCheckResult
+	}
+	// Not critical: Clerk is shared by every instance, so failing readiness
+	// everywhere would also take down the routes that need no login
+	authCheckResult = timedCheck(ctx, false, func(ctx context.Context) error {
+		keys, err := jwks.Get(ctx, &jwks.GetParams{})
+		if err != nil {
+			return err
+		}
+		if len(keys.Keys) == 0 {
+			return fmt.Errorf("no signing keys published")
+		}
+		return nil
+	})
+	authCheckAt = time.Now()
+	return authCheckResult
+}
+
+// runReadinessChecks checks every dependency concurrently
+func runReadinessChecks(ctx context.Context) map[string]HealthCheck {
+	checks := make(map[string]HealthCheck)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	record := func(name string, result HealthCheck) {
+		mu.Lock()
+		checks[name] = result
+		mu.Unlock()
+	}
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		record("mongo", timedCheck(ctx, true, checkMongo))
+	}()
+	go func() {
+		defer wg.Done()
+		record("auth", checkAuth(ctx))
+	}()
+
+	heartbeatsMu.Lock()
+	for _, h := range heartbeats {
+		record(h.name, h.check())
+	}
+	heartbeatsMu.Unlock()
+
+	wg.Wait()
+	return checks
+}
+
+// overallHealthStatus is unavailable if a critical check fails and degraded if any other does
+func overallHealthStatus(checks map[string]HealthCheck) string {
+	status := HealthStatusOK
+	for _, check := range checks {
+		if check.Status == HealthStatusOK {
+			continue
+		}
+		if check.Critical {
+			return HealthStatusUnavailable
+		}
+		status = HealthStatusDegraded
+	}
+	return status
+}
+
+func newHealthReport(status string) HealthReport {
+	return HealthReport{
+		Status:    status,
+		Build:     currentBuildInfo(),
+		StartedAt: startedAt,
+		Uptime:    time.Since(startedAt).Round(time.Second).String(),
+	}
+}
+
+func writeHealthReport(w http.ResponseWriter, code int, report HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(report)
+}
+
+// healthCheck is the original health endpoint, kept for existing monitors. It
+// only says the process is up; use /health/ready for dependencies.
+func healthCheck(w http.ResponseWriter, r *http.Request) {
+	report := newHealthReport("OK")
+	report.Message = GetAPIMessage()
+	writeHealthReport(w, http.StatusOK, report)
+}
+
+// livenessHandler answers as long as the process can serve requests. It
+// checks no dependencies, so a database outage does not get the process restarted.
+func livenessHandler(w http.ResponseWriter, r *http.Request) {
+	writeHealthReport(w, http.StatusOK, newHealthReport(HealthStatusOK))
+}
+
+// readinessHandler reports whether this instance should receive traffic,
+// with the status and latency of each dependency. It answers 503 when a
+// critical dependency is failing.
+func readinessHandler(w http.ResponseWriter, r *http.Request) {
+	checks := runReadinessChecks(r.Context())
+	report := newHealthReport(overallHealthStatus(checks))
+	report.Checks = checks
+
+	code := http.StatusOK
+	if report.Status == HealthStatusUnavailable {
+		code = http.StatusServiceUnavailable
+	}
+	writeHealthReport(w, code, report)
+}
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	maxImportRows  = 1000
+	maxImportBytes = 5 << 20
+)
+
+// CampaignImportRow reports the outcome for one imported row
+type CampaignImportRow struct {
+	Row        int      `json:"row"` // CSV line number, or 1-based index in a JSON array
+	Title      string   `json:"title"`
+	Valid      bool     `json:"valid"`
+	Errors     []string `json:"errors,omitempty"`
+	CampaignID string   `json:"campaignId,omitempty"`
+}
+
+// CampaignImportResult is the response for an import or dry run
+type CampaignImportResult struct {
+	DryRun  bool                `json:"dryRun"`
+	Total   int                 `json:"total"`
+	Valid   int                 `json:"valid"`
+	Invalid int                 `json:"invalid"`
+	Created int                 `json:"created"`
+	Rows    []CampaignImportRow `json:"rows"`
+}
+
+// importField locates a CampaignRequest field by its dotted JSON key
+type importField struct {
+	index []int
+	kind  reflect.Kind // String, Bool or Slice (of strings)
+}
+
+// importFields maps lower-cased dotted keys (e.g. "targetaudience.location") to CampaignRequest fields
+var importFields = buildImportFields(reflect.TypeOf(CampaignRequest{}), "", nil)
+
+func buildImportFields(t reflect.Type, prefix string, parent []int) map[string]importField {
+	fields := make(map[string]importField)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		index := append(append([]int{}, parent...), i)
+		key := strings.ToLower(prefix + name)
+		if field.Type.Kind() == reflect.Struct {
+			for nested, f := range buildImportFields(field.Type, prefix+name+".", index) {
+				fields[nested] = f
+			}
+			continue
+		}
+		fields[key] = importField{index: index, kind: field.Type.Kind()}
+	}
+	return fields
+}
+
+// importIgnoredColumns are export columns with no meaning on import, so an
+// export can be edited and imported again. Imported campaigns are always drafts.
+var importIgnoredColumns = func() map[string]bool {
+	ignored := map[string]bool{"status": true}
+	for _, column := range exportColumns(reflect.TypeOf(Campaign{})) {
+		if _, ok := importFields[strings.ToLower(column)]; !ok {
+			ignored[strings.ToLower(column)] = true
+		}
+	}
+	return ignored
+}()
+
+// setImportField parses raw into the request field. Lists are separated by ";".
+func setImportField(req *CampaignRequest, field importField, raw string) error {
+	v := reflect.ValueOf(req).Elem().FieldByIndex(field.index)
+	raw = spreadsheetUnsafe(strings.TrimSpace(raw))
+
+	switch field.kind {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		if raw == "" {
+			return nil
+		}
+		switch strings.ToLower(raw) {
+		case "yes", "y":
+			v.SetBool(true)
+		case "no", "n":
+			v.SetBool(false)
+		default:
+			b, err := strconv.ParseBool(raw)
+			if err != nil {
+				return fmt.Errorf("%q is not true or false", raw)
+			}
+			v.SetBool(b)
+		}
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ";") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	}
+	return nil
+}
+
+// readImportCSV maps each CSV record onto a CampaignRequest using the header row
+func readImportCSV(body io.Reader) ([]CampaignRequest, []CampaignImportRow, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read CSV header: %v", err)
+	}
+
+	columns := make([]*importField, len(header))
+	for i, name := range header {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if field, ok := importFields[key]; ok {
+			field := field
+			columns[i] = &field
+			continue
+		}
+		if key != "" && !importIgnoredColumns[key] {
+			return nil, nil, fmt.Errorf("unknown column %q", strings.TrimSpace(name))
+		}
+	}
+
+	var requests []CampaignRequest
+	var rows []CampaignImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not read CSV: %v", err)
+		}
+		line, _ := reader.FieldPos(0)
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue // Skip blank spreadsheet rows
+		}
+		if len(requests) == maxImportRows {
+			return nil, nil, fmt.Errorf("at most %d rows can be imported at once", maxImportRows)
+		}
+
+		var req CampaignRequest
+		row := CampaignImportRow{Row: line}
+		for i, value := range record {
+			if i >= len(columns) {
+				row.Errors = append(row.Errors, fmt.Sprintf("line has more values than the header (%d)", len(header)))
+				break
+			}
+			if columns[i] == nil {
+				continue
+			}
+			if err := setImportField(&req, *columns[i], value); err != nil {
+				row.Errors = append(row.Errors, fmt.Sprintf("%s: %v", strings.TrimSpace(header[i]), err))
+			}
+		}
+		requests = append(requests, req)
+		rows = append(rows, row)
+	}
+	return requests, rows, nil
+}
+
+// readImportJSON decodes a JSON array of CampaignRequest objects. Rows that do
+// not decode are reported individually instead of failing the whole import.
+func readImportJSON(body io.Reader) ([]CampaignRequest, []CampaignImportRow, error) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(body).Decode(&raw); err != nil {
+		return nil, nil, fmt.Errorf("body must be a JSON array of campaigns")
+	}
+	if len(raw) > maxImportRows {
+		return nil, nil, fmt.Errorf("at most %d rows can be imported at once", maxImportRows)
+	}
+
+	requests := make([]CampaignRequest, len(raw))
+	rows := make([]CampaignImportRow, len(raw))
+	for i, item := range raw {
+		rows[i].Row = i + 1
+		if err := json.Unmarshal(item, &requests[i]); err != nil {
+			rows[i].Errors = append(rows[i].Errors, "invalid campaign: "+err.Error())
+		}
+	}
+	return requests, rows, nil
+}
+
+// compensationFixedPayment is the compensation type whose paymentAmount is a money amount
+const compensationFixedPayment = "Fixed Payment"
+
+// validMoney reports whether s is one positive amount or a range of them,
+// such as the campaign form's "$1,000-$2,500" or "$25,000+"
+func validMoney(s, currency string) bool {
+	if isMoneyRange(s) {
+		_, _, err := parseMoneyRange(s, currency)
+		return err == nil
+	}
+	amount, err := parseMoney(s, currency)
+	return err == nil && amount > 0
+}
+
+// validateCampaignRequest checks a created, duplicated, templated or imported
+// campaign and normalizes its currency
+func validateCampaignRequest(req *CampaignRequest) []string {
+	var errs []string
+
+	if strings.TrimSpace(req.Title) == "" {
+		errs = append(errs, "title is required")
+	}
+
+	if req.Currency == "" {
+		req.Currency = defaultCurrency
+	}
+	if currency, err := normalizeCurrency(req.Currency); err != nil {
+		errs = append(errs, err.Error())
+	} else {
+		req.Currency = currency
+	}
+
+	var start, end time.Time
+	var err error
+	if req.StartDate != "" {
+		if start, err = time.Parse(campaignDateLayout, req.StartDate); err != nil {
+			errs = append(errs, "startDate must be in YYYY-MM-DD format")
+		}
+	}
+	if req.EndDate != "" {
+		if end, err = time.Parse(campaignDateLayout, req.EndDate); err != nil {
+			errs = append(errs, "endDate must be in YYYY-MM-DD format")
+		}
+	}
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		errs = append(errs, "endDate must not be before startDate")
+	}
+
+	if req.Budget != "" && !validMoney(req.Budget, req.Currency) {
+		errs = append(errs, "budget must be a positive amount or range")
+	}
+	// Other compensation types describe the payment in words ("5% commission").
+	// A range only sets expectations; the fee is agreed per application.
+	if req.PaymentAmount != "" && req.CompensationType == compensationFixedPayment && !validMoney(req.PaymentAmount, req.Currency) {
+		errs = append(errs, "paymentAmount must be a positive amount or range")
+	}
+
+	return errs
+}
+
+// importFormat picks csv or json from ?format=, the upload's file name or the Content-Type
+func importFormat(r *http.Request, filename, contentType string) string {
+	if format := strings.ToLower(r.URL.Query().Get("format")); format != "" {
+		return format
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return "csv"
+	case ".json":
+		return "json"
+	}
+	if mediaType, _, _ := mime.ParseMediaType(contentType); strings.Contains(mediaType, "csv") {
+		return "csv"
+	}
+	return "json"
+}
+
+// importCampaignsHandler creates draft campaigns from a CSV or JSON upload.
+// With ?dryRun=true nothing is written and the per-row results are returned.
+func importCampaignsHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	if getUserTypeFromClerkUser(user) != "brand" {
+		http.Error(w, "Only brands can import campaigns", http.StatusForbidden)
+		return
+	}
+	userID := getUserIDFromClerkUser(user)
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+
+	// Accept a raw body or a multipart upload in the "file" field
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	var body io.Reader = r.Body
+	filename := ""
+	contentType := r.Header.Get("Content-Type")
+	if strings.HasPrefix(contentType, "multipart/form-data") {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "Upload the campaigns in a \"file\" field", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		body = file
+		filename = header.Filename
+		contentType = header.Header.Get("Content-Type")
+	}
+
+	var requests []CampaignRequest
+	var rows []CampaignImportRow
+	var err error
+	switch importFormat(r, filename, contentType) {
+	case "csv":
+		requests, rows, err = readImportCSV(body)
+	case "json":
+		requests, rows, err = readImportJSON(body)
+	default:
+		http.Error(w, "Unknown format: use csv or json", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(requests) == 0 {
+		http.Error(w, "No campaigns to import", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var dbUser User
+	err = database.Collection("users").FindOne(ctx, bson.M{"clerkId": userID}).Decode(&dbUser)
+	if err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	// Validate every row before writing anything
+	result := CampaignImportResult{DryRun: dryRun, Total: len(requests)}
+	var campaigns []interface{}
+	titles := make(map[string]int)
+	for i := range requests {
+		req := &requests[i]
+		row := &rows[i]
+		row.Title = req.Title
+		row.Errors = append(row.Errors, validateCampaignRequest(req)...)
+
+		title := strings.ToLower(strings.TrimSpace(req.Title))
+		if first, ok := titles[title]; ok && title != "" {
+			row.Errors = append(row.Errors, fmt.Sprintf("duplicate title, first seen in row %d", first))
+		} else {
+			titles[title] = row.Row
+		}
+
+		row.Valid = len(row.Errors) == 0
+		if !row.Valid {
+			result.Invalid++
+			continue
+		}
+		result.Valid++
+
+		campaign := newCampaignFromRequest(req, userID, dbUser.Name)
+		// The create form sends the targetAudience* lists; imports may also fill the older group
+		campaign.TargetAudience.Location = req.TargetAudience.Location
+		campaign.TargetAudience.AgeGroup = req.TargetAudience.AgeGroup
+		campaign.TargetAudience.Gender = req.TargetAudience.Gender
+		campaign.TargetAudience.Interests = req.TargetAudience.Interests
+		campaigns = append(campaigns, campaign)
+		if !dryRun {
+			row.CampaignID = campaign.ID.Hex()
+		}
+	}
+	result.Rows = rows
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if dryRun || len(campaigns) == 0 {
+		if !dryRun {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		}
+		json.NewEncoder(w).Encode(result)
+		return
+	}
+
+	// Insert every valid row in one transaction so a failure leaves nothing behind
+	err = withTransaction(ctx, func(sc mongo.SessionContext) error {
+		_, err := database.Collection("campaigns").InsertMany(sc, campaigns)
+		return err
+	})
+	if err != nil {
+		log.Printf("importCampaignsHandler: Error inserting campaigns: %v", err)
+		http.Error(w, "Error creating campaigns", http.StatusInternalServerError)
+		return
+	}
+	result.Created = len(campaigns)
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(result)
+}
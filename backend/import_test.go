@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestValidateCampaignRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		req  CampaignRequest
+		errs int
+	}{
+		{"minimal", CampaignRequest{Title: "Launch"}, 0},
+		{"form values", CampaignRequest{Title: "Launch", Budget: "$1,000-$2,500", NumberOfPosts: "1 Instagram reel",
+			CompensationType: "Commission", PaymentAmount: "5% commission"}, 0},
+		{"missing title", CampaignRequest{Title: "  "}, 1},
+		{"bad currency", CampaignRequest{Title: "Launch", Currency: "dollars"}, 1},
+		{"bad date", CampaignRequest{Title: "Launch", StartDate: "01/02/2026"}, 1},
+		{"end before start", CampaignRequest{Title: "Launch", StartDate: "2026-02-01", EndDate: "2026-01-01"}, 1},
+		{"open-ended budget", CampaignRequest{Title: "Launch", Budget: "$25,000+"}, 0},
+		{"payment range", CampaignRequest{Title: "Launch", CompensationType: compensationFixedPayment, PaymentAmount: "$500-$1,000"}, 0},
+		{"inverted budget range", CampaignRequest{Title: "Launch", Budget: "$2,500-$1,000"}, 1},
+		{"negative budget", CampaignRequest{Title: "Launch", Budget: "-500"}, 1},
+		{"zero budget", CampaignRequest{Title: "Launch", Budget: "0"}, 1},
+		{"fixed payment without amount", CampaignRequest{Title: "Launch", CompensationType: compensationFixedPayment, PaymentAmount: "negotiable"}, 1},
+		{"everything wrong", CampaignRequest{Budget: "lots", Currency: "XX", EndDate: "soon"}, 4},
+	}
+	for _, tt := range tests {
+		req := tt.req
+		if errs := validateCampaignRequest(&req); len(errs) != tt.errs {
+			t.Errorf("%s: got errors %q, want %d", tt.name, errs, tt.errs)
+		}
+	}
+
+	req := CampaignRequest{Title: "Launch", Currency: " eur "}
+	if errs := validateCampaignRequest(&req); len(errs) != 0 || req.Currency != "EUR" {
+		t.Errorf("currency = %q (errors %q), want EUR", req.Currency, errs)
+	}
+	req = CampaignRequest{Title: "Launch"}
+	if validateCampaignRequest(&req); req.Currency != defaultCurrency {
+		t.Errorf("default currency = %q, want %q", req.Currency, defaultCurrency)
+	}
+}
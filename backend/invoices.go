@@ -0,0 +1,767 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Invoice kinds, one per billable ledger transaction kind
+const (
+	InvoiceKindApplication = "application" // The whole fee of an approved application
+	InvoiceKindMilestone   = "milestone"   // The share earned by a published deliverable
+	InvoiceKindBonus       = "bonus"       // A performance bonus or commission
+)
+
+// defaultInvoiceDueDays is the payment term used when none is given
+const defaultInvoiceDueDays = 30
+
+// invoiceKinds maps the ledger transactions that can be invoiced to invoice kinds
+var invoiceKinds = map[string]string{
+	LedgerKindCommitment: InvoiceKindApplication,
+	LedgerKindMilestone:  InvoiceKindMilestone,
+	LedgerKindBonus:      InvoiceKindBonus,
+}
+
+// nextInvoiceSequence allocates the brand's next invoice number. Call it in
+// the transaction that inserts the invoice, so a failed insert gives the
+// number back and numbering has no gaps.
+func nextInvoiceSequence(ctx context.Context, brandID string) (int64, error) {
+	var counter struct {
+		Sequence int64 `bson:"sequence"`
+	}
+	err := database.Collection("invoice_counters").FindOneAndUpdate(ctx,
+		bson.M{"_id": brandID},
+		bson.M{"$inc": bson.M{"sequence": 1}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	return counter.Sequence, err
+}
+
+// invoiceNumber formats a brand's invoice sequence as its invoice number
+func invoiceNumber(sequence int64) string {
+	return fmt.Sprintf("INV-%05d", sequence)
+}
+
+// invoiceParty snapshots a user's billing details for an invoice
+func invoiceParty(ctx context.Context, userID, fallbackName, fallbackEmail string) InvoiceParty {
+	party := InvoiceParty{UserID: userID, Name: fallbackName, Email: fallbackEmail}
+
+	var user User
+	if err := database.Collection("users").FindOne(ctx, bson.M{"clerkId": userID}).Decode(&user); err != nil {
+		return party
+	}
+	if user.Name != "" {
+		party.Name = user.Name
+	}
+	if user.Email != "" {
+		party.Email = user.Email
+	}
+	if user.Billing != nil {
+		party.LegalName = user.Billing.LegalName
+		party.TaxID = user.Billing.TaxID
+		party.Address = user.Billing.Address
+		party.Country = user.Billing.Country
+	}
+	return party
+}
+
+// parseTaxRate reads a tax percentage such as "18" or "7.5%"
+func parseTaxRate(s string) (float64, error) {
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "%"))
+	if s == "" {
+		return 0, nil
+	}
+	rate, err := strconv.ParseFloat(s, 64)
+	if err != nil || rate < 0 || rate > 100 {
+		return 0, fmt.Errorf("taxRate must be a percentage between 0 and 100")
+	}
+	return rate, nil
+}
+
+// findInvoiceSource picks the ledger transaction to invoice: the given
+// transaction, the deliverable's milestone, or the application's latest commitment
+func findInvoiceSource(ctx context.Context, application *Application, transactionID, deliverableID primitive.ObjectID) (*LedgerTransaction, int, string) {
+	// Rejected or withdrawn applications had their commitment released
+	if application.Status != "approved" {
+		return nil, http.StatusConflict, "Only approved applications can be invoiced"
+	}
+
+	filter := bson.M{"applicationId": application.ID}
+	switch {
+	case !transactionID.IsZero():
+		filter["_id"] = transactionID
+	case !deliverableID.IsZero():
+		filter["idempotencyKey"] = "milestone:" + deliverableID.Hex()
+	default:
+		filter["idempotencyKey"] = commitmentKey(application)
+	}
+
+	var tx LedgerTransaction
+	err := database.Collection("ledger_transactions").FindOne(ctx, filter,
+		options.FindOne().SetSort(bson.D{{Key: "createdAt", Value: -1}}),
+	).Decode(&tx)
+	if err == mongo.ErrNoDocuments {
+		return nil, http.StatusConflict, "Nothing to invoice yet: the application has no recorded fee, milestone or bonus"
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError, "Error fetching ledger"
+	}
+	if _, ok := invoiceKinds[tx.Kind]; !ok {
+		return nil, http.StatusBadRequest, "Only commitments, milestones and bonuses can be invoiced"
+	}
+	// Commitments from an earlier approval were released when it was withdrawn
+	if tx.Kind == LedgerKindCommitment && tx.IdempotencyKey != commitmentKey(application) {
+		return nil, http.StatusConflict, "This commitment was released and can no longer be invoiced"
+	}
+	return &tx, http.StatusOK, ""
+}
+
+// checkInvoiceOverlap stops an application being billed both as a whole and per milestone
+func checkInvoiceOverlap(ctx context.Context, applicationID primitive.ObjectID, kind string) (int, string) {
+	var other string
+	switch kind {
+	case InvoiceKindApplication:
+		other = InvoiceKindMilestone
+	case InvoiceKindMilestone:
+		other = InvoiceKindApplication
+	default:
+		return http.StatusOK, ""
+	}
+
+	count, err := database.Collection("invoices").CountDocuments(ctx, bson.M{"applicationId": applicationID, "kind": other})
+	if err != nil {
+		return http.StatusInternalServerError, "Error checking invoices"
+	}
+	if count > 0 {
+		return http.StatusConflict, fmt.Sprintf("This application is already invoiced per %s", other)
+	}
+	return http.StatusOK, ""
+}
+
+// loadInvoiceForParticipant fetches an invoice the user issued or received
+func loadInvoiceForParticipant(ctx context.Context, invoiceId, userID string) (*Invoice, int, string) {
+	invoiceOID, err := primitive.ObjectIDFromHex(invoiceId)
+	if err != nil {
+		return nil, http.StatusBadRequest, "Invalid invoice ID"
+	}
+
+	var invoice Invoice
+	err = database.Collection("invoices").FindOne(ctx, bson.M{"_id": invoiceOID}).Decode(&invoice)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, http.StatusNotFound, "Invoice not found"
+		}
+		return nil, http.StatusInternalServerError, "Error fetching invoice"
+	}
+
+	if invoice.BrandID != userID && invoice.CreatorID != userID {
+		return nil, http.StatusForbidden, "Access denied"
+	}
+	return &invoice, http.StatusOK, ""
+}
+
+// invoiceCSVHeader lists the columns of invoice CSV exports
+var invoiceCSVHeader = []string{
+	"number", "issued_at", "due_at", "kind", "campaign", "seller", "seller_tax_id",
+	"buyer", "buyer_tax_id", "currency", "subtotal", "tax_label", "tax_rate", "tax_amount", "total",
+}
+
+// invoiceCSVRow renders an invoice as one CSV row. Text entered by users is
+// passed through spreadsheetSafe, as in exports.
+func invoiceCSVRow(invoice *Invoice) []string {
+	return []string{
+		invoice.Number,
+		invoice.IssuedAt.Format("2006-01-02"),
+		invoice.DueAt.Format("2006-01-02"),
+		invoice.Kind,
+		spreadsheetSafe(invoice.CampaignTitle),
+		spreadsheetSafe(partyName(invoice.Seller)),
+		spreadsheetSafe(invoice.Seller.TaxID),
+		spreadsheetSafe(partyName(invoice.Buyer)),
+		spreadsheetSafe(invoice.Buyer.TaxID),
+		invoice.Currency,
+		formatMoney(invoice.Subtotal, invoice.Currency),
+		spreadsheetSafe(invoice.TaxLabel),
+		strconv.FormatFloat(invoice.TaxRate, 'f', -1, 64),
+		formatMoney(invoice.TaxAmount, invoice.Currency),
+		formatMoney(invoice.Total, invoice.Currency),
+	}
+}
+
+// invoiceLinesCSVHeader lists the columns of an invoice's line item CSV
+var invoiceLinesCSVHeader = []string{"invoice", "description", "quantity", "unit_amount", "amount", "currency"}
+
+// invoiceLinesCSVRows renders an invoice's line items and totals as CSV rows
+func invoiceLinesCSVRows(invoice *Invoice) [][]string {
+	var rows [][]string
+	for _, line := range invoice.Lines {
+		rows = append(rows, []string{invoice.Number, spreadsheetSafe(line.Description), strconv.Itoa(line.Quantity),
+			formatMoney(line.UnitAmount, invoice.Currency), formatMoney(line.Amount, invoice.Currency), invoice.Currency})
+	}
+	return append(rows,
+		[]string{invoice.Number, "Subtotal", "", "", formatMoney(invoice.Subtotal, invoice.Currency), invoice.Currency},
+		[]string{invoice.Number, spreadsheetSafe(invoice.TaxLabel), "", "", formatMoney(invoice.TaxAmount, invoice.Currency), invoice.Currency},
+		[]string{invoice.Number, "Total", "", "", formatMoney(invoice.Total, invoice.Currency), invoice.Currency},
+	)
+}
+
+// partyName prefers the legal name printed on invoices
+func partyName(party InvoiceParty) string {
+	if party.LegalName != "" {
+		return party.LegalName
+	}
+	return party.Name
+}
+
+// writePartyLines prints one side of an invoice
+func writePartyLines(doc *PDFDocument, label string, party InvoiceParty) {
+	doc.Heading("%s", label)
+	doc.Line("%s", partyName(party))
+	for _, line := range strings.Split(party.Address, "\n") {
+		if strings.TrimSpace(line) != "" {
+			doc.Line("%s", strings.TrimSpace(line))
+		}
+	}
+	if party.Country != "" {
+		doc.Line("%s", party.Country)
+	}
+	if party.Email != "" {
+		doc.Line("%s", party.Email)
+	}
+	if party.TaxID != "" {
+		doc.Line("Tax ID: %s", party.TaxID)
+	}
+	doc.Blank()
+}
+
+// renderInvoicePDF lays an invoice out as a PDF
+func renderInvoicePDF(invoice *Invoice) []byte {
+	doc := &PDFDocument{Title: "Invoice " + invoice.Number}
+	doc.Heading("INVOICE %s", invoice.Number)
+	doc.Line("Issued:   %s", invoice.IssuedAt.Format("2006-01-02"))
+	doc.Line("Due:      %s", invoice.DueAt.Format("2006-01-02"))
+	doc.Line("Campaign: %s", invoice.CampaignTitle)
+	doc.Blank()
+
+	writePartyLines(doc, "From", invoice.Seller)
+	writePartyLines(doc, "Bill to", invoice.Buyer)
+
+	doc.Heading("%-44s %5s %13s %13s", "Description", "Qty", "Unit", "Amount")
+	for _, line := range invoice.Lines {
+		doc.Line("%-44s %5d %13s %13s", truncate(line.Description, 44), line.Quantity, formatMoney(line.UnitAmount, invoice.Currency), formatMoney(line.Amount, invoice.Currency))
+	}
+	doc.Blank()
+	doc.Line("%63s %13s", "Subtotal", formatMoney(invoice.Subtotal, invoice.Currency))
+	if invoice.TaxRate > 0 {
+		label := fmt.Sprintf("%s (%s%%)", invoice.TaxLabel, strconv.FormatFloat(invoice.TaxRate, 'f', -1, 64))
+		doc.Line("%63s %13s", label, formatMoney(invoice.TaxAmount, invoice.Currency))
+	}
+	doc.Heading("%63s %13s", "Total "+invoice.Currency, formatMoney(invoice.Total, invoice.Currency))
+
+	if invoice.Notes != "" {
+		doc.Blank()
+		doc.Heading("Notes")
+		for _, line := range strings.Split(invoice.Notes, "\n") {
+			doc.Line("%s", line)
+		}
+	}
+	return doc.Bytes()
+}
+
+// Handlers
+
+// createInvoiceHandler issues an invoice for an approved application, a
+// published milestone or a bonus. Invoicing the same item again returns the
+// existing invoice.
+func createInvoiceHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ApplicationID string `json:"applicationId"`
+		DeliverableID string `json:"deliverableId"` // Invoice a milestone
+		TransactionID string `json:"transactionId"` // Invoice a specific ledger transaction, e.g. a bonus
+		TaxLabel      string `json:"taxLabel"`
+		TaxRate       string `json:"taxRate"` // Percent, e.g. "18"
+		DueDays       int    `json:"dueDays"`
+		Notes         string `json:"notes"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	appObjID, err := primitive.ObjectIDFromHex(req.ApplicationID)
+	if err != nil {
+		http.Error(w, "Invalid application ID", http.StatusBadRequest)
+		return
+	}
+	var deliverableOID, transactionOID primitive.ObjectID
+	if req.DeliverableID != "" {
+		if deliverableOID, err = primitive.ObjectIDFromHex(req.DeliverableID); err != nil {
+			http.Error(w, "Invalid deliverable ID", http.StatusBadRequest)
+			return
+		}
+	}
+	if req.TransactionID != "" {
+		if transactionOID, err = primitive.ObjectIDFromHex(req.TransactionID); err != nil {
+			http.Error(w, "Invalid transaction ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	taxRate, err := parseTaxRate(req.TaxRate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.TaxLabel == "" {
+		req.TaxLabel = "Tax"
+	}
+	if req.DueDays <= 0 {
+		req.DueDays = defaultInvoiceDueDays
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	application, campaign, status, msg := loadApplicationForParticipant(ctx, appObjID, getUserIDFromClerkUser(user))
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	tx, status, msg := findInvoiceSource(ctx, application, transactionOID, deliverableOID)
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	// Invoicing the same item twice returns the original invoice
+	var existing Invoice
+	err = database.Collection("invoices").FindOne(ctx, bson.M{"sourceTransactionId": tx.ID}).Decode(&existing)
+	if err == nil {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(existing)
+		return
+	}
+	if err != mongo.ErrNoDocuments {
+		http.Error(w, "Error fetching invoice", http.StatusInternalServerError)
+		return
+	}
+
+	kind := invoiceKinds[tx.Kind]
+	if kind == InvoiceKindMilestone && deliverableOID.IsZero() {
+		deliverableOID, _ = primitive.ObjectIDFromHex(strings.TrimPrefix(tx.IdempotencyKey, "milestone:"))
+	}
+	if status, msg := checkInvoiceOverlap(ctx, application.ID, kind); status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	now := time.Now()
+	taxAmount := int64(math.Round(float64(tx.Amount) * taxRate / 100))
+	invoice := Invoice{
+		ID:                  primitive.NewObjectID(),
+		Kind:                kind,
+		SourceTransactionID: tx.ID,
+		ApplicationID:       application.ID,
+		CampaignID:          campaign.ID,
+		DeliverableID:       deliverableOID,
+		BrandID:             campaign.BrandID,
+		CreatorID:           application.CreatorID,
+		CampaignTitle:       campaign.Title,
+		Seller:              invoiceParty(ctx, application.CreatorID, application.CreatorName, application.CreatorEmail),
+		Buyer:               invoiceParty(ctx, campaign.BrandID, campaign.BrandName, ""),
+		Currency:            tx.Currency,
+		Lines: []InvoiceLine{{
+			Description: tx.Description,
+			Quantity:    1,
+			UnitAmount:  tx.Amount,
+			Amount:      tx.Amount,
+		}},
+		Subtotal:  tx.Amount,
+		TaxLabel:  req.TaxLabel,
+		TaxRate:   taxRate,
+		TaxAmount: taxAmount,
+		Total:     tx.Amount + taxAmount,
+		Notes:     req.Notes,
+		IssuedAt:  now,
+		DueAt:     now.AddDate(0, 0, req.DueDays),
+		CreatedAt: now,
+	}
+
+	// Number and insert together so a failed insert does not use up a number
+	err = withTransaction(ctx, func(sc mongo.SessionContext) error {
+		sequence, err := nextInvoiceSequence(sc, campaign.BrandID)
+		if err != nil {
+			return err
+		}
+		invoice.Sequence = sequence
+		invoice.Number = invoiceNumber(sequence)
+		_, err = database.Collection("invoices").InsertOne(sc, invoice)
+		return err
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		http.Error(w, "This item was invoiced concurrently; fetch it from /api/invoices", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Error creating invoice", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(invoice)
+}
+
+// getInvoicesHandler lists the signed-in user's invoices as JSON, or as CSV with ?format=csv
+func getInvoicesHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	userID := getUserIDFromClerkUser(user)
+	filter := bson.M{"creatorId": userID}
+	if getUserTypeFromClerkUser(user) == "brand" {
+		filter = bson.M{"brandId": userID}
+	}
+
+	query := r.URL.Query()
+	if campaignId := query.Get("campaignId"); campaignId != "" {
+		campaignOID, err := primitive.ObjectIDFromHex(campaignId)
+		if err != nil {
+			http.Error(w, "Invalid campaign ID", http.StatusBadRequest)
+			return
+		}
+		filter["campaignId"] = campaignOID
+	}
+	if applicationId := query.Get("applicationId"); applicationId != "" {
+		appObjID, err := primitive.ObjectIDFromHex(applicationId)
+		if err != nil {
+			http.Error(w, "Invalid application ID", http.StatusBadRequest)
+			return
+		}
+		filter["applicationId"] = appObjID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "issuedAt", Value: -1}})
+	cursor, err := database.Collection("invoices").Find(ctx, filter, opts)
+	if err != nil {
+		http.Error(w, "Error fetching invoices", http.StatusInternalServerError)
+		return
+	}
+	invoices := []Invoice{}
+	if err = cursor.All(ctx, &invoices); err != nil {
+		http.Error(w, "Error decoding invoices", http.StatusInternalServerError)
+		return
+	}
+
+	if query.Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="invoices.csv"`)
+		writer := csv.NewWriter(w)
+		writer.Write(invoiceCSVHeader)
+		for i := range invoices {
+			writer.Write(invoiceCSVRow(&invoices[i]))
+		}
+		writer.Flush()
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(invoices)
+}
+
+// getInvoiceHandler returns one invoice as JSON
+func getInvoiceHandler(w http.ResponseWriter, r *http.Request) {
+	serveInvoice(w, r, "json")
+}
+
+// getInvoicePDFHandler renders one invoice as a PDF
+func getInvoicePDFHandler(w http.ResponseWriter, r *http.Request) {
+	serveInvoice(w, r, "pdf")
+}
+
+// getInvoiceCSVHandler renders one invoice's line items as CSV
+func getInvoiceCSVHandler(w http.ResponseWriter, r *http.Request) {
+	serveInvoice(w, r, "csv")
+}
+
+// serveInvoice loads the invoice named in the URL and writes it in format
+func serveInvoice(w http.ResponseWriter, r *http.Request, format string) {
+	vars := mux.Vars(r)
+	invoiceId := vars["invoiceId"]
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	invoice, status, msg := loadInvoiceForParticipant(ctx, invoiceId, getUserIDFromClerkUser(user))
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	switch format {
+	case "pdf":
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s.pdf"`, invoice.Number))
+		w.Write(renderInvoicePDF(invoice))
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, invoice.Number))
+		writer := csv.NewWriter(w)
+		writer.Write(invoiceLinesCSVHeader)
+		writer.WriteAll(invoiceLinesCSVRows(invoice))
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(invoice)
+	}
+}
+
+// StatementTotals sums a statement per currency, in minor units
+type StatementTotals struct {
+	Currency string `json:"currency"`
+	Invoiced int64  `json:"invoiced"`
+	Paid     int64  `json:"paid"`
+}
+
+// statementTotals sums the invoices and paid payouts of a statement per
+// currency, in the order each currency first appears
+func statementTotals(invoices []Invoice, payouts []Payout) []StatementTotals {
+	totals := []StatementTotals{}
+	index := make(map[string]int)
+	total := func(currency string) *StatementTotals {
+		i, ok := index[currency]
+		if !ok {
+			i = len(totals)
+			index[currency] = i
+			totals = append(totals, StatementTotals{Currency: currency})
+		}
+		return &totals[i]
+	}
+	for _, invoice := range invoices {
+		total(invoice.Currency).Invoiced += invoice.Total
+	}
+	for _, payout := range payouts {
+		if payout.Status == PayoutStatusPaid {
+			total(payout.Currency).Paid += payout.Amount
+		}
+	}
+	return totals
+}
+
+// getStatementHandler returns the invoices and payouts of a period for the
+// signed-in brand or creator, as JSON, CSV (?format=csv) or PDF (?format=pdf)
+func getStatementHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	userID := getUserIDFromClerkUser(user)
+	filter := bson.M{"creatorId": userID}
+	if getUserTypeFromClerkUser(user) == "brand" {
+		filter = bson.M{"brandId": userID}
+	}
+
+	// Default to the current calendar month
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	query := r.URL.Query()
+	if s := query.Get("from"); s != "" {
+		parsed, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			http.Error(w, "Invalid from date, use YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		from = parsed
+		if query.Get("to") == "" {
+			to = from.AddDate(0, 1, 0)
+		}
+	}
+	if s := query.Get("to"); s != "" {
+		parsed, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			http.Error(w, "Invalid to date, use YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		to = parsed.AddDate(0, 0, 1) // Inclusive
+	}
+	if !to.After(from) {
+		http.Error(w, "to must not be before from", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	invoiceFilter := bson.M{"issuedAt": bson.M{"$gte": from, "$lt": to}}
+	payoutFilter := bson.M{"createdAt": bson.M{"$gte": from, "$lt": to}}
+	for key, value := range filter {
+		invoiceFilter[key] = value
+		payoutFilter[key] = value
+	}
+
+	invoices := []Invoice{}
+	cursor, err := database.Collection("invoices").Find(ctx, invoiceFilter, options.Find().SetSort(bson.D{{Key: "issuedAt", Value: 1}}))
+	if err == nil {
+		err = cursor.All(ctx, &invoices)
+	}
+	if err != nil {
+		http.Error(w, "Error fetching invoices", http.StatusInternalServerError)
+		return
+	}
+
+	payouts := []Payout{}
+	cursor, err = database.Collection("payouts").Find(ctx, payoutFilter, options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err == nil {
+		err = cursor.All(ctx, &payouts)
+	}
+	if err != nil {
+		http.Error(w, "Error fetching payouts", http.StatusInternalServerError)
+		return
+	}
+
+	totals := statementTotals(invoices, payouts)
+
+	period := fmt.Sprintf("%s to %s", from.Format("2006-01-02"), to.AddDate(0, 0, -1).Format("2006-01-02"))
+	filename := fmt.Sprintf("statement-%s-%s", from.Format("20060102"), to.AddDate(0, 0, -1).Format("20060102"))
+
+	switch query.Get("format") {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+		writer := csv.NewWriter(w)
+		writer.Write([]string{"date", "type", "reference", "campaign_or_application", "status", "currency", "amount"})
+		for _, invoice := range invoices {
+			writer.Write([]string{invoice.IssuedAt.Format("2006-01-02"), "invoice", invoice.Number,
+				spreadsheetSafe(invoice.CampaignTitle), "issued", invoice.Currency, formatMoney(invoice.Total, invoice.Currency)})
+		}
+		for _, payout := range payouts {
+			writer.Write([]string{payout.CreatedAt.Format("2006-01-02"), "payout", spreadsheetSafe(payout.ProviderRef),
+				payout.ApplicationID.Hex(), payout.Status, payout.Currency, formatMoney(payout.Amount, payout.Currency)})
+		}
+		writer.Flush()
+
+	case "pdf":
+		doc := &PDFDocument{Title: "Statement " + period}
+		doc.Heading("STATEMENT %s", period)
+		doc.Line("Account: %s", userID)
+		doc.Blank()
+		doc.Heading("Invoices")
+		if len(invoices) == 0 {
+			doc.Line("None")
+		}
+		for _, invoice := range invoices {
+			doc.Line("%s  %-10s %-38s %s %13s", invoice.IssuedAt.Format("2006-01-02"), invoice.Number,
+				truncate(invoice.CampaignTitle, 38), invoice.Currency, formatMoney(invoice.Total, invoice.Currency))
+		}
+		doc.Blank()
+		doc.Heading("Payouts")
+		if len(payouts) == 0 {
+			doc.Line("None")
+		}
+		for _, payout := range payouts {
+			doc.Line("%s  %-24s %-9s %s %13s", payout.CreatedAt.Format("2006-01-02"), truncate(payout.ProviderRef, 24),
+				payout.Status, payout.Currency, formatMoney(payout.Amount, payout.Currency))
+		}
+		doc.Blank()
+		doc.Heading("Totals")
+		for _, t := range totals {
+			doc.Line("%s  invoiced %13s  paid %13s", t.Currency, formatMoney(t.Invoiced, t.Currency), formatMoney(t.Paid, t.Currency))
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%s.pdf"`, filename))
+		w.Write(doc.Bytes())
+
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"from":     from.Format("2006-01-02"),
+			"to":       to.AddDate(0, 0, -1).Format("2006-01-02"),
+			"invoices": invoices,
+			"payouts":  payouts,
+			"totals":   totals,
+		})
+	}
+}
+
+// truncate shortens s to at most n characters for fixed-width layouts
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-3]) + "..."
+}
+
+// updateBillingDetailsHandler saves the legal and tax details printed on the user's invoices
+func updateBillingDetailsHandler(w http.ResponseWriter, r *http.Request) {
+	var req BillingDetails
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := database.Collection("users").UpdateOne(ctx,
+		bson.M{"clerkId": getUserIDFromClerkUser(user)},
+		bson.M{"$set": bson.M{"billing": req, "updatedAt": time.Now()}},
+	)
+	if err != nil {
+		http.Error(w, "Error updating user", http.StatusInternalServerError)
+		return
+	}
+	if result.MatchedCount == 0 {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(req)
+}
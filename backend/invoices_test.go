@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// useTestDatabase points the package at a scratch database on the MongoDB
+// in MONGODB_TEST_URI, skipping the test when it is not set
+func useTestDatabase(t *testing.T) {
+	uri := os.Getenv("MONGODB_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGODB_TEST_URI is not set")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	testClient, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := testClient.Ping(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	savedClient, savedDatabase := client, database
+	client, database = testClient, testClient.Database("test_"+primitive.NewObjectID().Hex())
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		database.Drop(ctx)
+		testClient.Disconnect(ctx)
+		client, database = savedClient, savedDatabase
+	})
+}
+
+func TestInvoiceNumber(t *testing.T) {
+	for sequence, want := range map[int64]string{1: "INV-00001", 42: "INV-00042", 123456: "INV-123456"} {
+		if got := invoiceNumber(sequence); got != want {
+			t.Errorf("invoiceNumber(%d) = %q, want %q", sequence, got, want)
+		}
+	}
+}
+
+func TestNextInvoiceSequence(t *testing.T) {
+	useTestDatabase(t)
+	ctx := context.Background()
+
+	// Each brand numbers its invoices from 1 without gaps
+	for _, step := range []struct {
+		brandID string
+		want    int64
+	}{
+		{"brand_a", 1}, {"brand_a", 2}, {"brand_b", 1}, {"brand_a", 3}, {"brand_b", 2},
+	} {
+		got, err := nextInvoiceSequence(ctx, step.brandID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != step.want {
+			t.Errorf("%s: sequence = %d, want %d", step.brandID, got, step.want)
+		}
+	}
+}
+
+func TestStatementTotals(t *testing.T) {
+	invoices := []Invoice{
+		{Currency: "USD", Total: 120000},
+		{Currency: "EUR", Total: 5000},
+		{Currency: "USD", Total: 3050},
+	}
+	payouts := []Payout{
+		{Currency: "USD", Amount: 100000, Status: PayoutStatusPaid},
+		{Currency: "USD", Amount: 20000, Status: PayoutStatusPending},
+		{Currency: "EUR", Amount: 5000, Status: PayoutStatusFailed},
+		{Currency: "JPY", Amount: 1500, Status: PayoutStatusPaid},
+	}
+
+	want := []StatementTotals{
+		{Currency: "USD", Invoiced: 123050, Paid: 100000},
+		{Currency: "EUR", Invoiced: 5000},
+		{Currency: "JPY", Paid: 1500},
+	}
+	if got := statementTotals(invoices, payouts); !reflect.DeepEqual(got, want) {
+		t.Errorf("statementTotals = %+v, want %+v", got, want)
+	}
+	if got := statementTotals(nil, nil); got == nil || len(got) != 0 {
+		t.Errorf("empty statement totals = %#v, want an empty list", got)
+	}
+}
+
+// testInvoice returns an invoice whose text fields hold formulas and non-ASCII names
+func testInvoice() *Invoice {
+	issued := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	return &Invoice{
+		Number:        "INV-00042",
+		Sequence:      42,
+		Kind:          InvoiceKindApplication,
+		CampaignTitle: "=HYPERLINK(\"http://evil.example\")",
+		Seller:        InvoiceParty{Name: "Zoë Müller", Address: "Straße 1\n10115 Berlin", Country: "DE", TaxID: "DE123"},
+		Buyer:         InvoiceParty{Name: "Acme", LegalName: "@Acme (Holdings) Ltd"},
+		Currency:      "EUR",
+		Lines: []InvoiceLine{{
+			Description: "-Fee for Café launch – spring",
+			Quantity:    1,
+			UnitAmount:  150000,
+			Amount:      150000,
+		}},
+		Subtotal:  150000,
+		TaxLabel:  "+VAT",
+		TaxRate:   19,
+		TaxAmount: 28500,
+		Total:     178500,
+		Notes:     "Payable within 30 days €",
+		IssuedAt:  issued,
+		DueAt:     issued.AddDate(0, 0, 30),
+	}
+}
+
+func TestInvoiceCSVRowEscapesFormulas(t *testing.T) {
+	row := invoiceCSVRow(testInvoice())
+	if len(row) != len(invoiceCSVHeader) {
+		t.Fatalf("row has %d columns, header %d", len(row), len(invoiceCSVHeader))
+	}
+	for column, want := range map[int]string{
+		4:  `'=HYPERLINK("http://evil.example")`,
+		7:  "'@Acme (Holdings) Ltd",
+		11: "'+VAT",
+		10: "1500.00",
+	} {
+		if row[column] != want {
+			t.Errorf("%s = %q, want %q", invoiceCSVHeader[column], row[column], want)
+		}
+	}
+
+	rows := invoiceLinesCSVRows(testInvoice())
+	if len(rows) != 4 {
+		t.Fatalf("got %d line rows, want 4", len(rows))
+	}
+	if rows[0][1] != "'-Fee for Café launch – spring" || rows[2][1] != "'+VAT" {
+		t.Errorf("line rows not escaped: %q", rows)
+	}
+	if rows[3][4] != "1785.00" {
+		t.Errorf("total = %q, want 1785.00", rows[3][4])
+	}
+}
+
+func TestRenderInvoicePDF(t *testing.T) {
+	pdf := renderInvoicePDF(testInvoice())
+
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatal("output is not framed as a PDF")
+	}
+	for _, want := range []string{
+		"(INVOICE INV-00042) Tj",
+		`(Zo\353 M\374ller) Tj`,
+		`(Stra\337e 1) Tj`,
+		`(@Acme \(Holdings\) Ltd) Tj`,
+		`-Fee for Caf\351 launch \226 spring`,
+		"1500.00",
+		`+VAT \(19%\)`,
+		"1785.00",
+		`(Payable within 30 days \200) Tj`,
+		"/Title (Invoice INV-00042)",
+	} {
+		if !bytes.Contains(pdf, []byte(want)) {
+			t.Errorf("PDF does not contain %q", want)
+		}
+	}
+	if bytes.Contains(pdf, []byte("Zo?")) {
+		t.Error("Latin-1 text was replaced with '?'")
+	}
+
+	// Every xref entry must point at the start of its object
+	xref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+	if xref == nil {
+		t.Fatal("no startxref")
+	}
+	start, _ := strconv.Atoi(string(xref[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[start:], -1)
+	if len(entries) == 0 {
+		t.Fatal("no xref entries")
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(pdf[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i+1, pdf[offset:offset+10])
+		}
+	}
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Job states
+const (
+	JobStatusPending = "pending"
+	JobStatusRunning = "running"
+	JobStatusDone    = "done"
+	JobStatusFailed  = "failed"
+)
+
+// Job is a unit of background work persisted in MongoDB
+type Job struct {
+	ID             primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	Type           string                 `bson:"type" json:"type"`
+	Key            string                 `bson:"key" json:"key"` // Unique key so the same job is only enqueued once
+	Payload        map[string]interface{} `bson:"payload" json:"payload"`
+	RunAt          time.Time              `bson:"runAt" json:"runAt"`
+	Status         string                 `bson:"status" json:"status"`
+	Attempts       int                    `bson:"attempts" json:"attempts"`
+	MaxAttempts    int                    `bson:"maxAttempts" json:"maxAttempts"`
+	LeaseOwner     string                 `bson:"leaseOwner,omitempty" json:"leaseOwner,omitempty"`
+	LeaseExpiresAt *time.Time             `bson:"leaseExpiresAt,omitempty" json:"leaseExpiresAt,omitempty"`
+	LastError      string                 `bson:"lastError,omitempty" json:"lastError,omitempty"`
+	CreatedAt      time.Time              `bson:"createdAt" json:"createdAt"`
+	UpdatedAt      time.Time              `bson:"updatedAt" json:"updatedAt"`
+}
+
+// JobHandler runs a job. Returning an error schedules a retry.
+type JobHandler func(ctx context.Context, job *Job) error
+
+// JobQueue is a Mongo-backed job queue. Workers lease jobs before running
+// them so that several replicas can poll the same collection without
+// running a job twice; a lease that is not completed in time expires and
+// the job becomes available again.
+type JobQueue struct {
+	collection    *mongo.Collection
+	owner         string
+	handlers      map[string]JobHandler
+	leaseDuration time.Duration
+	pollInterval  time.Duration
+}
+
+// jobQueue is the process-wide job queue, set up by initJobQueue
+var jobQueue *JobQueue
+
+// defaultJobMaxAttempts is how many times a job runs before it is marked failed
+const defaultJobMaxAttempts = 5
+
+// NewJobQueue creates a job queue on the given collection
+func NewJobQueue(collection *mongo.Collection, leaseDuration, pollInterval time.Duration) *JobQueue {
+	hostname, _ := os.Hostname()
+	return &JobQueue{
+		collection:    collection,
+		owner:         fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), primitive.NewObjectID().Hex()),
+		handlers:      make(map[string]JobHandler),
+		leaseDuration: leaseDuration,
+		pollInterval:  pollInterval,
+	}
+}
+
+// initJobQueue creates the process-wide queue, its indexes and registers job handlers
+func initJobQueue() error {
+	jobQueue = NewJobQueue(database.Collection("jobs"), 2*time.Minute, 5*time.Second)
+	if err := jobQueue.EnsureIndexes(context.Background()); err != nil {
+		return err
+	}
+
+	jobQueue.Register(JobCampaignDeadlineReminder, runCampaignDeadlineReminder)
+	jobQueue.Register(JobDeliverableReminder, runDeliverableReminder)
+	return nil
+}
+
+// EnsureIndexes creates the indexes the queue relies on
+func (q *JobQueue) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	_, err := q.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "key", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "runAt", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create job indexes: %w", err)
+	}
+	return nil
+}
+
+// Register sets the handler for a job type
+func (q *JobQueue) Register(jobType string, handler JobHandler) {
+	q.handlers[jobType] = handler
+}
+
+// Enqueue schedules a job to run at runAt. Enqueuing a job whose key
+// already exists is a no-op, which makes it safe to call repeatedly.
+func (q *JobQueue) Enqueue(ctx context.Context, jobType, key string, runAt time.Time, payload map[string]interface{}) error {
+	now := time.Now()
+	_, err := q.collection.UpdateOne(ctx,
+		bson.M{"key": key},
+		bson.M{"$setOnInsert": Job{
+			ID:          primitive.NewObjectID(),
+			Type:        jobType,
+			Key:         key,
+			Payload:     payload,
+			RunAt:       runAt,
+			Status:      JobStatusPending,
+			MaxAttempts: defaultJobMaxAttempts,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
+
+// Cancel deletes the pending jobs of a type whose payload field is one of
+// values. Enqueuing the same keys again later schedules them afresh.
+func (q *JobQueue) Cancel(ctx context.Context, jobType, field string, values []string) error {
+	_, err := q.collection.DeleteMany(ctx, bson.M{
+		"type":             jobType,
+		"status":           JobStatusPending,
+		"payload." + field: bson.M{"$in": values},
+	})
+	return err
+}
+
+// Start polls for due jobs until ctx is cancelled. The returned channel is
+// closed once the worker has stopped, after any job in progress finishes.
+// A job in progress keeps running after ctx is cancelled until it ends, its
+// lease runs out or drain is cancelled, whichever comes first.
+func (q *JobQueue) Start(ctx, drain context.Context) <-chan struct{} {
+	done := make(chan struct{})
+	// A single job may run for its whole lease
+	heartbeat := registerHeartbeat("jobQueue", q.leaseDuration+2*q.pollInterval)
+
+	go func() {
+		defer close(done)
+		defer heartbeat.Stop()
+
+		ticker := time.NewTicker(q.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			// Drain every due job before waiting for the next tick
+			for ctx.Err() == nil {
+				heartbeat.Beat()
+				ran, err := q.runNext(ctx, drain)
+				if err != nil {
+					log.Println("Job queue: error claiming job:", err)
+					break
+				}
+				if !ran {
+					break
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return done
+}
+
+// runNext leases and runs one due job, reporting whether a job was found
+func (q *JobQueue) runNext(ctx, drain context.Context) (bool, error) {
+	now := time.Now()
+	leaseExpiresAt := now.Add(q.leaseDuration)
+
+	var job Job
+	err := q.collection.FindOneAndUpdate(ctx,
+		bson.M{
+			"runAt": bson.M{"$lte": now},
+			"$or": []bson.M{
+				{"status": JobStatusPending},
+				{"status": JobStatusRunning, "leaseExpiresAt": bson.M{"$lte": now}},
+			},
+		},
+		bson.M{
+			"$set": bson.M{
+				"status":         JobStatusRunning,
+				"leaseOwner":     q.owner,
+				"leaseExpiresAt": leaseExpiresAt,
+				"updatedAt":      now,
+			},
+			"$inc": bson.M{"attempts": 1},
+		},
+		options.FindOneAndUpdate().
+			SetSort(bson.D{{Key: "runAt", Value: 1}}).
+			SetReturnDocument(options.After),
+	).Decode(&job)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	handler, ok := q.handlers[job.Type]
+	if !ok {
+		q.finish(ctx, &job, fmt.Errorf("no handler registered for job type %q", job.Type))
+		return true, nil
+	}
+
+	// A job that has started is not cut off when the queue stops polling, so
+	// shutdown drains it, but it must end by the shutdown deadline (drain)
+	jobCtx, cancel := context.WithDeadline(drain, leaseExpiresAt)
+	err = handler(jobCtx, &job)
+	cancel()
+
+	q.finish(context.WithoutCancel(ctx), &job, err)
+	return true, nil
+}
+
+// finish records the outcome of a job, scheduling a retry with backoff on failure
+func (q *JobQueue) finish(ctx context.Context, job *Job, runErr error) {
+	now := time.Now()
+	set := bson.M{"updatedAt": now}
+	unset := bson.M{"leaseOwner": "", "leaseExpiresAt": ""}
+
+	switch {
+	case runErr == nil:
+		set["status"] = JobStatusDone
+		set["lastError"] = ""
+	case job.Attempts >= job.MaxAttempts:
+		log.Printf("Job queue: job %s (%s) failed permanently: %v", job.Key, job.Type, runErr)
+		set["status"] = JobStatusFailed
+		set["lastError"] = runErr.Error()
+	default:
+		log.Printf("Job queue: job %s (%s) failed, will retry: %v", job.Key, job.Type, runErr)
+		set["status"] = JobStatusPending
+		set["lastError"] = runErr.Error()
+		set["runAt"] = now.Add(time.Duration(job.Attempts*job.Attempts) * time.Minute)
+	}
+
+	// Only the lease holder may complete the job
+	_, err := q.collection.UpdateOne(ctx,
+		bson.M{"_id": job.ID, "leaseOwner": q.owner},
+		bson.M{"$set": set, "$unset": unset},
+	)
+	if err != nil {
+		log.Printf("Job queue: error updating job %s: %v", job.Key, err)
+	}
+}
+
+// Deadline reminders
+
+// JobCampaignDeadlineReminder reminds participants that a campaign's EndDate is near
+const JobCampaignDeadlineReminder = "campaign.deadline_reminder"
+
+// reminderDaysBefore lists how many days before a deadline reminders are sent
+var reminderDaysBefore = []int{7, 1}
+
+// reminderHour is the local hour (in the campaign's time zone) reminders go out
+const reminderHour = 9
+
+// reminderTimes returns when reminders for a deadline on the given local
+// day should run, skipping any that are already in the past
+func reminderTimes(deadline time.Time, now time.Time) map[int]time.Time {
+	times := make(map[int]time.Time)
+	for _, days := range reminderDaysBefore {
+		day := deadline.AddDate(0, 0, -days)
+		runAt := time.Date(day.Year(), day.Month(), day.Day(), reminderHour, 0, 0, 0, deadline.Location())
+		if runAt.After(now) {
+			times[days] = runAt
+		}
+	}
+	return times
+}
+
+// scheduleCampaignReminders enqueues the deadline reminders for a campaign
+// with DeadlineReminders enabled. Reminders are keyed on the EndDate so a
+// changed EndDate gets a fresh set and stale jobs are skipped when they run.
+func scheduleCampaignReminders(ctx context.Context, campaign *Campaign) {
+	if jobQueue == nil || !campaign.DeadlineReminders {
+		return
+	}
+	if campaign.Status != CampaignStatusScheduled && campaign.Status != CampaignStatusActive {
+		return
+	}
+
+	deadline, err := time.ParseInLocation(campaignDateLayout, campaign.EndDate, campaignLocation(campaign.TimeZone))
+	if err != nil {
+		return
+	}
+
+	for days, runAt := range reminderTimes(deadline, time.Now()) {
+		key := fmt.Sprintf("%s:%s:%s:%d", JobCampaignDeadlineReminder, campaign.ID.Hex(), campaign.EndDate, days)
+		err := jobQueue.Enqueue(ctx, JobCampaignDeadlineReminder, key, runAt, map[string]interface{}{
+			"campaignId": campaign.ID.Hex(),
+			"endDate":    campaign.EndDate,
+			"daysBefore": days,
+		})
+		if err != nil {
+			log.Printf("Error scheduling reminder for campaign %s: %v", campaign.ID.Hex(), err)
+		}
+	}
+}
+
+// runCampaignDeadlineReminder sends a campaign deadline reminder if it still applies
+func runCampaignDeadlineReminder(ctx context.Context, job *Job) error {
+	campaignID, _ := job.Payload["campaignId"].(string)
+	campaignOID, err := primitive.ObjectIDFromHex(campaignID)
+	if err != nil {
+		return nil // Malformed payload, nothing to retry
+	}
+
+	var campaign Campaign
+	err = database.Collection("campaigns").FindOne(ctx, bson.M{"_id": campaignOID}).Decode(&campaign)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Skip reminders that no longer apply
+	if !campaign.DeadlineReminders || campaign.EndDate != job.Payload["endDate"] {
+		return nil
+	}
+	if campaign.Status != CampaignStatusActive && campaign.Status != CampaignStatusScheduled {
+		return nil
+	}
+
+	return notifyDeadlineApproaching(ctx, job.Key, &campaign)
+}
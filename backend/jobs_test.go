@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReminderTimes(t *testing.T) {
+	kolkata := time.FixedZone("IST", 5*3600+30*60)
+	deadline := time.Date(2024, 6, 20, 0, 0, 0, 0, kolkata)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want map[int]time.Time
+	}{
+		{
+			name: "both reminders ahead",
+			now:  time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			want: map[int]time.Time{
+				7: time.Date(2024, 6, 13, 9, 0, 0, 0, kolkata),
+				1: time.Date(2024, 6, 19, 9, 0, 0, 0, kolkata),
+			},
+		},
+		{
+			name: "week reminder already passed",
+			now:  time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC),
+			want: map[int]time.Time{
+				1: time.Date(2024, 6, 19, 9, 0, 0, 0, kolkata),
+			},
+		},
+		{
+			name: "exactly at reminder time is skipped",
+			now:  time.Date(2024, 6, 19, 9, 0, 0, 0, kolkata),
+			want: map[int]time.Time{},
+		},
+		{
+			name: "deadline passed",
+			now:  time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC),
+			want: map[int]time.Time{},
+		},
+	}
+	for _, tt := range tests {
+		got := reminderTimes(deadline, tt.now)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for days, want := range tt.want {
+			if !got[days].Equal(want) {
+				t.Errorf("%s: %d days before = %v, want %v", tt.name, days, got[days], want)
+			}
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Campaign lifecycle states
+const (
+	CampaignStatusDraft     = "draft"
+	CampaignStatusScheduled = "scheduled"
+	CampaignStatusActive    = "active"
+	CampaignStatusPaused    = "paused"
+	CampaignStatusCompleted = "completed"
+	CampaignStatusCancelled = "cancelled"
+)
+
+// campaignDateLayout is the format the frontend sends for StartDate and EndDate
+const campaignDateLayout = "2006-01-02"
+
+// campaignTransitions lists the states each campaign state may move to
+var campaignTransitions = map[string][]string{
+	CampaignStatusDraft:     {CampaignStatusScheduled, CampaignStatusActive, CampaignStatusCancelled},
+	CampaignStatusScheduled: {CampaignStatusDraft, CampaignStatusActive, CampaignStatusCancelled},
+	CampaignStatusActive:    {CampaignStatusPaused, CampaignStatusCompleted, CampaignStatusCancelled},
+	CampaignStatusPaused:    {CampaignStatusActive, CampaignStatusCompleted, CampaignStatusCancelled},
+	CampaignStatusCompleted: {},
+	CampaignStatusCancelled: {},
+}
+
+// isValidCampaignStatus reports whether status is a known lifecycle state
+func isValidCampaignStatus(status string) bool {
+	_, ok := campaignTransitions[status]
+	return ok
+}
+
+// canTransitionCampaign reports whether the state machine allows from -> to
+func canTransitionCampaign(from, to string) bool {
+	for _, next := range campaignTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// utcOffsetPattern matches the "(UTC+5:30)" style suffix used by the campaign form
+var utcOffsetPattern = regexp.MustCompile(`UTC\s*([+-])\s*(\d{1,2})(?::(\d{2}))?`)
+
+// campaignLocation resolves a campaign's TimeZone into a *time.Location.
+// It accepts IANA names ("Asia/Kolkata") as well as the labels offered by
+// the create campaign form ("IST (UTC+5:30)"), falling back to UTC.
+func campaignLocation(timeZone string) *time.Location {
+	tz := strings.TrimSpace(timeZone)
+	if tz == "" {
+		return time.UTC
+	}
+
+	if loc, err := time.LoadLocation(tz); err == nil {
+		return loc
+	}
+
+	if m := utcOffsetPattern.FindStringSubmatch(tz); m != nil {
+		hours, _ := strconv.Atoi(m[2])
+		minutes, _ := strconv.Atoi(m[3])
+		offset := hours*3600 + minutes*60
+		if m[1] == "-" {
+			offset = -offset
+		}
+		return time.FixedZone(tz, offset)
+	}
+
+	return time.UTC
+}
+
+// campaignStartTime returns the moment a campaign starts (midnight of StartDate in its time zone)
+func campaignStartTime(campaign *Campaign) (time.Time, error) {
+	return time.ParseInLocation(campaignDateLayout, campaign.StartDate, campaignLocation(campaign.TimeZone))
+}
+
+// campaignEndTime returns the moment a campaign ends (the end of EndDate in its time zone)
+func campaignEndTime(campaign *Campaign) (time.Time, error) {
+	end, err := time.ParseInLocation(campaignDateLayout, campaign.EndDate, campaignLocation(campaign.TimeZone))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return end.AddDate(0, 0, 1), nil
+}
+
+// validateCampaignTransition checks that campaign may move to the given state at time now
+func validateCampaignTransition(campaign *Campaign, to string, now time.Time) error {
+	if !isValidCampaignStatus(to) {
+		return fmt.Errorf("invalid status %q", to)
+	}
+
+	if !canTransitionCampaign(campaign.Status, to) {
+		return fmt.Errorf("cannot move campaign from %q to %q", campaign.Status, to)
+	}
+
+	switch to {
+	case CampaignStatusScheduled, CampaignStatusActive:
+		if strings.TrimSpace(campaign.Title) == "" {
+			return fmt.Errorf("campaign title is required")
+		}
+
+		start, err := campaignStartTime(campaign)
+		if err != nil {
+			return fmt.Errorf("campaign start date must be in YYYY-MM-DD format")
+		}
+		end, err := campaignEndTime(campaign)
+		if err != nil {
+			return fmt.Errorf("campaign end date must be in YYYY-MM-DD format")
+		}
+		if !end.After(start) {
+			return fmt.Errorf("campaign end date must not be before its start date")
+		}
+		if !now.Before(end) {
+			return fmt.Errorf("campaign end date has already passed")
+		}
+		if to == CampaignStatusScheduled && !now.Before(start) {
+			return fmt.Errorf("campaign start date has already passed, activate it instead")
+		}
+	}
+
+	return nil
+}
+
+// transitionCampaignHandler moves a campaign through its lifecycle (brand owner only)
+func transitionCampaignHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	campaignId := vars["campaignId"]
+
+	var req struct {
+		Status string `json:"status"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	// Convert campaign ID to ObjectID
+	campaignOID, err := primitive.ObjectIDFromHex(campaignId)
+	if err != nil {
+		http.Error(w, "Invalid campaign ID", http.StatusBadRequest)
+		return
+	}
+
+	collection := database.Collection("campaigns")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var campaign Campaign
+	err = collection.FindOne(ctx, bson.M{"_id": campaignOID}).Decode(&campaign)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			http.Error(w, "Campaign not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error fetching campaign", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// Check ownership
+	if campaign.BrandID != getUserIDFromClerkUser(user) {
+		http.Error(w, "Access denied: You can only update your own campaigns", http.StatusForbidden)
+		return
+	}
+
+	if err := validateCampaignTransition(&campaign, req.Status, time.Now()); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	// Only apply the change if nobody else moved the campaign in the meantime
+	now := time.Now()
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": campaignOID, "status": campaign.Status},
+		bson.M{"$set": bson.M{"status": req.Status, "updatedAt": now}},
+	)
+	if err != nil {
+		http.Error(w, "Error updating campaign", http.StatusInternalServerError)
+		return
+	}
+	if result.MatchedCount == 0 {
+		http.Error(w, "Campaign status changed concurrently, please retry", http.StatusConflict)
+		return
+	}
+
+	campaign.Status = req.Status
+	campaign.UpdatedAt = now
+	publishCampaignUpdated(ctx, &campaign)
+	scheduleCampaignReminders(ctx, &campaign)
+	if campaign.Status == CampaignStatusCompleted {
+		notifyCampaignEnded(ctx, &campaign)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(campaign)
+}
+
+// startCampaignScheduler periodically activates scheduled campaigns whose
+// StartDate has arrived and completes running campaigns whose EndDate has
+// passed. It stops when ctx is cancelled; the returned channel is closed
+// once it has.
+func startCampaignScheduler(ctx context.Context, interval time.Duration) <-chan struct{} {
+	done := make(chan struct{})
+	// A pass may take up to 30 seconds on top of the interval
+	heartbeat := registerHeartbeat("campaignScheduler", 2*interval+30*time.Second)
+
+	go func() {
+		defer close(done)
+		defer heartbeat.Stop()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			runCampaignScheduler(ctx, time.Now())
+			heartbeat.Beat()
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return done
+}
+
+// runCampaignScheduler performs a single pass of the campaign scheduler
+func runCampaignScheduler(parent context.Context, now time.Time) {
+	ctx, cancel := context.WithTimeout(parent, 30*time.Second)
+	defer cancel()
+
+	collection := database.Collection("campaigns")
+	cursor, err := collection.Find(ctx, bson.M{"status": bson.M{"$in": []string{
+		CampaignStatusScheduled, CampaignStatusActive, CampaignStatusPaused,
+	}}})
+	if err != nil {
+		log.Println("Campaign scheduler: error fetching campaigns:", err)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var campaign Campaign
+		if err := cursor.Decode(&campaign); err != nil {
+			log.Println("Campaign scheduler: error decoding campaign:", err)
+			continue
+		}
+
+		// Step through each state in turn, so a campaign whose whole run was
+		// missed still goes scheduled -> active -> completed
+		from := campaign.Status
+		for {
+			next := scheduledCampaignStatus(&campaign, now)
+			if next == campaign.Status {
+				break
+			}
+			if !canTransitionCampaign(campaign.Status, next) {
+				log.Printf("Campaign scheduler: refusing to move campaign %s from %s to %s", campaign.ID.Hex(), campaign.Status, next)
+				break
+			}
+
+			result, err := collection.UpdateOne(ctx,
+				bson.M{"_id": campaign.ID, "status": campaign.Status},
+				bson.M{"$set": bson.M{"status": next, "updatedAt": now}},
+			)
+			if err != nil {
+				log.Printf("Campaign scheduler: error moving campaign %s to %s: %v", campaign.ID.Hex(), next, err)
+				break
+			}
+			if result.MatchedCount == 0 {
+				break // Moved by someone else in the meantime
+			}
+
+			campaign.Status = next
+			campaign.UpdatedAt = now
+		}
+		if campaign.Status == from {
+			continue
+		}
+		log.Printf("Campaign scheduler: campaign %s moved from %s to %s", campaign.ID.Hex(), from, campaign.Status)
+
+		publishCampaignUpdated(ctx, &campaign)
+		scheduleCampaignReminders(ctx, &campaign)
+		if campaign.Status == CampaignStatusCompleted {
+			notifyCampaignEnded(ctx, &campaign)
+		}
+	}
+}
+
+// scheduledCampaignStatus returns the next state the scheduler should move
+// campaign to at time now, one transition at a time, or its current state
+func scheduledCampaignStatus(campaign *Campaign, now time.Time) string {
+	switch campaign.Status {
+	case CampaignStatusScheduled:
+		if start, err := campaignStartTime(campaign); err == nil && !now.Before(start) {
+			return CampaignStatusActive
+		}
+	case CampaignStatusActive, CampaignStatusPaused:
+		if end, err := campaignEndTime(campaign); err == nil && !now.Before(end) {
+			return CampaignStatusCompleted
+		}
+	}
+
+	return campaign.Status
+}
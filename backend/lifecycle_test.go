@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCanTransitionCampaign(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{CampaignStatusDraft, CampaignStatusScheduled, true},
+		{CampaignStatusDraft, CampaignStatusActive, true},
+		{CampaignStatusDraft, CampaignStatusCompleted, false},
+		{CampaignStatusScheduled, CampaignStatusActive, true},
+		{CampaignStatusScheduled, CampaignStatusCompleted, false},
+		{CampaignStatusActive, CampaignStatusPaused, true},
+		{CampaignStatusPaused, CampaignStatusActive, true},
+		{CampaignStatusActive, CampaignStatusCompleted, true},
+		{CampaignStatusCompleted, CampaignStatusActive, false},
+		{CampaignStatusCancelled, CampaignStatusDraft, false},
+		{"unknown", CampaignStatusActive, false},
+	}
+	for _, tt := range tests {
+		if got := canTransitionCampaign(tt.from, tt.to); got != tt.want {
+			t.Errorf("canTransitionCampaign(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestScheduledCampaignStatus(t *testing.T) {
+	now := time.Date(2024, 6, 15, 11, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		status   string
+		start    string
+		end      string
+		timeZone string
+		want     string
+	}{
+		{"scheduled before start", CampaignStatusScheduled, "2024-06-20", "2024-06-30", "", CampaignStatusScheduled},
+		{"scheduled at start", CampaignStatusScheduled, "2024-06-15", "2024-06-30", "", CampaignStatusActive},
+		{"scheduled past end steps to active first", CampaignStatusScheduled, "2024-06-01", "2024-06-10", "", CampaignStatusActive},
+		{"active before end", CampaignStatusActive, "2024-06-01", "2024-06-15", "", CampaignStatusActive},
+		{"active after end", CampaignStatusActive, "2024-06-01", "2024-06-14", "", CampaignStatusCompleted},
+		{"paused after end", CampaignStatusPaused, "2024-06-01", "2024-06-14", "", CampaignStatusCompleted},
+		{"end day still running west of UTC", CampaignStatusActive, "2024-06-01", "2024-06-14", "Etc/GMT+12", CampaignStatusActive},
+		{"start not reached east of UTC offset label", CampaignStatusScheduled, "2024-06-16", "2024-06-30", "IST (UTC+5:30)", CampaignStatusScheduled},
+		{"draft is left alone", CampaignStatusDraft, "2024-06-01", "2024-06-10", "", CampaignStatusDraft},
+		{"bad dates are left alone", CampaignStatusActive, "soon", "later", "", CampaignStatusActive},
+	}
+	for _, tt := range tests {
+		campaign := &Campaign{Status: tt.status, StartDate: tt.start, EndDate: tt.end, TimeZone: tt.timeZone}
+		got := scheduledCampaignStatus(campaign, now)
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+		if got != tt.status && !canTransitionCampaign(tt.status, got) {
+			t.Errorf("%s: %q -> %q is not an allowed transition", tt.name, tt.status, got)
+		}
+	}
+}
@@ -3,10 +3,13 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -21,6 +24,21 @@ func main() {
 		log.Println("No .env file found, using environment variables")
 	}
 
+	// `config print` shows the effective configuration and exits
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfigCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	// Load configuration: defaults, then the config file, then environment variables
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML config file")
+	flag.Parse()
+	config, err := loadConfig(*configPath)
+	if err != nil {
+		log.Fatal("Invalid configuration:\n", err)
+	}
+	appConfig = config
+	trustedProxies, _ = parseTrustedProxies(config.Server.TrustedProxies) // Checked by Config.Validate
+
 	// Initialize Clerk
 	if err := initializeClerk(); err != nil {
 		log.Fatal("Failed to initialize Clerk:", err)
@@ -28,64 +46,281 @@ func main() {
 
 	// Initialize MongoDB
 	initMongoDB()
-	defer closeMongoDB()
+
+	// Apply one-off data fixes
+	if err := runMigrations(); err != nil {
+		log.Fatal("Failed to run migrations:", err)
+	}
+
+	// Initialize notification delivery
+	if err := initNotifications(); err != nil {
+		log.Fatal("Failed to initialize notifications:", err)
+	}
+
+	// Initialize payments
+	if err := initPayments(); err != nil {
+		log.Fatal("Failed to initialize payments:", err)
+	}
+
+	// Initialize contracts
+	if err := initContracts(); err != nil {
+		log.Fatal("Failed to initialize contracts:", err)
+	}
+
+	// Initialize campaign templates
+	if err := initCampaignTemplates(); err != nil {
+		log.Fatal("Failed to initialize campaign templates:", err)
+	}
+
+	// Initialize exchange rates for reporting
+	if err := initRates(); err != nil {
+		log.Fatal("Failed to initialize exchange rates:", err)
+	}
+
+	// Initialize blob storage for uploads
+	if err := initStorage(); err != nil {
+		log.Fatal("Failed to initialize storage:", err)
+	}
+
+	// Initialize rate limiting
+	if err := initRateLimits(); err != nil {
+		log.Fatal("Failed to initialize rate limits:", err)
+	}
+
+	// Initialize the background job queue
+	if err := initJobQueue(); err != nil {
+		log.Fatal("Failed to initialize job queue:", err)
+	}
+
+	// Start background workers; they stop when the server shuts down
+	// Workers stop taking work when shutdown starts, and abandon what they are
+	// running once the shutdown deadline passes
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	drainCtx, stopDraining := context.WithCancel(context.Background())
+	schedulerDone := startCampaignScheduler(workerCtx, time.Minute)
+	jobsDone := jobQueue.Start(workerCtx, drainCtx)
 
 	router := mux.NewRouter()
 
+	// Health probes come before the API subrouter so the global rate limit
+	// never answers a load balancer or orchestrator with 429
+	router.HandleFunc("/api/health", healthCheck).Methods("GET")
+	router.HandleFunc("/api/health/live", livenessHandler).Methods("GET")
+	router.HandleFunc("/api/health/ready", readinessHandler).Methods("GET")
+
 	// API routes
 	api := router.PathPrefix("/api").Subrouter()
+	api.Use(rateLimitMiddleware("global"))
 
 	// Public routes
-	api.HandleFunc("/health", healthCheck).Methods("GET")
-	api.HandleFunc("/debug/applications", debugApplicationsHandler).Methods("GET") // Temporary debug endpoint
-	api.HandleFunc("/debug/campaigns", debugCampaignsHandler).Methods("GET")       // Temporary debug endpoint
+	api.HandleFunc("/media/blob/{key:.+}", serveLocalBlobHandler).Methods("GET") // Authorized by the link's signature
+	api.HandleFunc("/campaigns/{campaignId}/banner", getCampaignBannerHandler).Methods("GET")
+	if appConfig.Features.PublicCampaignPages {
+		api.HandleFunc("/public/campaigns/{slug}", rateLimit("public", getPublicCampaignHandler)).Methods("GET")
+		api.HandleFunc("/public/campaigns/{slug}/share", rateLimit("public", getPublicCampaignShareHandler)).Methods("GET")
+	}
 
 	// Protected routes - general
+	if appConfig.Features.DebugEndpoints {
+		api.HandleFunc("/debug/applications", authMiddleware(debugApplicationsHandler)).Methods("GET") // Temporary debug endpoint
+		api.HandleFunc("/debug/campaigns", authMiddleware(debugCampaignsHandler)).Methods("GET")       // Temporary debug endpoint
+	}
 	api.HandleFunc("/auth/profile", authMiddleware(profileHandler)).Methods("GET")
 	api.HandleFunc("/profile", authMiddleware(createProfileHandler)).Methods("POST")
+	api.HandleFunc("/profile/reporting-currency", authMiddleware(updateReportingCurrencyHandler)).Methods("PUT")
+	api.HandleFunc("/profile/billing", authMiddleware(updateBillingDetailsHandler)).Methods("PUT")
+	api.HandleFunc("/events", eventTokenQuery(authMiddleware(eventsHandler))).Methods("GET")
+
+	// Notification routes
+	api.HandleFunc("/notifications", authMiddleware(getNotificationsHandler)).Methods("GET")
+	api.HandleFunc("/notifications/read-all", authMiddleware(markAllNotificationsReadHandler)).Methods("POST")
+	api.HandleFunc("/notifications/preferences", authMiddleware(getNotificationPreferencesHandler)).Methods("GET")
+	api.HandleFunc("/notifications/preferences", authMiddleware(updateNotificationPreferencesHandler)).Methods("PUT")
+	api.HandleFunc("/notifications/{notificationId}/read", authMiddleware(markNotificationReadHandler)).Methods("POST")
 
 	// Protected routes - user type specific
 	api.HandleFunc("/brand/dashboard", requireUserType("brand", brandOnlyHandler)).Methods("GET")
 	api.HandleFunc("/influencer/dashboard", requireUserType("influencer", influencerOnlyHandler)).Methods("GET")
 
 	// Campaign routes
-	api.HandleFunc("/campaigns", authMiddleware(createCampaignHandler)).Methods("POST")
+	api.HandleFunc("/campaigns", authMiddleware(rateLimit("campaign-create", createCampaignHandler))).Methods("POST")
 	api.HandleFunc("/campaigns", authMiddleware(getCampaignsHandler)).Methods("GET")
 	api.HandleFunc("/campaigns/all", authMiddleware(getAllCampaignsHandler)).Methods("GET")
+	api.HandleFunc("/campaigns/export", authMiddleware(exportCampaignsHandler)).Methods("GET")
+	if appConfig.Features.CampaignImport {
+		api.HandleFunc("/campaigns/import", authMiddleware(rateLimit("campaign-create", importCampaignsHandler))).Methods("POST")
+	}
 	api.HandleFunc("/campaigns/{campaignId}", authMiddleware(getCampaignHandler)).Methods("GET")
 	api.HandleFunc("/campaigns/{campaignId}", authMiddleware(updateCampaignHandler)).Methods("PUT")
 	api.HandleFunc("/campaigns/{campaignId}", authMiddleware(deleteCampaignHandler)).Methods("DELETE")
 	api.HandleFunc("/campaigns/{campaignId}/applications", authMiddleware(getCampaignApplicationsHandler)).Methods("GET")
+	api.HandleFunc("/campaigns/{campaignId}/applications/export", authMiddleware(exportCampaignApplicationsHandler)).Methods("GET")
+	api.HandleFunc("/campaigns/{campaignId}/transitions", authMiddleware(transitionCampaignHandler)).Methods("POST")
+	api.HandleFunc("/campaigns/{campaignId}/duplicate", authMiddleware(rateLimit("campaign-create", duplicateCampaignHandler))).Methods("POST")
+	if appConfig.Features.PublicCampaignPages {
+		api.HandleFunc("/campaigns/{campaignId}/public", authMiddleware(updateCampaignSharingHandler)).Methods("PUT")
+	}
+
+	// Media routes
+	if appConfig.Features.MediaUploads {
+		api.HandleFunc("/media", authMiddleware(rateLimit("upload", uploadMediaHandler))).Methods("POST")
+	}
+	api.HandleFunc("/media", authMiddleware(getMediaHandler)).Methods("GET")
+	api.HandleFunc("/media/{mediaId}", authMiddleware(getMediaAssetHandler)).Methods("GET")
+	api.HandleFunc("/media/{mediaId}", authMiddleware(deleteMediaHandler)).Methods("DELETE")
+	api.HandleFunc("/media/{mediaId}/content", authMiddleware(getMediaContentHandler)).Methods("GET")
+	api.HandleFunc("/media/{mediaId}/link", authMiddleware(linkMediaHandler)).Methods("POST")
+
+	// Campaign template routes
+	api.HandleFunc("/campaign-templates", authMiddleware(getCampaignTemplatesHandler)).Methods("GET")
+	api.HandleFunc("/campaign-templates", authMiddleware(createCampaignTemplateHandler)).Methods("POST")
+	api.HandleFunc("/campaign-templates/{templateId}", authMiddleware(getCampaignTemplateHandler)).Methods("GET")
+	api.HandleFunc("/campaign-templates/{templateId}", authMiddleware(deleteCampaignTemplateHandler)).Methods("DELETE")
+	api.HandleFunc("/campaign-templates/{templateId}/campaigns", authMiddleware(rateLimit("campaign-create", createCampaignFromTemplateHandler))).Methods("POST")
 
 	// Application routes
 	api.HandleFunc("/applications", authMiddleware(getApplicationsForBrandHandler)).Methods("GET")
 	api.HandleFunc("/applications/creator", authMiddleware(getCreatorApplicationsHandler)).Methods("GET")
-	api.HandleFunc("/campaigns/{campaignId}/apply", authMiddleware(applyCampaignHandler)).Methods("POST")
+	api.HandleFunc("/campaigns/{campaignId}/apply", authMiddleware(rateLimit("apply", applyCampaignHandler))).Methods("POST")
 	api.HandleFunc("/applications/{applicationId}/status", authMiddleware(updateApplicationStatusHandler)).Methods("PUT")
+	api.HandleFunc("/applications/{applicationId}", authMiddleware(updateApplicationHandler)).Methods("PUT")
+	api.HandleFunc("/applications/{applicationId}/withdraw", authMiddleware(withdrawApplicationHandler)).Methods("POST")
+	api.HandleFunc("/campaigns/{campaignId}/applications/bulk", authMiddleware(bulkUpdateApplicationsHandler)).Methods("POST")
+
+	// Deliverable routes
+	api.HandleFunc("/deliverables", authMiddleware(getDeliverablesHandler)).Methods("GET")
+	api.HandleFunc("/applications/{applicationId}/deliverables", authMiddleware(getApplicationDeliverablesHandler)).Methods("GET")
+	api.HandleFunc("/deliverables/{deliverableId}", authMiddleware(updateDeliverableHandler)).Methods("PUT")
+	api.HandleFunc("/deliverables/{deliverableId}/submissions", authMiddleware(submitDeliverableHandler)).Methods("POST")
+	api.HandleFunc("/deliverables/{deliverableId}/status", authMiddleware(updateDeliverableStatusHandler)).Methods("PUT")
+	api.HandleFunc("/deliverables/{deliverableId}/reviews", authMiddleware(reviewDeliverableHandler)).Methods("POST")
+	api.HandleFunc("/deliverables/{deliverableId}/compliance", authMiddleware(checkDeliverableComplianceHandler)).Methods("POST")
+
+	// Payment routes
+	api.HandleFunc("/balances", authMiddleware(getBalancesHandler)).Methods("GET")
+	api.HandleFunc("/applications/{applicationId}/ledger", authMiddleware(getApplicationLedgerHandler)).Methods("GET")
+	api.HandleFunc("/applications/{applicationId}/payouts", authMiddleware(createPayoutHandler)).Methods("POST")
+	api.HandleFunc("/applications/{applicationId}/bonuses", authMiddleware(createBonusHandler)).Methods("POST")
+
+	// Invoice routes
+	api.HandleFunc("/invoices", authMiddleware(getInvoicesHandler)).Methods("GET")
+	api.HandleFunc("/invoices", authMiddleware(createInvoiceHandler)).Methods("POST")
+	api.HandleFunc("/invoices/{invoiceId:[0-9a-f]{24}}.pdf", authMiddleware(getInvoicePDFHandler)).Methods("GET")
+	api.HandleFunc("/invoices/{invoiceId:[0-9a-f]{24}}.csv", authMiddleware(getInvoiceCSVHandler)).Methods("GET")
+	api.HandleFunc("/invoices/{invoiceId}", authMiddleware(getInvoiceHandler)).Methods("GET")
+	api.HandleFunc("/statements", authMiddleware(getStatementHandler)).Methods("GET")
+
+	// Reporting routes
+	api.HandleFunc("/reports/spend", authMiddleware(getSpendReportHandler)).Methods("GET")
+	api.HandleFunc("/reports/earnings", authMiddleware(getEarningsReportHandler)).Methods("GET")
+
+	// Negotiation routes
+	api.HandleFunc("/applications/{applicationId}/offers", authMiddleware(getApplicationOffersHandler)).Methods("GET")
+	api.HandleFunc("/applications/{applicationId}/offers", authMiddleware(rateLimit("messages", createOfferHandler))).Methods("POST")
+	api.HandleFunc("/applications/{applicationId}/offers/{offerId}/accept", authMiddleware(acceptOfferHandler)).Methods("POST")
+	api.HandleFunc("/applications/{applicationId}/offers/{offerId}/decline", authMiddleware(declineOfferHandler)).Methods("POST")
+
+	// Contract routes
+	api.HandleFunc("/applications/{applicationId}/contracts", authMiddleware(getApplicationContractsHandler)).Methods("GET")
+	api.HandleFunc("/applications/{applicationId}/contracts", authMiddleware(regenerateContractHandler)).Methods("POST")
+	api.HandleFunc("/contracts/{contractId:[0-9a-f]{24}}.pdf", authMiddleware(getContractPDFHandler)).Methods("GET")
+	api.HandleFunc("/contracts/{contractId}", authMiddleware(getContractHandler)).Methods("GET")
+	api.HandleFunc("/contracts/{contractId}/sign", authMiddleware(signContractHandler)).Methods("POST")
+	api.HandleFunc("/contract-templates", authMiddleware(getContractTemplatesHandler)).Methods("GET")
+	api.HandleFunc("/contract-templates", authMiddleware(createContractTemplateHandler)).Methods("POST")
+
+	// Message routes
+	api.HandleFunc("/applications/{applicationId}/messages", authMiddleware(getApplicationMessagesHandler)).Methods("GET")
+	api.HandleFunc("/applications/{applicationId}/messages", authMiddleware(rateLimit("messages", postApplicationMessageHandler))).Methods("POST")
+	api.HandleFunc("/applications/{applicationId}/messages/read", authMiddleware(markApplicationMessagesReadHandler)).Methods("POST")
 
 	// Setup CORS
 	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:3000", "http://frontend:3000", "http://172.18.0.2:3000"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedOrigins:   appConfig.CORS.AllowedOrigins,
+		AllowedMethods:   appConfig.CORS.AllowedMethods,
 		AllowedHeaders:   []string{"*"},
-		AllowCredentials: true,
+		AllowCredentials: appConfig.CORS.AllowCredentials,
 	})
 
-	handler := c.Handler(router)
+	server := &http.Server{
+		Addr:              ":" + appConfig.Server.Port,
+		Handler:           c.Handler(router),
+		ReadHeaderTimeout: appConfig.Server.ReadHeaderTimeout,
+		ReadTimeout:       appConfig.Server.ReadTimeout,
+		WriteTimeout:      appConfig.Server.WriteTimeout,
+		IdleTimeout:       appConfig.Server.IdleTimeout,
+		MaxHeaderBytes:    appConfig.Server.MaxHeaderBytes,
+	}
+	// Event streams never go idle, so end them when shutdown begins
+	server.RegisterOnShutdown(eventBus.Close)
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	serverErr := make(chan error, 1)
+	go func() {
+		fmt.Printf("%s backend server starting on port %s\n", GetAppName(), appConfig.Server.Port)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	// Wait for SIGINT or SIGTERM; a second signal kills the process straight away
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	exitCode := 0
+	select {
+	case <-signalCtx.Done():
+		log.Println("Shutting down, draining requests and background work...")
+	case err := <-serverErr:
+		log.Println("Server error:", err)
+		exitCode = 1
 	}
+	stopSignals()
 
-	fmt.Printf("%s backend server starting on port %s\n", GetAppName(), port)
-	log.Fatal(http.ListenAndServe(":"+port, handler))
+	if !shutdown(server, stopWorkers, stopDraining, schedulerDone, jobsDone) {
+		exitCode = 1
+	}
+	os.Exit(exitCode)
 }
 
-func healthCheck(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(fmt.Sprintf(`{"status": "OK", "message": "%s"}`, GetAPIMessage())))
+// shutdown stops accepting requests and waits for in-flight ones, then stops
+// the workers and waits for them and for pending notifications, all within
+// server.shutdownTimeout. Mongo is disconnected last. It reports whether
+// everything finished in time.
+func shutdown(server *http.Server, stopWorkers, stopDraining context.CancelFunc, workersDone ...<-chan struct{}) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), appConfig.Server.ShutdownTimeout)
+	defer cancel()
+	// Running jobs see their context cancelled at the deadline
+	context.AfterFunc(ctx, stopDraining)
+
+	clean := true
+	if err := server.Shutdown(ctx); err != nil {
+		log.Println("Shutdown: requests still running at the deadline:", err)
+		server.Close()
+		clean = false
+	}
+
+	stopWorkers()
+	for _, done := range workersDone {
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+	}
+
+	notificationsDone := make(chan struct{})
+	go func() {
+		notificationService.Wait()
+		close(notificationsDone)
+	}()
+	select {
+	case <-notificationsDone:
+	case <-ctx.Done():
+	}
+
+	if ctx.Err() != nil {
+		log.Println("Shutdown: background work still running at the deadline; unfinished jobs will be retried")
+		clean = false
+	}
+
+	closeMongoDB()
+	log.Println("Shutdown complete")
+	return clean
 }
 
 // Temporary debug handler to check applications in database
@@ -0,0 +1,707 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // Register decoders for thumbnails
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Media purposes, which decide what an asset is linked to
+const (
+	MediaPurposeBanner      = "banner"
+	MediaPurposeReference   = "reference"
+	MediaPurposeDeliverable = "deliverable"
+)
+
+const (
+	thumbnailSize      = 320        // Longest side of generated thumbnails, in pixels
+	maxImagePixels     = 40_000_000 // Larger images are rejected before decoding
+	maxMediaUploadSize = 200 << 20
+)
+
+// mediaType describes an accepted upload type
+type mediaType struct {
+	Ext     string
+	MaxSize int64
+	Image   bool
+	Thumb   bool // The standard library can decode it for a thumbnail
+}
+
+// allowedMediaTypes lists the accepted content types and their size limits
+var allowedMediaTypes = map[string]mediaType{
+	"image/jpeg":      {Ext: ".jpg", MaxSize: 10 << 20, Image: true, Thumb: true},
+	"image/png":       {Ext: ".png", MaxSize: 10 << 20, Image: true, Thumb: true},
+	"image/gif":       {Ext: ".gif", MaxSize: 10 << 20, Image: true, Thumb: true},
+	"image/webp":      {Ext: ".webp", MaxSize: 10 << 20, Image: true},
+	"video/mp4":       {Ext: ".mp4", MaxSize: maxMediaUploadSize},
+	"video/quicktime": {Ext: ".mov", MaxSize: maxMediaUploadSize},
+	"application/pdf": {Ext: ".pdf", MaxSize: 20 << 20},
+}
+
+// detectMediaType sniffs the upload rather than trusting the client's
+// Content-Type. Formats the sniffer does not know fall back to the declared
+// type only for video, which has no reliable signature in the first bytes.
+func detectMediaType(head []byte, declared string) string {
+	sniffed := strings.Split(http.DetectContentType(head), ";")[0]
+	if _, ok := allowedMediaTypes[sniffed]; ok {
+		return sniffed
+	}
+	if sniffed == "application/octet-stream" && strings.HasPrefix(declared, "video/") {
+		return declared
+	}
+	return sniffed
+}
+
+// makeThumbnail scales img to fit in size x size, averaging a few source
+// pixels per output pixel. Transparent areas are filled with white.
+func makeThumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	tw, th := w, h
+	if w > size || h > size {
+		if w >= h {
+			tw, th = size, h*size/w
+		} else {
+			tw, th = w*size/h, size
+		}
+	}
+	if tw < 1 {
+		tw = 1
+	}
+	if th < 1 {
+		th = 1
+	}
+
+	// Flatten onto white so JPEG encoding does not turn transparency black
+	flat := image.NewRGBA(bounds)
+	draw.Draw(flat, bounds, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, bounds, img, bounds.Min, draw.Over)
+
+	thumb := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := y*h/th, (y+1)*h/th
+		for x := 0; x < tw; x++ {
+			x0, x1 := x*w/tw, (x+1)*w/tw
+			// Sample at most 4x4 points of the source box
+			stepX, stepY := max((x1-x0)/4, 1), max((y1-y0)/4, 1)
+			var r, g, b, n uint32
+			for sy := y0; sy < max(y1, y0+1); sy += stepY {
+				for sx := x0; sx < max(x1, x0+1); sx += stepX {
+					c := flat.RGBAAt(bounds.Min.X+sx, bounds.Min.Y+sy)
+					r, g, b, n = r+uint32(c.R), g+uint32(c.G), b+uint32(c.B), n+1
+				}
+			}
+			thumb.SetRGBA(x, y, color.RGBA{uint8(r / n), uint8(g / n), uint8(b / n), 255})
+		}
+	}
+	return thumb
+}
+
+// withMediaURLs fills in fresh signed download links
+func withMediaURLs(asset *MediaAsset) *MediaAsset {
+	if url, err := blobStore.SignedURL(asset.Key, signedURLExpiry); err == nil {
+		asset.URL = url
+	}
+	if asset.ThumbnailKey != "" {
+		if url, err := blobStore.SignedURL(asset.ThumbnailKey, signedURLExpiry); err == nil {
+			asset.ThumbnailURL = url
+		}
+	}
+	return asset
+}
+
+// campaignBannerPath is the stable link stored as a campaign's bannerImageUrl.
+// It needs no login so it can be used directly as an image source.
+func campaignBannerPath(campaignID primitive.ObjectID) string {
+	return "/api/campaigns/" + campaignID.Hex() + "/banner"
+}
+
+// mediaContentPath is the link to an asset's file for signed-in users who may view it
+func mediaContentPath(assetID primitive.ObjectID) string {
+	return "/api/media/" + assetID.Hex() + "/content"
+}
+
+// mediaLink is a checked target for linking an asset
+type mediaLink struct {
+	Purpose       string
+	CampaignID    *primitive.ObjectID
+	DeliverableID *primitive.ObjectID
+}
+
+// resolveMediaLink checks that the user may attach media to the campaign
+// (brand owner, as banner or reference) or deliverable (either party)
+func resolveMediaLink(ctx context.Context, userID, campaignId, deliverableId, purpose string, contentType mediaType) (*mediaLink, int, string) {
+	switch {
+	case campaignId != "" && deliverableId != "":
+		return nil, http.StatusBadRequest, "Link media to a campaign or a deliverable, not both"
+
+	case campaignId != "":
+		if purpose == "" {
+			purpose = MediaPurposeReference
+		}
+		if purpose != MediaPurposeBanner && purpose != MediaPurposeReference {
+			return nil, http.StatusBadRequest, "Campaign media purpose must be banner or reference"
+		}
+		if purpose == MediaPurposeBanner && !contentType.Image {
+			return nil, http.StatusBadRequest, "A banner must be an image"
+		}
+		campaign, status, msg := loadOwnedCampaign(ctx, campaignId, userID)
+		if campaign == nil {
+			return nil, status, msg
+		}
+		return &mediaLink{Purpose: purpose, CampaignID: &campaign.ID}, http.StatusOK, ""
+
+	case deliverableId != "":
+		if purpose != "" && purpose != MediaPurposeDeliverable {
+			return nil, http.StatusBadRequest, "Deliverable media purpose must be deliverable"
+		}
+		deliverableOID, err := primitive.ObjectIDFromHex(deliverableId)
+		if err != nil {
+			return nil, http.StatusBadRequest, "Invalid deliverable ID"
+		}
+		deliverable, status, msg := loadDeliverableForParticipant(ctx, deliverableOID, userID)
+		if deliverable == nil {
+			return nil, status, msg
+		}
+		return &mediaLink{Purpose: MediaPurposeDeliverable, DeliverableID: &deliverable.ID}, http.StatusOK, ""
+	}
+
+	if purpose != "" {
+		return nil, http.StatusBadRequest, "purpose needs a campaignId or deliverableId"
+	}
+	return nil, http.StatusOK, ""
+}
+
+// applyMediaLink records the link on the asset and on its campaign or deliverable
+func applyMediaLink(ctx context.Context, asset *MediaAsset, link *mediaLink) error {
+	now := time.Now()
+	switch {
+	case link.Purpose == MediaPurposeBanner:
+		_, err := database.Collection("campaigns").UpdateOne(ctx, bson.M{"_id": link.CampaignID}, bson.M{"$set": bson.M{
+			"bannerAssetId":  asset.ID,
+			"bannerImageUrl": campaignBannerPath(*link.CampaignID),
+			"updatedAt":      now,
+		}})
+		if err != nil {
+			return err
+		}
+	case link.CampaignID != nil:
+		_, err := database.Collection("campaigns").UpdateOne(ctx, bson.M{"_id": link.CampaignID}, bson.M{
+			"$addToSet": bson.M{"referenceAssetIds": asset.ID},
+			"$set":      bson.M{"updatedAt": now},
+		})
+		if err != nil {
+			return err
+		}
+	case link.DeliverableID != nil:
+		_, err := database.Collection("deliverables").UpdateOne(ctx, bson.M{"_id": link.DeliverableID}, bson.M{
+			"$addToSet": bson.M{"assetIds": asset.ID},
+			"$set":      bson.M{"updatedAt": now},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	asset.Purpose = link.Purpose
+	asset.CampaignID = link.CampaignID
+	asset.DeliverableID = link.DeliverableID
+	_, err := database.Collection("media_assets").UpdateOne(ctx, bson.M{"_id": asset.ID}, bson.M{"$set": bson.M{
+		"purpose":       asset.Purpose,
+		"campaignId":    asset.CampaignID,
+		"deliverableId": asset.DeliverableID,
+	}})
+	return err
+}
+
+// unlinkMedia removes every reference to an asset from campaigns and deliverables
+func unlinkMedia(ctx context.Context, assetID primitive.ObjectID) error {
+	campaigns := database.Collection("campaigns")
+	_, err := campaigns.UpdateMany(ctx, bson.M{"bannerAssetId": assetID}, bson.M{
+		"$unset": bson.M{"bannerAssetId": ""},
+		"$set":   bson.M{"bannerImageUrl": ""},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = campaigns.UpdateMany(ctx, bson.M{"referenceAssetIds": assetID}, bson.M{"$pull": bson.M{"referenceAssetIds": assetID}})
+	if err != nil {
+		return err
+	}
+	_, err = database.Collection("deliverables").UpdateMany(ctx, bson.M{"assetIds": assetID}, bson.M{"$pull": bson.M{"assetIds": assetID}})
+	return err
+}
+
+// canViewMedia reports whether the user may download an asset: its uploader,
+// anyone who can see the campaign it belongs to, either party to its
+// deliverable, or either party to a conversation it was sent in
+func canViewMedia(ctx context.Context, asset *MediaAsset, userID string) bool {
+	if asset.OwnerID == userID {
+		return true
+	}
+	if asset.CampaignID != nil {
+		var campaign Campaign
+		if err := database.Collection("campaigns").FindOne(ctx, bson.M{"_id": asset.CampaignID}).Decode(&campaign); err != nil {
+			return false
+		}
+		// Published campaigns are visible to every signed-in user, drafts only to their brand
+		return campaign.BrandID == userID || campaign.Status != CampaignStatusDraft
+	}
+	if asset.DeliverableID != nil {
+		deliverable, _, _ := loadDeliverableForParticipant(ctx, *asset.DeliverableID, userID)
+		return deliverable != nil
+	}
+	// Assets sent in messages are visible to both sides of the conversation
+	var message Message
+	if err := database.Collection("messages").FindOne(ctx, bson.M{"attachments.mediaId": asset.ID}).Decode(&message); err == nil {
+		_, _, status, _ := loadApplicationForParticipant(ctx, message.ApplicationID, userID)
+		return status == http.StatusOK
+	}
+	return false
+}
+
+// loadMediaAsset fetches an asset by its hex ID
+func loadMediaAsset(ctx context.Context, mediaId string) (*MediaAsset, int, string) {
+	mediaOID, err := primitive.ObjectIDFromHex(mediaId)
+	if err != nil {
+		return nil, http.StatusBadRequest, "Invalid media ID"
+	}
+
+	var asset MediaAsset
+	err = database.Collection("media_assets").FindOne(ctx, bson.M{"_id": mediaOID}).Decode(&asset)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, http.StatusNotFound, "Media not found"
+		}
+		return nil, http.StatusInternalServerError, "Error fetching media"
+	}
+	return &asset, http.StatusOK, ""
+}
+
+// uploadMediaHandler stores a multipart "file" upload. Optional form fields
+// campaignId, deliverableId and purpose link it on upload.
+func uploadMediaHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+	userID := getUserIDFromClerkUser(user)
+
+	// The write deadline started when the headers arrived, but the body may
+	// take up to server.readTimeout, so leave server.writeTimeout after that
+	if appConfig.Server.WriteTimeout > 0 {
+		http.NewResponseController(w).SetWriteDeadline(time.Now().Add(appConfig.Server.ReadTimeout + appConfig.Server.WriteTimeout))
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, maxMediaUploadSize+(1<<20))
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		http.Error(w, fmt.Sprintf("Upload must be multipart form data under %d MB", maxMediaUploadSize>>20), http.StatusRequestEntityTooLarge)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "Upload the media in a \"file\" field", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	// Validate the content type and size
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(file, head)
+	contentType := detectMediaType(head[:n], header.Header.Get("Content-Type"))
+	accepted, ok := allowedMediaTypes[contentType]
+	if !ok {
+		http.Error(w, fmt.Sprintf("Unsupported file type %s", contentType), http.StatusUnsupportedMediaType)
+		return
+	}
+	if header.Size > accepted.MaxSize {
+		http.Error(w, fmt.Sprintf("%s files must be under %d MB", contentType, accepted.MaxSize>>20), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	// Check the link target before storing anything
+	link, status, msg := resolveMediaLink(ctx, userID, r.FormValue("campaignId"), r.FormValue("deliverableId"), r.FormValue("purpose"), accepted)
+	if msg != "" {
+		http.Error(w, msg, status)
+		return
+	}
+
+	asset := MediaAsset{
+		ID:          primitive.NewObjectID(),
+		OwnerID:     userID,
+		Filename:    filepath.Base(header.Filename),
+		ContentType: contentType,
+		Size:        header.Size,
+		CreatedAt:   time.Now(),
+	}
+	asset.Key = "media/" + asset.ID.Hex() + "/original" + accepted.Ext
+
+	// Generate a thumbnail for images the standard library can decode
+	var thumbnail []byte
+	if accepted.Thumb {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			http.Error(w, "Error reading upload", http.StatusInternalServerError)
+			return
+		}
+		config, _, err := image.DecodeConfig(file)
+		if err != nil {
+			http.Error(w, "Image could not be read", http.StatusBadRequest)
+			return
+		}
+		if config.Width*config.Height > maxImagePixels {
+			http.Error(w, "Image dimensions are too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		asset.Width, asset.Height = config.Width, config.Height
+
+		file.Seek(0, io.SeekStart)
+		img, _, err := image.Decode(file)
+		if err != nil {
+			http.Error(w, "Image could not be read", http.StatusBadRequest)
+			return
+		}
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, makeThumbnail(img, thumbnailSize), &jpeg.Options{Quality: 80}); err != nil {
+			http.Error(w, "Error generating thumbnail", http.StatusInternalServerError)
+			return
+		}
+		thumbnail = buf.Bytes()
+		asset.ThumbnailKey = "media/" + asset.ID.Hex() + "/thumbnail.jpg"
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		http.Error(w, "Error reading upload", http.StatusInternalServerError)
+		return
+	}
+	if err := blobStore.Put(ctx, asset.Key, contentType, file, header.Size); err != nil {
+		log.Printf("Error storing upload %s: %v", asset.Key, err)
+		http.Error(w, "Error storing upload", http.StatusInternalServerError)
+		return
+	}
+	if thumbnail != nil {
+		if err := blobStore.Put(ctx, asset.ThumbnailKey, "image/jpeg", bytes.NewReader(thumbnail), int64(len(thumbnail))); err != nil {
+			log.Printf("Error storing thumbnail %s: %v", asset.ThumbnailKey, err)
+			asset.ThumbnailKey = ""
+		}
+	}
+
+	if _, err := database.Collection("media_assets").InsertOne(ctx, asset); err != nil {
+		blobStore.Delete(ctx, asset.Key)
+		if asset.ThumbnailKey != "" {
+			blobStore.Delete(ctx, asset.ThumbnailKey)
+		}
+		http.Error(w, "Error saving media", http.StatusInternalServerError)
+		return
+	}
+
+	if link != nil {
+		if err := applyMediaLink(ctx, &asset, link); err != nil {
+			log.Printf("Error linking media %s: %v", asset.ID.Hex(), err)
+			http.Error(w, "Media was stored but could not be linked", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(withMediaURLs(&asset))
+}
+
+// getMediaHandler lists media: the user's own uploads, or the media of a
+// campaign (?campaignId=) or deliverable (?deliverableId=) the user can see
+func getMediaHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+	userID := getUserIDFromClerkUser(user)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"ownerId": userID}
+	query := r.URL.Query()
+	switch {
+	case query.Get("campaignId") != "":
+		campaignOID, err := primitive.ObjectIDFromHex(query.Get("campaignId"))
+		if err != nil {
+			http.Error(w, "Invalid campaign ID", http.StatusBadRequest)
+			return
+		}
+		// Access follows the campaign, so check it through a placeholder asset
+		if !canViewMedia(ctx, &MediaAsset{CampaignID: &campaignOID}, userID) {
+			http.Error(w, "Access denied", http.StatusForbidden)
+			return
+		}
+		filter = bson.M{"campaignId": campaignOID}
+	case query.Get("deliverableId") != "":
+		deliverableOID, err := primitive.ObjectIDFromHex(query.Get("deliverableId"))
+		if err != nil {
+			http.Error(w, "Invalid deliverable ID", http.StatusBadRequest)
+			return
+		}
+		if deliverable, status, msg := loadDeliverableForParticipant(ctx, deliverableOID, userID); deliverable == nil {
+			http.Error(w, msg, status)
+			return
+		}
+		filter = bson.M{"deliverableId": deliverableOID}
+	}
+
+	cursor, err := database.Collection("media_assets").Find(ctx, filter)
+	if err != nil {
+		http.Error(w, "Error fetching media", http.StatusInternalServerError)
+		return
+	}
+	assets := []MediaAsset{}
+	if err = cursor.All(ctx, &assets); err != nil {
+		http.Error(w, "Error decoding media", http.StatusInternalServerError)
+		return
+	}
+	for i := range assets {
+		withMediaURLs(&assets[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(assets)
+}
+
+// getMediaAssetHandler returns one asset with fresh signed links
+func getMediaAssetHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	asset, status, msg := loadMediaAsset(ctx, vars["mediaId"])
+	if asset == nil {
+		http.Error(w, msg, status)
+		return
+	}
+	if !canViewMedia(ctx, asset, getUserIDFromClerkUser(user)) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(withMediaURLs(asset))
+}
+
+// getMediaContentHandler redirects to a signed link for the file (?thumbnail=true for the thumbnail)
+func getMediaContentHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	asset, status, msg := loadMediaAsset(ctx, vars["mediaId"])
+	if asset == nil {
+		http.Error(w, msg, status)
+		return
+	}
+	if !canViewMedia(ctx, asset, getUserIDFromClerkUser(user)) {
+		http.Error(w, "Access denied", http.StatusForbidden)
+		return
+	}
+
+	key := asset.Key
+	if r.URL.Query().Get("thumbnail") == "true" && asset.ThumbnailKey != "" {
+		key = asset.ThumbnailKey
+	}
+	url, err := blobStore.SignedURL(key, signedURLExpiry)
+	if err != nil {
+		http.Error(w, "Error signing link", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, url, http.StatusFound)
+}
+
+// getCampaignBannerHandler redirects to a signed link for a campaign's uploaded
+// banner. Banners are shown on campaign cards and pages, so no login is needed.
+func getCampaignBannerHandler(w http.ResponseWriter, r *http.Request) {
+	campaignOID, err := primitive.ObjectIDFromHex(mux.Vars(r)["campaignId"])
+	if err != nil {
+		http.Error(w, "Invalid campaign ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var campaign Campaign
+	err = database.Collection("campaigns").FindOne(ctx, bson.M{"_id": campaignOID}).Decode(&campaign)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			http.Error(w, "Campaign not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error fetching campaign", http.StatusInternalServerError)
+		}
+		return
+	}
+	if campaign.BannerAssetID == nil {
+		http.Error(w, "Campaign has no uploaded banner", http.StatusNotFound)
+		return
+	}
+
+	asset, status, msg := loadMediaAsset(ctx, campaign.BannerAssetID.Hex())
+	if asset == nil {
+		http.Error(w, msg, status)
+		return
+	}
+	key := asset.Key
+	if r.URL.Query().Get("thumbnail") == "true" && asset.ThumbnailKey != "" {
+		key = asset.ThumbnailKey
+	}
+	url, err := blobStore.SignedURL(key, signedURLExpiry)
+	if err != nil {
+		http.Error(w, "Error signing link", http.StatusInternalServerError)
+		return
+	}
+	// Let browsers reuse the redirect for part of the link's lifetime
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(signedURLExpiry.Seconds())/3))
+	http.Redirect(w, r, url, http.StatusFound)
+}
+
+// linkMediaHandler attaches an uploaded asset to a campaign or deliverable
+func linkMediaHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req struct {
+		CampaignID    string `json:"campaignId"`
+		DeliverableID string `json:"deliverableId"`
+		Purpose       string `json:"purpose"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+	userID := getUserIDFromClerkUser(user)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	asset, status, msg := loadMediaAsset(ctx, vars["mediaId"])
+	if asset == nil {
+		http.Error(w, msg, status)
+		return
+	}
+	if asset.OwnerID != userID {
+		http.Error(w, "Access denied: You can only link your own uploads", http.StatusForbidden)
+		return
+	}
+
+	link, status, msg := resolveMediaLink(ctx, userID, req.CampaignID, req.DeliverableID, req.Purpose, allowedMediaTypes[asset.ContentType])
+	if link == nil {
+		if msg == "" {
+			msg, status = "campaignId or deliverableId is required", http.StatusBadRequest
+		}
+		http.Error(w, msg, status)
+		return
+	}
+
+	// An asset belongs to one campaign or deliverable at a time
+	if err := unlinkMedia(ctx, asset.ID); err != nil {
+		http.Error(w, "Error linking media", http.StatusInternalServerError)
+		return
+	}
+	if err := applyMediaLink(ctx, asset, link); err != nil {
+		http.Error(w, "Error linking media", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(withMediaURLs(asset))
+}
+
+// deleteMediaHandler removes an upload, its links and its stored files
+func deleteMediaHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	asset, status, msg := loadMediaAsset(ctx, vars["mediaId"])
+	if asset == nil {
+		http.Error(w, msg, status)
+		return
+	}
+	if asset.OwnerID != getUserIDFromClerkUser(user) {
+		http.Error(w, "Access denied: You can only delete your own uploads", http.StatusForbidden)
+		return
+	}
+
+	if err := unlinkMedia(ctx, asset.ID); err != nil {
+		http.Error(w, "Error unlinking media", http.StatusInternalServerError)
+		return
+	}
+	if _, err := database.Collection("media_assets").DeleteOne(ctx, bson.M{"_id": asset.ID}); err != nil {
+		http.Error(w, "Error deleting media", http.StatusInternalServerError)
+		return
+	}
+
+	// The record is gone, so a failure here only leaves an orphaned file
+	for _, key := range []string{asset.Key, asset.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := blobStore.Delete(ctx, key); err != nil {
+			log.Printf("Error deleting blob %s: %v", key, err)
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
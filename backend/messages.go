@@ -0,0 +1,310 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Limits for message payloads
+const (
+	maxMessageLength      = 5000
+	maxMessageAttachments = 10
+)
+
+// Page sizes for the message list
+const (
+	defaultMessagePageSize = 50
+	maxMessagePageSize     = 200
+)
+
+// messagePage reads the ?limit= and ?before= (a message ID) list parameters
+func messagePage(query url.Values) (before *primitive.ObjectID, limit int64, err error) {
+	limit = defaultMessagePageSize
+	if l := query.Get("limit"); l != "" {
+		limit, err = strconv.ParseInt(l, 10, 64)
+		if err != nil || limit < 1 || limit > maxMessagePageSize {
+			return nil, 0, fmt.Errorf("limit must be between 1 and %d", maxMessagePageSize)
+		}
+	}
+	if b := query.Get("before"); b != "" {
+		oid, err := primitive.ObjectIDFromHex(b)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid before message ID")
+		}
+		before = &oid
+	}
+	return before, limit, nil
+}
+
+// validateMessageAttachment checks that an attachment is an uploaded asset
+// or an http(s) link, so no javascript: or data: URLs reach the other party
+func validateMessageAttachment(attachment MessageAttachment) error {
+	if attachment.MediaID != nil {
+		return nil
+	}
+	if attachment.URL == "" {
+		return fmt.Errorf("attachment URL or media ID is required")
+	}
+	u, err := url.Parse(attachment.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("attachment URL must be an http or https link")
+	}
+	return nil
+}
+
+// getApplicationMessagesHandler lists the conversation for an application (brand owner or applicant)
+func getApplicationMessagesHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	applicationId := vars["applicationId"]
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	// Convert application ID to ObjectID
+	appObjID, err := primitive.ObjectIDFromHex(applicationId)
+	if err != nil {
+		http.Error(w, "Invalid application ID", http.StatusBadRequest)
+		return
+	}
+
+	before, limit, err := messagePage(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID := getUserIDFromClerkUser(user)
+	if _, _, status, msg := loadApplicationForParticipant(ctx, appObjID, userID); status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	filter := bson.M{"applicationId": appObjID}
+	if before != nil {
+		filter["_id"] = bson.M{"$lt": *before}
+	}
+
+	// Read the newest page, then return it oldest first
+	collection := database.Collection("messages")
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}}).SetLimit(limit)
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		http.Error(w, "Error fetching messages", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	messages := []Message{}
+	if err = cursor.All(ctx, &messages); err != nil {
+		http.Error(w, "Error decoding messages", http.StatusInternalServerError)
+		return
+	}
+	slices.Reverse(messages)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(messages)
+}
+
+// postApplicationMessageHandler adds a message to an application's conversation
+func postApplicationMessageHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	applicationId := vars["applicationId"]
+
+	var req struct {
+		Body        string              `json:"body"`
+		ParentID    string              `json:"parentId"`
+		Attachments []MessageAttachment `json:"attachments"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Validate message
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Body == "" && len(req.Attachments) == 0 {
+		http.Error(w, "Message must have a body or at least one attachment", http.StatusBadRequest)
+		return
+	}
+	if len(req.Body) > maxMessageLength {
+		http.Error(w, "Message is too long", http.StatusBadRequest)
+		return
+	}
+	if len(req.Attachments) > maxMessageAttachments {
+		http.Error(w, "Too many attachments", http.StatusBadRequest)
+		return
+	}
+	for _, attachment := range req.Attachments {
+		if err := validateMessageAttachment(attachment); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	// Convert application ID to ObjectID
+	appObjID, err := primitive.ObjectIDFromHex(applicationId)
+	if err != nil {
+		http.Error(w, "Invalid application ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID := getUserIDFromClerkUser(user)
+	application, campaign, status, msg := loadApplicationForParticipant(ctx, appObjID, userID)
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	collection := database.Collection("messages")
+
+	// Replies must point at a message in the same conversation
+	var parentID *primitive.ObjectID
+	if req.ParentID != "" {
+		parentOID, err := primitive.ObjectIDFromHex(req.ParentID)
+		if err != nil {
+			http.Error(w, "Invalid parent message ID", http.StatusBadRequest)
+			return
+		}
+		count, err := collection.CountDocuments(ctx, bson.M{"_id": parentOID, "applicationId": appObjID})
+		if err != nil {
+			http.Error(w, "Error fetching parent message", http.StatusInternalServerError)
+			return
+		}
+		if count == 0 {
+			http.Error(w, "Parent message not found", http.StatusNotFound)
+			return
+		}
+		parentID = &parentOID
+	}
+
+	senderType := "influencer"
+	senderName := application.CreatorName
+	if campaign.BrandID == userID {
+		senderType = "brand"
+		senderName = campaign.BrandName
+	}
+
+	// Uploaded assets must be the sender's own; their details come from the asset
+	attachments := []MessageAttachment{}
+	for _, attachment := range req.Attachments {
+		if attachment.MediaID != nil {
+			asset, status, msg := loadMediaAsset(ctx, attachment.MediaID.Hex())
+			if asset == nil {
+				http.Error(w, msg, status)
+				return
+			}
+			if asset.OwnerID != userID {
+				http.Error(w, "Access denied: You can only attach your own media", http.StatusForbidden)
+				return
+			}
+			attachment = MessageAttachment{
+				Name:        asset.Filename,
+				URL:         mediaContentPath(asset.ID),
+				MediaID:     &asset.ID,
+				ContentType: asset.ContentType,
+				Size:        asset.Size,
+			}
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	now := time.Now()
+	message := Message{
+		ID:            primitive.NewObjectID(),
+		ApplicationID: appObjID,
+		CampaignID:    campaign.ID,
+		ParentID:      parentID,
+		SenderID:      userID,
+		SenderName:    senderName,
+		SenderType:    senderType,
+		Body:          req.Body,
+		Attachments:   attachments,
+		ReadBy:        []MessageReceipt{{UserID: userID, ReadAt: now}},
+		CreatedAt:     now,
+	}
+
+	if _, err := collection.InsertOne(ctx, message); err != nil {
+		http.Error(w, "Error creating message", http.StatusInternalServerError)
+		return
+	}
+
+	publishEvent(EventMessageCreated, message, campaign.BrandID, application.CreatorID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(message)
+}
+
+// markApplicationMessagesReadHandler records a read receipt for every unread message in the conversation
+func markApplicationMessagesReadHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	applicationId := vars["applicationId"]
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	// Convert application ID to ObjectID
+	appObjID, err := primitive.ObjectIDFromHex(applicationId)
+	if err != nil {
+		http.Error(w, "Invalid application ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID := getUserIDFromClerkUser(user)
+	if _, _, status, msg := loadApplicationForParticipant(ctx, appObjID, userID); status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	result, err := database.Collection("messages").UpdateMany(ctx,
+		bson.M{"applicationId": appObjID, "readBy.userId": bson.M{"$ne": userID}},
+		bson.M{"$push": bson.M{"readBy": MessageReceipt{UserID: userID, ReadAt: time.Now()}}},
+	)
+	if err != nil {
+		http.Error(w, "Error updating messages", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Messages marked as read",
+		"updated": result.ModifiedCount,
+	})
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestValidateMessageAttachment(t *testing.T) {
+	mediaID := primitive.NewObjectID()
+	tests := []struct {
+		name       string
+		attachment MessageAttachment
+		ok         bool
+	}{
+		{"https link", MessageAttachment{URL: "https://example.com/brief.pdf"}, true},
+		{"http link", MessageAttachment{URL: "http://example.com/brief.pdf"}, true},
+		{"uploaded asset", MessageAttachment{MediaID: &mediaID}, true},
+		{"missing URL", MessageAttachment{Name: "brief.pdf"}, false},
+		{"javascript URL", MessageAttachment{URL: "javascript:alert(1)"}, false},
+		{"data URL", MessageAttachment{URL: "data:text/html,<script>alert(1)</script>"}, false},
+		{"relative path", MessageAttachment{URL: "/api/media/x/content"}, false},
+		{"no host", MessageAttachment{URL: "https:///brief.pdf"}, false},
+	}
+	for _, tt := range tests {
+		if err := validateMessageAttachment(tt.attachment); (err == nil) != tt.ok {
+			t.Errorf("%s: error = %v, want ok %v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestMessagePage(t *testing.T) {
+	before, limit, err := messagePage(url.Values{})
+	if err != nil || before != nil || limit != defaultMessagePageSize {
+		t.Errorf("defaults = %v, %d, %v", before, limit, err)
+	}
+
+	id := primitive.NewObjectID()
+	before, limit, err = messagePage(url.Values{"limit": {"20"}, "before": {id.Hex()}})
+	if err != nil || before == nil || *before != id || limit != 20 {
+		t.Errorf("explicit page = %v, %d, %v", before, limit, err)
+	}
+
+	for _, query := range []url.Values{
+		{"limit": {"0"}},
+		{"limit": {"201"}},
+		{"limit": {"ten"}},
+		{"before": {"not-an-id"}},
+	} {
+		if _, _, err := messagePage(query); err == nil {
+			t.Errorf("%v was accepted", query)
+		}
+	}
+}
@@ -2,7 +2,11 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
+	"net"
 	"net/http"
+	"net/netip"
+	"strings"
 
 	"github.com/clerk/clerk-sdk-go/v2"
 )
@@ -56,3 +60,70 @@ func influencerOnlyHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
+
+// parseTrustedProxies reads server.trustedProxies, where each entry is an IP
+// address or a CIDR range
+func parseTrustedProxies(entries []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range entries {
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an IP address or CIDR range", entry)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// trustedProxies holds server.trustedProxies, parsed once at startup
+var trustedProxies []netip.Prefix
+
+// isTrustedProxy reports whether ip is listed in server.trustedProxies
+func isTrustedProxy(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the caller's IP. Forwarding headers are only believed
+// when the connection comes from a trusted proxy, and X-Forwarded-For is
+// read from the right so a client cannot put its own address first.
+func clientIP(r *http.Request) string {
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+	if !isTrustedProxy(peer) {
+		return peer
+	}
+
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		client := ""
+		for i := len(hops) - 1; i >= 0; i-- {
+			client = strings.TrimSpace(hops[i])
+			if client != "" && !isTrustedProxy(client) {
+				break
+			}
+		}
+		if client != "" {
+			return client
+		}
+	}
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); realIP != "" {
+		return realIP
+	}
+	return peer
+}
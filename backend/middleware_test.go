@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	saved := trustedProxies
+	defer func() { trustedProxies = saved }()
+	var err error
+	trustedProxies, err = parseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		realIP     string
+		want       string
+	}{
+		{"direct client", "203.0.113.7:5123", nil, "", "203.0.113.7"},
+		{"spoofed header from untrusted peer", "203.0.113.7:5123", []string{"1.2.3.4"}, "5.6.7.8", "203.0.113.7"},
+		{"trusted proxy", "10.1.2.3:80", []string{"198.51.100.4"}, "", "198.51.100.4"},
+		{"client prepends a fake hop", "10.1.2.3:80", []string{"1.2.3.4, 198.51.100.4"}, "", "198.51.100.4"},
+		{"chain of trusted proxies", "10.1.2.3:80", []string{"198.51.100.4, 192.168.1.10", "10.9.9.9"}, "", "198.51.100.4"},
+		{"empty hops are skipped", "10.1.2.3:80", []string{"198.51.100.4, "}, "", "198.51.100.4"},
+		{"X-Real-IP from trusted proxy", "192.168.1.10:80", nil, "198.51.100.9", "198.51.100.9"},
+		{"only trusted hops", "10.1.2.3:80", []string{"10.0.0.5"}, "", "10.0.0.5"},
+		{"IPv4-mapped peer", "[::ffff:10.1.2.3]:80", []string{"198.51.100.4"}, "", "198.51.100.4"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tt.remoteAddr
+		for _, value := range tt.forwarded {
+			r.Header.Add("X-Forwarded-For", value)
+		}
+		if tt.realIP != "" {
+			r.Header.Set("X-Real-IP", tt.realIP)
+		}
+		if got := clientIP(r); got != tt.want {
+			t.Errorf("%s: clientIP = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	if _, err := parseTrustedProxies([]string{"10.0.0.0/8", "::1", "2001:db8::/32"}); err != nil {
+		t.Errorf("valid entries: %v", err)
+	}
+	if _, err := parseTrustedProxies([]string{"proxy.internal"}); err == nil {
+		t.Error("hostname was accepted")
+	}
+}
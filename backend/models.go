@@ -7,13 +7,17 @@ import (
 )
 
 type User struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	ClerkID   string             `bson:"clerkId" json:"clerkId"` // Clerk user ID for linking
-	Email     string             `bson:"email" json:"email"`
-	Name      string             `bson:"name" json:"name"`
-	UserType  string             `bson:"userType" json:"userType"` // "brand" or "creator"
-	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
-	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
+	ID       primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ClerkID  string             `bson:"clerkId" json:"clerkId"` // Clerk user ID for linking
+	Email    string             `bson:"email" json:"email"`
+	Name     string             `bson:"name" json:"name"`
+	UserType string             `bson:"userType" json:"userType"` // "brand" or "creator"
+
+	ReportingCurrency string          `bson:"reportingCurrency,omitempty" json:"reportingCurrency,omitempty"` // ISO 4217 code reports convert into
+	Billing           *BillingDetails `bson:"billing,omitempty" json:"billing,omitempty"`                     // Printed on invoices
+
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
 }
 
 type Campaign struct {
@@ -77,6 +81,7 @@ type Campaign struct {
 	PerformanceBonus     bool   `bson:"performanceBonus" json:"performanceBonus"`
 	BonusCriteria        string `bson:"bonusCriteria" json:"bonusCriteria"`
 	ProductDetails       string `bson:"productDetails" json:"productDetails"`
+	UsageRights          string `bson:"usageRights" json:"usageRights"` // How the brand may reuse creator content; printed on contracts
 
 	// Campaign Workflow
 	ApprovalSteps        []string `bson:"approvalSteps" json:"approvalSteps"`
@@ -85,15 +90,29 @@ type Campaign struct {
 	TimeZone             string   `bson:"timeZone" json:"timeZone"`
 
 	// Media & Assets
-	BannerImageURL string `bson:"bannerImageUrl" json:"bannerImageUrl"`
-	ReferenceLinks string `bson:"referenceLinks" json:"referenceLinks"`
-	ReferenceMedia string `bson:"referenceMedia" json:"referenceMedia"`
+	BannerImageURL    string               `bson:"bannerImageUrl" json:"bannerImageUrl"`
+	ReferenceLinks    string               `bson:"referenceLinks" json:"referenceLinks"`
+	ReferenceMedia    string               `bson:"referenceMedia" json:"referenceMedia"`
+	BannerAssetID     *primitive.ObjectID  `bson:"bannerAssetId,omitempty" json:"bannerAssetId,omitempty"` // Uploaded banner, see MediaAsset
+	ReferenceAssetIDs []primitive.ObjectID `bson:"referenceAssetIds,omitempty" json:"referenceAssetIds,omitempty"`
+
+	// Public sharing (opt-in)
+	PublicSlug       string `bson:"publicSlug,omitempty" json:"publicSlug,omitempty"` // Assigned once, never changes
+	IsPublic         bool   `bson:"isPublic" json:"isPublic"`
+	PublicShowBudget bool   `bson:"publicShowBudget" json:"publicShowBudget"` // Include budget and payment amount on the public page
+	PublicViews      int64  `bson:"publicViews" json:"publicViews"`
 
 	// Status and Metadata
-	Status     string    `bson:"status" json:"status"` // "draft", "active", "completed", "cancelled"
-	Applicants int       `bson:"applicants" json:"applicants"`
-	CreatedAt  time.Time `bson:"createdAt" json:"createdAt"`
-	UpdatedAt  time.Time `bson:"updatedAt" json:"updatedAt"`
+	Status     string `bson:"status" json:"status"` // "draft", "scheduled", "active", "paused", "completed", "cancelled"
+	Applicants int    `bson:"applicants" json:"applicants"`
+
+	// Budget reserved for approved creators, in minor units (e.g. cents).
+	// Only the owner sees it, through BudgetSummary.
+	ReservedBudget int64          `bson:"reservedBudget" json:"-"`
+	BudgetSummary  *BudgetSummary `bson:"-" json:"budgetSummary,omitempty"`
+
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time `bson:"updatedAt" json:"updatedAt"`
 }
 
 type Application struct {
@@ -104,11 +123,20 @@ type Application struct {
 	CreatorEmail string             `bson:"creatorEmail" json:"creatorEmail"`
 	Followers    string             `bson:"followers" json:"followers"`
 	Platform     string             `bson:"platform" json:"platform"`
-	Status       string             `bson:"status" json:"status"` // "pending", "approved", "rejected"
+	Status       string             `bson:"status" json:"status"` // "pending", "shortlisted", "approved", "rejected", "withdrawn"
 	AppliedDate  time.Time          `bson:"appliedDate" json:"appliedDate"`
-	CampaignName string             `bson:"campaignName" json:"campaignName"`
-	CreatedAt    time.Time          `bson:"createdAt" json:"createdAt"`
-	UpdatedAt    time.Time          `bson:"updatedAt" json:"updatedAt"`
+
+	Pitch         string            `bson:"pitch" json:"pitch"`
+	ProposedTerms *ApplicationTerms `bson:"proposedTerms,omitempty" json:"proposedTerms,omitempty"` // The creator's opening offer
+	AgreedTerms   *ApplicationTerms `bson:"agreedTerms,omitempty" json:"agreedTerms,omitempty"`     // Frozen once an offer is accepted
+	AgreedAt      *time.Time        `bson:"agreedAt,omitempty" json:"agreedAt,omitempty"`
+
+	ReservedAmount int64 `bson:"reservedAmount" json:"reservedAmount"` // Budget held for this creator, in minor units
+	ApprovalRound  int   `bson:"approvalRound" json:"approvalRound"`   // Incremented on each approval
+
+	CampaignName string    `bson:"campaignName" json:"campaignName"`
+	CreatedAt    time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt    time.Time `bson:"updatedAt" json:"updatedAt"`
 }
 
 // CampaignRequest represents the request payload for creating a campaign
@@ -166,6 +194,7 @@ type CampaignRequest struct {
 	PerformanceBonus     bool   `json:"performanceBonus"`
 	BonusCriteria        string `json:"bonusCriteria"`
 	ProductDetails       string `json:"productDetails"`
+	UsageRights          string `json:"usageRights"`
 
 	ApprovalSteps        []string `json:"approvalSteps"`
 	DeadlineReminders    bool     `json:"deadlineReminders"`
@@ -185,3 +214,314 @@ type UserProfile struct {
 	Name     string `json:"name"`
 	UserType string `json:"userType"`
 }
+
+// Message is a single entry in the conversation attached to an application
+type Message struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	ApplicationID primitive.ObjectID  `bson:"applicationId" json:"applicationId"`
+	CampaignID    primitive.ObjectID  `bson:"campaignId" json:"campaignId"`
+	ParentID      *primitive.ObjectID `bson:"parentId,omitempty" json:"parentId,omitempty"` // Message being replied to
+	SenderID      string              `bson:"senderId" json:"senderId"`                     // Clerk ID of the sender
+	SenderName    string              `bson:"senderName" json:"senderName"`
+	SenderType    string              `bson:"senderType" json:"senderType"` // "brand" or "influencer"
+	Body          string              `bson:"body" json:"body"`
+	Attachments   []MessageAttachment `bson:"attachments" json:"attachments"`
+	ReadBy        []MessageReceipt    `bson:"readBy" json:"readBy"`
+	CreatedAt     time.Time           `bson:"createdAt" json:"createdAt"`
+}
+
+// MessageAttachment references an uploaded asset or a file hosted elsewhere
+type MessageAttachment struct {
+	Name        string              `bson:"name" json:"name"`
+	URL         string              `bson:"url" json:"url"`
+	MediaID     *primitive.ObjectID `bson:"mediaId,omitempty" json:"mediaId,omitempty"` // Uploaded asset, if the attachment is one
+	ContentType string              `bson:"contentType" json:"contentType"`
+	Size        int64               `bson:"size" json:"size"`
+}
+
+// MessageReceipt records when a participant read a message
+type MessageReceipt struct {
+	UserID string    `bson:"userId" json:"userId"`
+	ReadAt time.Time `bson:"readAt" json:"readAt"`
+}
+
+// Notification is an entry in a user's in-app inbox
+type Notification struct {
+	ID        primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	UserID    string                 `bson:"userId" json:"userId"` // Clerk ID of the recipient
+	Type      string                 `bson:"type" json:"type"`
+	Title     string                 `bson:"title" json:"title"`
+	Body      string                 `bson:"body" json:"body"`
+	Link      string                 `bson:"link,omitempty" json:"link,omitempty"`
+	Data      map[string]interface{} `bson:"data,omitempty" json:"data,omitempty"`
+	Read      bool                   `bson:"read" json:"read"`
+	ReadAt    *time.Time             `bson:"readAt,omitempty" json:"readAt,omitempty"`
+	Key       string                 `bson:"key,omitempty" json:"-"` // Set by jobs so a retried delivery is not stored or emailed twice
+	CreatedAt time.Time              `bson:"createdAt" json:"createdAt"`
+}
+
+// NotificationPreferences controls how a user is notified
+type NotificationPreferences struct {
+	UserID        string    `bson:"userId" json:"userId"`
+	InApp         bool      `bson:"inApp" json:"inApp"`
+	Email         bool      `bson:"email" json:"email"`
+	DisabledTypes []string  `bson:"disabledTypes" json:"disabledTypes"` // Notification types the user opted out of
+	UpdatedAt     time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Deliverable is a piece of content an approved creator owes the brand
+type Deliverable struct {
+	ID            primitive.ObjectID      `bson:"_id,omitempty" json:"id"`
+	ApplicationID primitive.ObjectID      `bson:"applicationId" json:"applicationId"`
+	CampaignID    primitive.ObjectID      `bson:"campaignId" json:"campaignId"`
+	BrandID       string                  `bson:"brandId" json:"brandId"`     // Clerk ID of the campaign's brand
+	CreatorID     string                  `bson:"creatorId" json:"creatorId"` // Clerk ID of the creator
+	CampaignName  string                  `bson:"campaignName" json:"campaignName"`
+	Sequence      int                     `bson:"sequence" json:"sequence"` // 1-based position within the application
+	Title         string                  `bson:"title" json:"title"`
+	ContentFormat string                  `bson:"contentFormat" json:"contentFormat"`
+	Platform      string                  `bson:"platform" json:"platform"`
+	DueDate       string                  `bson:"dueDate" json:"dueDate"` // YYYY-MM-DD in the campaign's time zone
+	Status        string                  `bson:"status" json:"status"`   // "pending", "submitted", "revision_needed", "approved", "rejected", "published"
+	PostURL       string                  `bson:"postUrl" json:"postUrl"`
+	DraftURL      string                  `bson:"draftUrl" json:"draftUrl"`
+	Caption       string                  `bson:"caption" json:"caption"`
+	Feedback      string                  `bson:"feedback" json:"feedback"`
+	Submissions   []DeliverableSubmission `bson:"submissions" json:"submissions"`
+	Version       int                     `bson:"version" json:"version"`             // Version of the latest submission
+	ApprovalSteps []string                `bson:"approvalSteps" json:"approvalSteps"` // Ordered review steps copied from the campaign
+	CurrentStep   int                     `bson:"currentStep" json:"currentStep"`     // Index of the step awaiting review
+	Reviews       []DeliverableReview     `bson:"reviews" json:"reviews"`
+	Compliance    *ComplianceReport       `bson:"compliance,omitempty" json:"compliance,omitempty"`
+	AssetIDs      []primitive.ObjectID    `bson:"assetIds,omitempty" json:"assetIds,omitempty"` // Uploaded media, see MediaAsset
+	SubmittedAt   *time.Time              `bson:"submittedAt,omitempty" json:"submittedAt,omitempty"`
+	PublishedAt   *time.Time              `bson:"publishedAt,omitempty" json:"publishedAt,omitempty"`
+	CreatedAt     time.Time               `bson:"createdAt" json:"createdAt"`
+	UpdatedAt     time.Time               `bson:"updatedAt" json:"updatedAt"`
+}
+
+// DeliverableSubmission is one version of content submitted by the creator
+type DeliverableSubmission struct {
+	Version     int       `bson:"version" json:"version"`
+	PostURL     string    `bson:"postUrl" json:"postUrl"`
+	DraftURL    string    `bson:"draftUrl" json:"draftUrl"`
+	Caption     string    `bson:"caption" json:"caption"`
+	Transcript  string    `bson:"transcript" json:"transcript"`
+	Notes       string    `bson:"notes" json:"notes"`
+	SubmittedAt time.Time `bson:"submittedAt" json:"submittedAt"`
+}
+
+// DeliverableReview is a brand decision on one approval step of a submission
+type DeliverableReview struct {
+	Version    int       `bson:"version" json:"version"`
+	Step       string    `bson:"step" json:"step"`
+	Action     string    `bson:"action" json:"action"` // "approve", "request_changes", "reject"
+	Comment    string    `bson:"comment" json:"comment"`
+	ReviewerID string    `bson:"reviewerId" json:"reviewerId"`
+	CreatedAt  time.Time `bson:"createdAt" json:"createdAt"`
+}
+
+// ComplianceReport is the result of checking submitted content against the campaign's rules
+type ComplianceReport struct {
+	Version           int       `bson:"version" json:"version"` // Submission version that was checked
+	Passed            bool      `bson:"passed" json:"passed"`
+	MissingHashtags   []string  `bson:"missingHashtags" json:"missingHashtags"`
+	MissingMentions   []string  `bson:"missingMentions" json:"missingMentions"`
+	MissingDisclosure bool      `bson:"missingDisclosure" json:"missingDisclosure"` // No #ad / #sponsored style tag found
+	BannedTerms       []string  `bson:"bannedTerms" json:"bannedTerms"`             // Banned terms from ContentGuidelines found in the content
+	CheckedAt         time.Time `bson:"checkedAt" json:"checkedAt"`
+}
+
+// LedgerTransaction is a balanced set of ledger entries (entries sum to zero)
+type LedgerTransaction struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	IdempotencyKey string             `bson:"idempotencyKey" json:"idempotencyKey"` // Unique; recording the same key twice is a no-op
+	Kind           string             `bson:"kind" json:"kind"`                     // "commitment", "milestone", "bonus", "payout"
+	ApplicationID  primitive.ObjectID `bson:"applicationId" json:"applicationId"`
+	CampaignID     primitive.ObjectID `bson:"campaignId" json:"campaignId"`
+	BrandID        string             `bson:"brandId" json:"brandId"`
+	CreatorID      string             `bson:"creatorId" json:"creatorId"`
+	Currency       string             `bson:"currency" json:"currency"`
+	Amount         int64              `bson:"amount" json:"amount"` // Minor units (e.g. cents)
+	Description    string             `bson:"description" json:"description"`
+	Entries        []LedgerEntry      `bson:"entries" json:"entries"`
+	CreatedAt      time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// LedgerEntry debits (positive) or credits (negative) one account
+type LedgerEntry struct {
+	Account   string `bson:"account" json:"account"`     // e.g. "creator:<clerkId>:receivable"
+	OwnerType string `bson:"ownerType" json:"ownerType"` // "brand" or "creator"
+	OwnerID   string `bson:"ownerId" json:"ownerId"`
+	Bucket    string `bson:"bucket" json:"bucket"`
+	Amount    int64  `bson:"amount" json:"amount"`
+}
+
+// Payout is a request to transfer money to a creator through the payment provider
+type Payout struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	IdempotencyKey string             `bson:"idempotencyKey" json:"idempotencyKey"`
+	ApplicationID  primitive.ObjectID `bson:"applicationId" json:"applicationId"`
+	BrandID        string             `bson:"brandId" json:"brandId"`
+	CreatorID      string             `bson:"creatorId" json:"creatorId"`
+	Currency       string             `bson:"currency" json:"currency"`
+	Amount         int64              `bson:"amount" json:"amount"` // Minor units (e.g. cents)
+	Status         string             `bson:"status" json:"status"` // "pending", "paid", "failed"
+	Provider       string             `bson:"provider" json:"provider"`
+	ProviderRef    string             `bson:"providerRef" json:"providerRef"`
+	Error          string             `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedAt      time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt      time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// BillingDetails are the legal and tax details printed on invoices
+type BillingDetails struct {
+	LegalName string `bson:"legalName" json:"legalName"`
+	TaxID     string `bson:"taxId" json:"taxId"` // VAT / GST / EIN number
+	Address   string `bson:"address" json:"address"`
+	Country   string `bson:"country" json:"country"`
+}
+
+// Invoice bills a brand for an approved application, a milestone or a bonus.
+// Numbers run sequentially per brand.
+type Invoice struct {
+	ID                  primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Number              string             `bson:"number" json:"number"` // e.g. "INV-00042"
+	Sequence            int64              `bson:"sequence" json:"sequence"`
+	Kind                string             `bson:"kind" json:"kind"`                               // "application", "milestone", "bonus"
+	SourceTransactionID primitive.ObjectID `bson:"sourceTransactionId" json:"sourceTransactionId"` // Ledger transaction being billed
+	ApplicationID       primitive.ObjectID `bson:"applicationId" json:"applicationId"`
+	CampaignID          primitive.ObjectID `bson:"campaignId" json:"campaignId"`
+	DeliverableID       primitive.ObjectID `bson:"deliverableId,omitempty" json:"deliverableId,omitempty"`
+	BrandID             string             `bson:"brandId" json:"brandId"`
+	CreatorID           string             `bson:"creatorId" json:"creatorId"`
+	CampaignTitle       string             `bson:"campaignTitle" json:"campaignTitle"`
+	Seller              InvoiceParty       `bson:"seller" json:"seller"` // The creator
+	Buyer               InvoiceParty       `bson:"buyer" json:"buyer"`   // The brand
+	Currency            string             `bson:"currency" json:"currency"`
+	Lines               []InvoiceLine      `bson:"lines" json:"lines"`
+	Subtotal            int64              `bson:"subtotal" json:"subtotal"` // Minor units (e.g. cents)
+	TaxLabel            string             `bson:"taxLabel" json:"taxLabel"` // e.g. "VAT", "GST"
+	TaxRate             float64            `bson:"taxRate" json:"taxRate"`   // Percent
+	TaxAmount           int64              `bson:"taxAmount" json:"taxAmount"`
+	Total               int64              `bson:"total" json:"total"`
+	Notes               string             `bson:"notes" json:"notes"`
+	IssuedAt            time.Time          `bson:"issuedAt" json:"issuedAt"`
+	DueAt               time.Time          `bson:"dueAt" json:"dueAt"`
+	CreatedAt           time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// InvoiceParty is a snapshot of one side's details when the invoice was issued
+type InvoiceParty struct {
+	UserID    string `bson:"userId" json:"userId"`
+	Name      string `bson:"name" json:"name"`
+	Email     string `bson:"email" json:"email"`
+	LegalName string `bson:"legalName" json:"legalName"`
+	TaxID     string `bson:"taxId" json:"taxId"`
+	Address   string `bson:"address" json:"address"`
+	Country   string `bson:"country" json:"country"`
+}
+
+// InvoiceLine is one billed item
+type InvoiceLine struct {
+	Description string `bson:"description" json:"description"`
+	Quantity    int    `bson:"quantity" json:"quantity"`
+	UnitAmount  int64  `bson:"unitAmount" json:"unitAmount"`
+	Amount      int64  `bson:"amount" json:"amount"`
+}
+
+// ApplicationTerms are the rate and deliverables offered for an application
+type ApplicationTerms struct {
+	Rate           string   `bson:"rate" json:"rate"`                 // Free-text amount in the campaign currency, e.g. "$1,200"
+	Deliverables   int      `bson:"deliverables" json:"deliverables"` // Number of posts
+	ContentFormats []string `bson:"contentFormats,omitempty" json:"contentFormats,omitempty"`
+	Notes          string   `bson:"notes" json:"notes"`
+}
+
+// Offer is one proposal in the negotiation on an application
+type Offer struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ApplicationID primitive.ObjectID `bson:"applicationId" json:"applicationId"`
+	CampaignID    primitive.ObjectID `bson:"campaignId" json:"campaignId"`
+	BrandID       string             `bson:"brandId" json:"brandId"`
+	CreatorID     string             `bson:"creatorId" json:"creatorId"`
+	FromUserID    string             `bson:"fromUserId" json:"fromUserId"`
+	FromRole      string             `bson:"fromRole" json:"fromRole"` // "brand" or "creator"
+	Terms         ApplicationTerms   `bson:"terms" json:"terms"`
+	Message       string             `bson:"message" json:"message"`
+	Status        string             `bson:"status" json:"status"` // "open", "superseded", "accepted", "declined"
+	CreatedAt     time.Time          `bson:"createdAt" json:"createdAt"`
+	RespondedAt   *time.Time         `bson:"respondedAt,omitempty" json:"respondedAt,omitempty"`
+}
+
+// Contract is one version of the agreement generated for an approved application
+type Contract struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	ApplicationID primitive.ObjectID  `bson:"applicationId" json:"applicationId"`
+	CampaignID    primitive.ObjectID  `bson:"campaignId" json:"campaignId"`
+	BrandID       string              `bson:"brandId" json:"brandId"`
+	CreatorID     string              `bson:"creatorId" json:"creatorId"`
+	Version       int                 `bson:"version" json:"version"`
+	TemplateName  string              `bson:"templateName" json:"templateName"`
+	Body          string              `bson:"body" json:"body"`
+	DocumentHash  string              `bson:"documentHash" json:"documentHash"` // SHA-256 of Body, hex encoded
+	Status        string              `bson:"status" json:"status"`             // "pending_signatures", "signed", "superseded"
+	Signatures    []ContractSignature `bson:"signatures" json:"signatures"`
+	CreatedAt     time.Time           `bson:"createdAt" json:"createdAt"`
+	SignedAt      *time.Time          `bson:"signedAt,omitempty" json:"signedAt,omitempty"` // When the last party signed
+}
+
+// ContractSignature records one party's click-to-sign acceptance
+type ContractSignature struct {
+	UserID       string    `bson:"userId" json:"userId"`
+	Role         string    `bson:"role" json:"role"` // "brand" or "creator"
+	Name         string    `bson:"name" json:"name"` // Typed by the signer
+	DocumentHash string    `bson:"documentHash" json:"documentHash"`
+	IP           string    `bson:"ip" json:"ip"`
+	UserAgent    string    `bson:"userAgent" json:"userAgent"`
+	SignedAt     time.Time `bson:"signedAt" json:"signedAt"`
+}
+
+// MediaAsset is an uploaded file held in blob storage
+type MediaAsset struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	OwnerID       string              `bson:"ownerId" json:"ownerId"` // Clerk ID of the uploader
+	Filename      string              `bson:"filename" json:"filename"`
+	ContentType   string              `bson:"contentType" json:"contentType"`
+	Size          int64               `bson:"size" json:"size"`
+	Width         int                 `bson:"width,omitempty" json:"width,omitempty"`
+	Height        int                 `bson:"height,omitempty" json:"height,omitempty"`
+	Key           string              `bson:"key" json:"-"`
+	ThumbnailKey  string              `bson:"thumbnailKey,omitempty" json:"-"`
+	Purpose       string              `bson:"purpose" json:"purpose"` // "banner", "reference", "deliverable" or "" while unlinked
+	CampaignID    *primitive.ObjectID `bson:"campaignId,omitempty" json:"campaignId,omitempty"`
+	DeliverableID *primitive.ObjectID `bson:"deliverableId,omitempty" json:"deliverableId,omitempty"`
+	CreatedAt     time.Time           `bson:"createdAt" json:"createdAt"`
+
+	URL          string `bson:"-" json:"url,omitempty"` // Signed, short-lived download links
+	ThumbnailURL string `bson:"-" json:"thumbnailUrl,omitempty"`
+}
+
+// CampaignTemplate is a saved campaign form, used to create new campaigns.
+// Templates belong to the user who saved them.
+type CampaignTemplate struct {
+	ID               primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	OwnerID          string              `bson:"ownerId" json:"ownerId"` // Clerk ID of the user who saved it
+	Name             string              `bson:"name" json:"name"`
+	Description      string              `bson:"description" json:"description"`
+	Campaign         CampaignRequest     `bson:"campaign" json:"campaign"`
+	SourceCampaignID *primitive.ObjectID `bson:"sourceCampaignId,omitempty" json:"sourceCampaignId,omitempty"` // Campaign it was saved from, if any
+	CreatedAt        time.Time           `bson:"createdAt" json:"createdAt"`
+	UpdatedAt        time.Time           `bson:"updatedAt" json:"updatedAt"`
+}
+
+// ContractTemplate is a brand's own contract wording, rendered with text/template
+type ContractTemplate struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BrandID   string             `bson:"brandId" json:"brandId"`
+	Name      string             `bson:"name" json:"name"`
+	Body      string             `bson:"body" json:"body"`
+	IsDefault bool               `bson:"isDefault" json:"isDefault"` // Used for the brand's new contracts
+	CreatedAt time.Time          `bson:"createdAt" json:"createdAt"`
+	UpdatedAt time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
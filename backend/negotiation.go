@@ -0,0 +1,415 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Offer states
+const (
+	OfferStatusOpen       = "open"
+	OfferStatusSuperseded = "superseded" // Replaced by a later offer from either side
+	OfferStatusAccepted   = "accepted"
+	OfferStatusDeclined   = "declined"
+)
+
+// Negotiation events pushed on the event stream
+const (
+	EventOfferCreated = "offer.created"
+	EventOfferUpdated = "offer.updated"
+)
+
+// Negotiation notification types
+const (
+	NotificationOfferReceived = "offer.received"
+	NotificationOfferAnswered = "offer.answered"
+)
+
+// normalizeTerms validates offered terms, with the rate in currency, and tidies their fields
+func normalizeTerms(terms *ApplicationTerms, currency string) error {
+	terms.Rate = strings.TrimSpace(terms.Rate)
+	if terms.Rate == "" {
+		return fmt.Errorf("rate is required")
+	}
+	if amount, err := parseAmount(terms.Rate, currency); err != nil || amount <= 0 {
+		return fmt.Errorf("rate must be a single positive amount")
+	}
+	if terms.Deliverables < 0 || terms.Deliverables > maxDeliverablesPerApplication {
+		return fmt.Errorf("deliverables must be between 0 and %d", maxDeliverablesPerApplication)
+	}
+
+	var formats []string
+	for _, format := range terms.ContentFormats {
+		if format = strings.TrimSpace(format); format != "" {
+			formats = append(formats, format)
+		}
+	}
+	terms.ContentFormats = formats
+	terms.Notes = strings.TrimSpace(terms.Notes)
+	return nil
+}
+
+// participantRole reports whether userID is the brand or the creator on an application
+func participantRole(campaign *Campaign, userID string) string {
+	if campaign.BrandID == userID {
+		return "brand"
+	}
+	return "creator"
+}
+
+// createOffer records a new offer in a transaction and tells the other side about it
+func createOffer(ctx context.Context, campaign *Campaign, application *Application, fromUserID string, terms ApplicationTerms, message string) (*Offer, error) {
+	var offer *Offer
+	err := withTransaction(ctx, func(sc mongo.SessionContext) error {
+		var err error
+		offer, err = storeOffer(sc, campaign, application, fromUserID, terms, message)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	announceOffer(campaign, application, offer)
+	return offer, nil
+}
+
+// storeOffer records a new offer, superseding any offer still open on the
+// application. Callers run it inside a transaction so the two writes land together.
+func storeOffer(ctx context.Context, campaign *Campaign, application *Application, fromUserID string, terms ApplicationTerms, message string) (*Offer, error) {
+	collection := database.Collection("offers")
+
+	now := time.Now()
+	_, err := collection.UpdateMany(ctx,
+		bson.M{"applicationId": application.ID, "status": OfferStatusOpen},
+		bson.M{"$set": bson.M{"status": OfferStatusSuperseded, "respondedAt": now}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	offer := Offer{
+		ID:            primitive.NewObjectID(),
+		ApplicationID: application.ID,
+		CampaignID:    campaign.ID,
+		BrandID:       campaign.BrandID,
+		CreatorID:     application.CreatorID,
+		FromUserID:    fromUserID,
+		FromRole:      participantRole(campaign, fromUserID),
+		Terms:         terms,
+		Message:       message,
+		Status:        OfferStatusOpen,
+		CreatedAt:     now,
+	}
+	if _, err := collection.InsertOne(ctx, offer); err != nil {
+		return nil, err
+	}
+	return &offer, nil
+}
+
+// announceOffer pushes a stored offer to both sides and notifies the recipient
+func announceOffer(campaign *Campaign, application *Application, offer *Offer) {
+	publishEvent(EventOfferCreated, *offer, offer.BrandID, offer.CreatorID)
+	notifyOfferReceived(campaign, application, offer)
+}
+
+// offerRecipient returns the user an offer is addressed to
+func offerRecipient(offer *Offer) string {
+	if offer.FromRole == "brand" {
+		return offer.CreatorID
+	}
+	return offer.BrandID
+}
+
+// offerLink points each side at the page where they handle applications
+func offerLink(role string) string {
+	if role == "brand" {
+		return "/brand/campaigns"
+	}
+	return "/creator/dashboard"
+}
+
+// notifyOfferReceived tells the other side that an offer is waiting for them
+func notifyOfferReceived(campaign *Campaign, application *Application, offer *Offer) {
+	from, to := application.CreatorName, "brand"
+	if offer.FromRole == "brand" {
+		from, to = campaign.BrandName, "creator"
+	}
+	notificationService.Notify(offerRecipient(offer), NotificationOfferReceived,
+		"New offer on "+campaign.Title,
+		fmt.Sprintf("%s offered %s for %s.", from, offer.Terms.Rate, campaign.Title),
+		offerLink(to),
+		map[string]interface{}{"applicationId": application.ID.Hex(), "offerId": offer.ID.Hex()},
+	)
+}
+
+// notifyOfferAnswered tells the offer's author that it was accepted or declined
+func notifyOfferAnswered(campaign *Campaign, offer *Offer) {
+	notificationService.Notify(offer.FromUserID, NotificationOfferAnswered,
+		"Your offer was "+offer.Status,
+		fmt.Sprintf("Your offer of %s for %s was %s.", offer.Terms.Rate, campaign.Title, offer.Status),
+		offerLink(offer.FromRole),
+		map[string]interface{}{"applicationId": offer.ApplicationID.Hex(), "offerId": offer.ID.Hex(), "status": offer.Status},
+	)
+}
+
+// checkNegotiable reports whether the application's terms can still change
+func checkNegotiable(application *Application) (int, string) {
+	if application.AgreedTerms != nil {
+		return http.StatusConflict, "Terms have already been agreed for this application"
+	}
+	if application.Status != "pending" {
+		return http.StatusConflict, "Only pending applications can be negotiated"
+	}
+	return http.StatusOK, ""
+}
+
+// checkNoOpenOffer stops an application being approved while an offer is
+// open, since the fee would fall back to the campaign's payment amount
+func checkNoOpenOffer(ctx context.Context, application *Application) (int, string) {
+	count, err := database.Collection("offers").CountDocuments(ctx, bson.M{"applicationId": application.ID, "status": OfferStatusOpen})
+	if err != nil {
+		return http.StatusInternalServerError, "Error checking offers"
+	}
+	if count > 0 {
+		return http.StatusConflict, "An offer is still open on this application; accept or decline it before approving"
+	}
+	return http.StatusOK, ""
+}
+
+// Handlers
+
+// getApplicationOffersHandler lists the negotiation on an application, oldest first
+func getApplicationOffersHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	applicationId := vars["applicationId"]
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	// Convert application ID to ObjectID
+	appObjID, err := primitive.ObjectIDFromHex(applicationId)
+	if err != nil {
+		http.Error(w, "Invalid application ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	application, _, status, msg := loadApplicationForParticipant(ctx, appObjID, getUserIDFromClerkUser(user))
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cursor, err := database.Collection("offers").Find(ctx, bson.M{"applicationId": appObjID}, opts)
+	if err != nil {
+		http.Error(w, "Error fetching offers", http.StatusInternalServerError)
+		return
+	}
+	offers := []Offer{}
+	if err = cursor.All(ctx, &offers); err != nil {
+		http.Error(w, "Error decoding offers", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"offers":      offers,
+		"agreedTerms": application.AgreedTerms,
+		"agreedAt":    application.AgreedAt,
+	})
+}
+
+// createOfferHandler lets the brand or creator make a (counter-)offer on a pending application
+func createOfferHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	applicationId := vars["applicationId"]
+
+	var req struct {
+		ApplicationTerms
+		Message string `json:"message"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	// Convert application ID to ObjectID
+	appObjID, err := primitive.ObjectIDFromHex(applicationId)
+	if err != nil {
+		http.Error(w, "Invalid application ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID := getUserIDFromClerkUser(user)
+	application, campaign, status, msg := loadApplicationForParticipant(ctx, appObjID, userID)
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	if status, msg := checkNegotiable(application); status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	terms := req.ApplicationTerms
+	if err := normalizeTerms(&terms, campaignCurrency(campaign)); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	offer, err := createOffer(ctx, campaign, application, userID, terms, strings.TrimSpace(req.Message))
+	if err != nil {
+		http.Error(w, "Error creating offer", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(offer)
+}
+
+// acceptOfferHandler accepts the open offer and freezes its terms onto the application
+func acceptOfferHandler(w http.ResponseWriter, r *http.Request) {
+	respondToOffer(w, r, OfferStatusAccepted)
+}
+
+// declineOfferHandler declines the open offer; either side may then make a new one
+func declineOfferHandler(w http.ResponseWriter, r *http.Request) {
+	respondToOffer(w, r, OfferStatusDeclined)
+}
+
+// respondToOffer moves the open offer named in the URL to status. Only the
+// side the offer was made to can respond.
+func respondToOffer(w http.ResponseWriter, r *http.Request, status string) {
+	vars := mux.Vars(r)
+	applicationId := vars["applicationId"]
+	offerId := vars["offerId"]
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	// Convert IDs to ObjectIDs
+	appObjID, err := primitive.ObjectIDFromHex(applicationId)
+	if err != nil {
+		http.Error(w, "Invalid application ID", http.StatusBadRequest)
+		return
+	}
+	offerOID, err := primitive.ObjectIDFromHex(offerId)
+	if err != nil {
+		http.Error(w, "Invalid offer ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID := getUserIDFromClerkUser(user)
+	application, campaign, httpStatus, msg := loadApplicationForParticipant(ctx, appObjID, userID)
+	if httpStatus != http.StatusOK {
+		http.Error(w, msg, httpStatus)
+		return
+	}
+
+	if httpStatus, msg := checkNegotiable(application); httpStatus != http.StatusOK {
+		http.Error(w, msg, httpStatus)
+		return
+	}
+
+	offers := database.Collection("offers")
+	var offer Offer
+	err = offers.FindOne(ctx, bson.M{"_id": offerOID, "applicationId": appObjID}).Decode(&offer)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			http.Error(w, "Offer not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "Error fetching offer", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if offerRecipient(&offer) != userID {
+		http.Error(w, "Only the other side can respond to this offer", http.StatusForbidden)
+		return
+	}
+
+	// Update only if the offer is still open, so a counter-offer made in the
+	// meantime cannot be accepted by mistake. Accepting also freezes the terms
+	// on the application in the same transaction.
+	var conflict string
+	err = withTransaction(ctx, func(sc mongo.SessionContext) error {
+		now := time.Now()
+		err := offers.FindOneAndUpdate(sc,
+			bson.M{"_id": offerOID, "status": OfferStatusOpen},
+			bson.M{"$set": bson.M{"status": status, "respondedAt": now}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&offer)
+		if err == mongo.ErrNoDocuments {
+			conflict = "This offer is no longer open"
+			return errAbortTransaction
+		}
+		if err != nil || status != OfferStatusAccepted {
+			return err
+		}
+
+		err = database.Collection("applications").FindOneAndUpdate(sc,
+			bson.M{"_id": appObjID, "status": "pending", "agreedTerms": bson.M{"$exists": false}},
+			bson.M{"$set": bson.M{"agreedTerms": offer.Terms, "agreedAt": now, "updatedAt": now}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(application)
+		if err == mongo.ErrNoDocuments {
+			conflict = "The application changed while accepting; reload and try again"
+			return errAbortTransaction
+		}
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, errAbortTransaction) {
+			http.Error(w, conflict, http.StatusConflict)
+		} else {
+			http.Error(w, "Error updating offer", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	publishEvent(EventOfferUpdated, offer, offer.BrandID, offer.CreatorID)
+	notifyOfferAnswered(campaign, &offer)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"offer":       offer,
+		"application": application,
+	})
+}
@@ -0,0 +1,792 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/http"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Notification types
+const (
+	NotificationApplicationCreated       = "application.created"
+	NotificationApplicationStatusChanged = "application.status_changed"
+	NotificationApplicationUpdated       = "application.updated"
+	NotificationApplicationWithdrawn     = "application.withdrawn"
+	NotificationDeadlineApproaching      = "campaign.deadline_approaching"
+	NotificationCampaignEnding           = "campaign.ended"
+)
+
+// notificationTypes lists every notification type a user can turn off
+var notificationTypes = []string{
+	NotificationApplicationCreated, NotificationApplicationStatusChanged,
+	NotificationApplicationUpdated, NotificationApplicationWithdrawn,
+	NotificationDeadlineApproaching, NotificationCampaignEnding,
+	NotificationContractReady, NotificationContractSigned,
+	NotificationDeliverableSubmitted, NotificationDeliverableReviewed, NotificationDeliverableDue,
+	NotificationOfferReceived, NotificationOfferAnswered,
+}
+
+// EventNotificationCreated is pushed on the event stream when an inbox entry is added
+const EventNotificationCreated = "notification.created"
+
+// Background delivery is done by a fixed pool of workers reading a bounded queue
+const (
+	notificationWorkers   = 4
+	notificationQueueSize = 1000
+)
+
+//go:embed templates/notifications/*.tmpl
+var notificationTemplateFS embed.FS
+
+// NotificationRecipient is the user a notification is delivered to
+type NotificationRecipient struct {
+	UserID string
+	Name   string
+	Email  string
+}
+
+// NotificationChannel delivers notifications outside the in-app inbox
+type NotificationChannel interface {
+	Name() string
+	Send(ctx context.Context, recipient NotificationRecipient, notification *Notification) error
+}
+
+// NotificationService stores inbox entries and fans notifications out to delivery channels
+type NotificationService struct {
+	channels []NotificationChannel
+
+	// handle delivers a queued notification; it is deliver unless replaced in tests
+	handle func(ctx context.Context, notification *Notification) error
+
+	mu      sync.RWMutex // Held while enqueueing, so Wait cannot close the queue under Notify
+	queue   chan *Notification
+	closed  bool
+	workers sync.WaitGroup
+}
+
+// notificationService is the process-wide notification service, set up by initNotifications
+var notificationService = &NotificationService{}
+
+// notificationTemplateData is passed to the email templates
+type notificationTemplateData struct {
+	AppName       string
+	RecipientName string
+	Title         string
+	Body          string
+	Link          string
+}
+
+// initNotifications configures delivery channels from the environment.
+// SMTP is used when SMTP_HOST is set; otherwise notifications are written
+// to NOTIFICATION_LOG_FILE (or the process log) for local development.
+func initNotifications() error {
+	htmlTemplate, err := htmltemplate.ParseFS(notificationTemplateFS, "templates/notifications/email.html.tmpl")
+	if err != nil {
+		return fmt.Errorf("failed to parse HTML email template: %w", err)
+	}
+	textTemplate, err := texttemplate.ParseFS(notificationTemplateFS, "templates/notifications/email.txt.tmpl")
+	if err != nil {
+		return fmt.Errorf("failed to parse text email template: %w", err)
+	}
+
+	// Keyed notifications are stored once however often their job is retried
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err = database.Collection("notifications").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "key", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"key": bson.M{"$exists": true}}),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create notification indexes: %w", err)
+	}
+
+	settings := appConfig.Notifications
+	if host := settings.SMTPHost; host != "" {
+		port := settings.SMTPPort
+		if port == "" {
+			port = "587"
+		}
+		from := settings.SMTPFrom
+		if from == "" {
+			return fmt.Errorf("notifications.smtpFrom (SMTP_FROM) is required when an SMTP host is set")
+		}
+		notificationService.channels = append(notificationService.channels, &SMTPChannel{
+			Host:     host,
+			Port:     port,
+			Username: settings.SMTPUsername,
+			Password: settings.SMTPPassword,
+			From:     from,
+			HTML:     htmlTemplate,
+			Text:     textTemplate,
+		})
+		log.Println("Notifications: SMTP delivery enabled via", host)
+		notificationService.start(notificationWorkers, notificationQueueSize)
+		return nil
+	}
+
+	notificationService.channels = append(notificationService.channels, &LogChannel{
+		Path: settings.LogFile,
+		Text: textTemplate,
+	})
+	log.Println("Notifications: SMTP not configured, using log sink")
+	notificationService.start(notificationWorkers, notificationQueueSize)
+	return nil
+}
+
+// start launches the delivery workers
+func (s *NotificationService) start(workers, queueSize int) {
+	if s.handle == nil {
+		s.handle = s.deliver
+	}
+	s.queue = make(chan *Notification, queueSize)
+	for i := 0; i < workers; i++ {
+		s.workers.Add(1)
+		go func() {
+			defer s.workers.Done()
+			for notification := range s.queue {
+				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				if err := s.handle(ctx, notification); err != nil {
+					log.Printf("Notifications: error delivering %s to %s: %v", notification.Type, notification.UserID, err)
+				}
+				cancel()
+			}
+		}()
+	}
+}
+
+// Notify records a notification for userID and delivers it in the background.
+// It never blocks: when the queue is full, or the service has stopped, the
+// notification is dropped and logged.
+func (s *NotificationService) Notify(userID, notificationType, title, body, link string, data map[string]interface{}) {
+	if userID == "" {
+		return
+	}
+
+	notification := &Notification{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Type:      notificationType,
+		Title:     title,
+		Body:      body,
+		Link:      link,
+		Data:      data,
+		CreatedAt: time.Now(),
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.queue == nil || s.closed {
+		log.Printf("Notifications: service stopped, dropping %s to %s", notificationType, userID)
+		return
+	}
+	select {
+	case s.queue <- notification:
+	default:
+		log.Printf("Notifications: queue full, dropping %s to %s", notificationType, userID)
+	}
+}
+
+// Send records and delivers a notification before returning, for callers
+// such as jobs that retry on failure. key identifies the notification so a
+// retry does not store or email it a second time.
+func (s *NotificationService) Send(ctx context.Context, key, userID, notificationType, title, body, link string, data map[string]interface{}) error {
+	if userID == "" {
+		return nil
+	}
+
+	return s.deliver(ctx, &Notification{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Type:      notificationType,
+		Title:     title,
+		Body:      body,
+		Link:      link,
+		Data:      data,
+		Key:       key,
+		CreatedAt: time.Now(),
+	})
+}
+
+// Wait stops accepting notifications and blocks until the queued ones have been delivered
+func (s *NotificationService) Wait() {
+	s.mu.Lock()
+	if s.queue != nil && !s.closed {
+		close(s.queue)
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	s.workers.Wait()
+}
+
+// deliver honours the recipient's preferences, stores the inbox entry and
+// sends it through every channel, returning the errors of any that failed
+func (s *NotificationService) deliver(ctx context.Context, notification *Notification) error {
+	prefs, err := getNotificationPreferences(ctx, notification.UserID)
+	if err != nil {
+		return err
+	}
+	for _, disabled := range prefs.DisabledTypes {
+		if disabled == notification.Type {
+			return nil
+		}
+	}
+
+	if prefs.InApp {
+		stored, err := storeNotification(ctx, notification)
+		if err != nil {
+			return fmt.Errorf("error storing notification: %w", err)
+		}
+		if stored {
+			publishEvent(EventNotificationCreated, notification, notification.UserID)
+		}
+	}
+
+	if !prefs.Email || len(s.channels) == 0 {
+		return nil
+	}
+	if notification.Key != "" {
+		sent, err := database.Collection("notification_deliveries").CountDocuments(ctx, bson.M{"_id": notification.Key})
+		if err != nil {
+			return fmt.Errorf("error checking earlier delivery: %w", err)
+		}
+		if sent > 0 {
+			return nil
+		}
+	}
+
+	var user User
+	err = database.Collection("users").FindOne(ctx, bson.M{"clerkId": notification.UserID}).Decode(&user)
+	if err != nil {
+		return fmt.Errorf("error fetching recipient: %w", err)
+	}
+	recipient := NotificationRecipient{UserID: user.ClerkID, Name: user.Name, Email: user.Email}
+
+	var errs []error
+	for _, channel := range s.channels {
+		if err := channel.Send(ctx, recipient, notification); err != nil {
+			errs = append(errs, fmt.Errorf("%s channel: %w", channel.Name(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if notification.Key != "" {
+		_, err := database.Collection("notification_deliveries").InsertOne(ctx, bson.M{"_id": notification.Key, "sentAt": time.Now()})
+		if err != nil && !mongo.IsDuplicateKeyError(err) {
+			log.Printf("Notifications: error recording delivery of %s: %v", notification.Key, err)
+		}
+	}
+	return nil
+}
+
+// storeNotification adds the notification to the inbox, reporting false if
+// a notification with the same key is already there
+func storeNotification(ctx context.Context, notification *Notification) (bool, error) {
+	if notification.Key == "" {
+		_, err := database.Collection("notifications").InsertOne(ctx, notification)
+		return err == nil, err
+	}
+
+	result, err := database.Collection("notifications").UpdateOne(ctx,
+		bson.M{"key": notification.Key},
+		bson.M{"$setOnInsert": notification},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount > 0, nil
+}
+
+// defaultNotificationPreferences are used until a user saves their own
+func defaultNotificationPreferences(userID string) NotificationPreferences {
+	return NotificationPreferences{
+		UserID:        userID,
+		InApp:         true,
+		Email:         true,
+		DisabledTypes: []string{},
+	}
+}
+
+// getNotificationPreferences loads a user's preferences, falling back to the defaults
+func getNotificationPreferences(ctx context.Context, userID string) (NotificationPreferences, error) {
+	var prefs NotificationPreferences
+	err := database.Collection("notification_preferences").FindOne(ctx, bson.M{"userId": userID}).Decode(&prefs)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return defaultNotificationPreferences(userID), nil
+		}
+		return prefs, fmt.Errorf("error fetching notification preferences: %w", err)
+	}
+	if prefs.DisabledTypes == nil {
+		prefs.DisabledTypes = []string{}
+	}
+	return prefs, nil
+}
+
+// notificationLink builds an absolute frontend URL for email links
+func notificationLink(path string) string {
+	if path == "" || strings.HasPrefix(path, "http") {
+		return path
+	}
+	return strings.TrimRight(appConfig.App.FrontendURL, "/") + path
+}
+
+// SMTPChannel sends multipart HTML/text email through an SMTP relay
+type SMTPChannel struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	HTML     *htmltemplate.Template
+	Text     *texttemplate.Template
+}
+
+// Name implements NotificationChannel
+func (c *SMTPChannel) Name() string {
+	return "smtp"
+}
+
+// Send implements NotificationChannel
+func (c *SMTPChannel) Send(ctx context.Context, recipient NotificationRecipient, notification *Notification) error {
+	if recipient.Email == "" {
+		return nil
+	}
+
+	data := notificationTemplateData{
+		AppName:       GetDisplayName(),
+		RecipientName: recipient.Name,
+		Title:         notification.Title,
+		Body:          notification.Body,
+		Link:          notificationLink(notification.Link),
+	}
+
+	var textBody, htmlBody bytes.Buffer
+	if err := c.Text.Execute(&textBody, data); err != nil {
+		return fmt.Errorf("error rendering text template: %w", err)
+	}
+	if err := c.HTML.Execute(&htmlBody, data); err != nil {
+		return fmt.Errorf("error rendering HTML template: %w", err)
+	}
+
+	var msg bytes.Buffer
+	writer := multipart.NewWriter(&msg)
+	fmt.Fprintf(&msg, "From: %s\r\n", c.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", recipient.Email)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", notification.Title))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", writer.Boundary())
+
+	for _, part := range []struct {
+		contentType string
+		body        []byte
+	}{
+		{"text/plain; charset=UTF-8", textBody.Bytes()},
+		{"text/html; charset=UTF-8", htmlBody.Bytes()},
+	} {
+		w, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write(part.body); err != nil {
+			return err
+		}
+		qp.Close()
+	}
+	writer.Close()
+
+	var auth smtp.Auth
+	if c.Username != "" {
+		auth = smtp.PlainAuth("", c.Username, c.Password, c.Host)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- smtp.SendMail(c.Host+":"+c.Port, auth, c.From, []string{recipient.Email}, msg.Bytes())
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// LogChannel writes rendered notifications to a file or the process log (for development)
+type LogChannel struct {
+	Path string
+	Text *texttemplate.Template
+	mu   sync.Mutex
+}
+
+// Name implements NotificationChannel
+func (c *LogChannel) Name() string {
+	return "log"
+}
+
+// Send implements NotificationChannel
+func (c *LogChannel) Send(ctx context.Context, recipient NotificationRecipient, notification *Notification) error {
+	var body bytes.Buffer
+	err := c.Text.Execute(&body, notificationTemplateData{
+		AppName:       GetDisplayName(),
+		RecipientName: recipient.Name,
+		Title:         notification.Title,
+		Body:          notification.Body,
+		Link:          notificationLink(notification.Link),
+	})
+	if err != nil {
+		return fmt.Errorf("error rendering text template: %w", err)
+	}
+
+	entry := fmt.Sprintf("=== %s to %s <%s> [%s]\n%s\n", time.Now().Format(time.RFC3339), recipient.Name, recipient.Email, notification.Type, body.String())
+
+	if c.Path == "" {
+		log.Print(entry)
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, err := os.OpenFile(c.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(entry)
+	return err
+}
+
+// Notification triggers
+
+// notifyApplicationCreated tells the brand that a creator applied
+func notifyApplicationCreated(campaign *Campaign, application *Application) {
+	notificationService.Notify(campaign.BrandID, NotificationApplicationCreated,
+		"New application for "+campaign.Title,
+		fmt.Sprintf("%s applied to %s on %s.", application.CreatorName, campaign.Title, application.Platform),
+		"/brand/applications",
+		map[string]interface{}{"applicationId": application.ID.Hex(), "campaignId": campaign.ID.Hex()},
+	)
+}
+
+// notifyApplicationUpdated tells the brand that a creator edited their application
+func notifyApplicationUpdated(campaign *Campaign, application *Application) {
+	notificationService.Notify(campaign.BrandID, NotificationApplicationUpdated,
+		"Application updated for "+campaign.Title,
+		fmt.Sprintf("%s updated their application to %s.", application.CreatorName, campaign.Title),
+		"/brand/applications",
+		map[string]interface{}{"applicationId": application.ID.Hex(), "campaignId": campaign.ID.Hex()},
+	)
+}
+
+// notifyApplicationWithdrawn tells the brand that a creator withdrew their application
+func notifyApplicationWithdrawn(campaign *Campaign, application *Application) {
+	notificationService.Notify(campaign.BrandID, NotificationApplicationWithdrawn,
+		"Application withdrawn from "+campaign.Title,
+		fmt.Sprintf("%s withdrew their application to %s.", application.CreatorName, campaign.Title),
+		"/brand/applications",
+		map[string]interface{}{"applicationId": application.ID.Hex(), "campaignId": campaign.ID.Hex()},
+	)
+}
+
+// notifyApplicationStatusChanged tells the creator that the brand reviewed their application
+func notifyApplicationStatusChanged(application *Application) {
+	notificationService.Notify(application.CreatorID, NotificationApplicationStatusChanged,
+		"Your application was "+application.Status,
+		fmt.Sprintf("Your application to %s is now %s.", application.CampaignName, application.Status),
+		"/creator/dashboard",
+		map[string]interface{}{"applicationId": application.ID.Hex(), "status": application.Status},
+	)
+}
+
+// notifyDeadlineApproaching reminds the brand and approved creators that a
+// campaign ends soon. It delivers before returning so the reminder job can
+// retry; key identifies the job, and recipients already reached are skipped.
+func notifyDeadlineApproaching(ctx context.Context, key string, campaign *Campaign) error {
+	body := fmt.Sprintf("%s ends on %s. Make sure all deliverables are submitted in time.", campaign.Title, campaign.EndDate)
+	data := map[string]interface{}{"campaignId": campaign.ID.Hex(), "endDate": campaign.EndDate}
+
+	creatorIDs, err := database.Collection("applications").Distinct(ctx, "creatorId", bson.M{"campaignId": campaign.ID, "status": "approved"})
+	if err != nil {
+		return fmt.Errorf("error fetching approved creators: %w", err)
+	}
+
+	var errs []error
+	err = notificationService.Send(ctx, key+":"+campaign.BrandID, campaign.BrandID, NotificationDeadlineApproaching, campaign.Title+" ends soon", body, "/brand/campaigns", data)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, id := range creatorIDs {
+		creatorID, _ := id.(string)
+		err := notificationService.Send(ctx, key+":"+creatorID, creatorID, NotificationDeadlineApproaching, campaign.Title+" ends soon", body, "/creator/dashboard", data)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// notifyCampaignEnded tells the brand and approved creators that a campaign has completed
+func notifyCampaignEnded(ctx context.Context, campaign *Campaign) {
+	body := fmt.Sprintf("%s has ended.", campaign.Title)
+	data := map[string]interface{}{"campaignId": campaign.ID.Hex()}
+
+	notificationService.Notify(campaign.BrandID, NotificationCampaignEnding, campaign.Title+" has ended", body, "/brand/campaigns", data)
+	for _, creatorID := range approvedCreatorIDs(ctx, campaign.ID) {
+		notificationService.Notify(creatorID, NotificationCampaignEnding, campaign.Title+" has ended", body, "/creator/dashboard", data)
+	}
+}
+
+// approvedCreatorIDs returns the Clerk IDs of creators approved for a campaign
+func approvedCreatorIDs(ctx context.Context, campaignID primitive.ObjectID) []string {
+	ids, err := database.Collection("applications").Distinct(ctx, "creatorId", bson.M{"campaignId": campaignID, "status": "approved"})
+	if err != nil {
+		log.Println("Error fetching approved creators:", err)
+		return nil
+	}
+	var creatorIDs []string
+	for _, id := range ids {
+		if creatorID, ok := id.(string); ok {
+			creatorIDs = append(creatorIDs, creatorID)
+		}
+	}
+	return creatorIDs
+}
+
+// Inbox handlers
+
+// getNotificationsHandler lists the signed-in user's notifications, newest first
+func getNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	userID := getUserIDFromClerkUser(user)
+	filter := bson.M{"userId": userID}
+	if r.URL.Query().Get("unread") == "true" {
+		filter["read"] = false
+	}
+
+	limit := int64(50)
+	if l, err := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 64); err == nil && l > 0 && l <= 200 {
+		limit = l
+	}
+
+	collection := database.Collection("notifications")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(limit)
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		http.Error(w, "Error fetching notifications", http.StatusInternalServerError)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	notifications := []Notification{}
+	if err = cursor.All(ctx, &notifications); err != nil {
+		http.Error(w, "Error decoding notifications", http.StatusInternalServerError)
+		return
+	}
+
+	unread, err := collection.CountDocuments(ctx, bson.M{"userId": userID, "read": false})
+	if err != nil {
+		http.Error(w, "Error counting notifications", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"notifications": notifications,
+		"unread":        unread,
+	})
+}
+
+// markNotificationReadHandler marks a single notification as read
+func markNotificationReadHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	notificationId := vars["notificationId"]
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	notificationOID, err := primitive.ObjectIDFromHex(notificationId)
+	if err != nil {
+		http.Error(w, "Invalid notification ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	result, err := database.Collection("notifications").UpdateOne(ctx,
+		bson.M{"_id": notificationOID, "userId": getUserIDFromClerkUser(user)},
+		bson.M{"$set": bson.M{"read": true, "readAt": now}},
+	)
+	if err != nil {
+		http.Error(w, "Error updating notification", http.StatusInternalServerError)
+		return
+	}
+	if result.MatchedCount == 0 {
+		http.Error(w, "Notification not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Notification marked as read"})
+}
+
+// markAllNotificationsReadHandler marks every unread notification as read
+func markAllNotificationsReadHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := database.Collection("notifications").UpdateMany(ctx,
+		bson.M{"userId": getUserIDFromClerkUser(user), "read": false},
+		bson.M{"$set": bson.M{"read": true, "readAt": time.Now()}},
+	)
+	if err != nil {
+		http.Error(w, "Error updating notifications", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Notifications marked as read",
+		"updated": result.ModifiedCount,
+	})
+}
+
+// getNotificationPreferencesHandler returns the signed-in user's notification preferences
+func getNotificationPreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	prefs, err := getNotificationPreferences(ctx, getUserIDFromClerkUser(user))
+	if err != nil {
+		http.Error(w, "Error fetching notification preferences", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prefs)
+}
+
+// updateNotificationPreferencesHandler saves the signed-in user's notification preferences
+func updateNotificationPreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	// Pointers tell omitted fields apart from false, so a partial body only changes what it sends
+	var req struct {
+		InApp         *bool     `json:"inApp"`
+		Email         *bool     `json:"email"`
+		DisabledTypes *[]string `json:"disabledTypes"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID := getUserIDFromClerkUser(user)
+	prefs, err := getNotificationPreferences(ctx, userID)
+	if err != nil {
+		http.Error(w, "Error fetching notification preferences", http.StatusInternalServerError)
+		return
+	}
+
+	if req.InApp != nil {
+		prefs.InApp = *req.InApp
+	}
+	if req.Email != nil {
+		prefs.Email = *req.Email
+	}
+	if req.DisabledTypes != nil {
+		for _, notificationType := range *req.DisabledTypes {
+			if !slices.Contains(notificationTypes, notificationType) {
+				http.Error(w, fmt.Sprintf("Unknown notification type %q", notificationType), http.StatusBadRequest)
+				return
+			}
+		}
+		prefs.DisabledTypes = *req.DisabledTypes
+	}
+	if prefs.DisabledTypes == nil {
+		prefs.DisabledTypes = []string{}
+	}
+	prefs.UserID = userID
+	prefs.UpdatedAt = time.Now()
+
+	_, err = database.Collection("notification_preferences").ReplaceOne(ctx,
+		bson.M{"userId": userID}, prefs, options.Replace().SetUpsert(true))
+	if err != nil {
+		http.Error(w, "Error saving notification preferences", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prefs)
+}
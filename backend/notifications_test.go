@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// recordingService returns a service whose deliveries are recorded instead of stored
+func recordingService() (*NotificationService, func() []string) {
+	var mu sync.Mutex
+	var delivered []string
+	s := &NotificationService{
+		handle: func(ctx context.Context, notification *Notification) error {
+			mu.Lock()
+			defer mu.Unlock()
+			delivered = append(delivered, notification.UserID)
+			return nil
+		},
+	}
+	return s, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), delivered...)
+	}
+}
+
+func TestNotificationServiceDeliversQueued(t *testing.T) {
+	s, delivered := recordingService()
+	s.start(2, 10)
+
+	for _, userID := range []string{"user_1", "user_2", "", "user_3"} {
+		s.Notify(userID, NotificationApplicationCreated, "title", "body", "/", nil)
+	}
+	s.Wait()
+
+	if got := delivered(); len(got) != 3 {
+		t.Errorf("delivered %q, want 3 notifications", got)
+	}
+}
+
+func TestNotificationServiceQueueFull(t *testing.T) {
+	s, _ := recordingService()
+	s.start(0, 2)
+
+	for i := 0; i < 5; i++ {
+		s.Notify("user_1", NotificationApplicationCreated, "title", "body", "/", nil)
+	}
+	if len(s.queue) != 2 {
+		t.Errorf("queued %d notifications, want 2", len(s.queue))
+	}
+	s.Wait()
+}
+
+func TestNotificationServiceNotifyAfterWait(t *testing.T) {
+	s, delivered := recordingService()
+	s.start(1, 10)
+	s.Wait()
+	s.Wait() // A second Wait must not close the queue again
+
+	s.Notify("user_1", NotificationApplicationCreated, "title", "body", "/", nil)
+	if got := delivered(); len(got) != 0 {
+		t.Errorf("delivered %q after Wait", got)
+	}
+}
+
+func TestNotificationServiceNotifyDuringWait(t *testing.T) {
+	s, _ := recordingService()
+	s.start(2, 100)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				s.Notify("user_1", NotificationApplicationCreated, "title", "body", "/", nil)
+			}
+		}()
+	}
+	s.Wait()
+	wg.Wait()
+}
+
+func TestNotificationServiceNotStarted(t *testing.T) {
+	s, delivered := recordingService()
+	s.Notify("user_1", NotificationApplicationCreated, "title", "body", "/", nil)
+	s.Wait()
+	if got := delivered(); len(got) != 0 {
+		t.Errorf("delivered %q without workers", got)
+	}
+}
@@ -0,0 +1,961 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Ledger transaction kinds
+const (
+	LedgerKindCommitment = "commitment" // Fee agreed when an application is approved
+	LedgerKindMilestone  = "milestone"  // Part of the commitment earned by a published deliverable
+	LedgerKindBonus      = "bonus"      // Performance bonus or commission on top of the fee
+	LedgerKindPayout     = "payout"     // Money sent to the creator
+	LedgerKindRelease    = "release"    // Unearned commitment cancelled when an approval is reversed
+)
+
+// Ledger buckets. Creator balances are the debit side and brand balances
+// the credit side of the same postings, so every transaction sums to zero.
+const (
+	BucketCommitted  = "committed"  // Agreed but not yet earned
+	BucketReceivable = "receivable" // Earned and waiting to be paid
+	BucketPaid       = "paid"       // Paid out
+)
+
+// Payout states
+const (
+	PayoutStatusPending = "pending"
+	PayoutStatusPaid    = "paid"
+	PayoutStatusFailed  = "failed"
+)
+
+// defaultCurrency is used for campaigns without a currency
+const defaultCurrency = "USD"
+
+// moneyPattern finds the first amount in free text such as "$1,500.00"
+var moneyPattern = regexp.MustCompile(`\d[\d,]*(?:\.\d+)?`)
+
+// parseMoney converts a free-text amount into minor units of currency (e.g.
+// cents for USD, yen for JPY). Signed amounts are rejected rather than read as
+// their absolute value.
+func parseMoney(s, currency string) (int64, error) {
+	loc := moneyPattern.FindStringIndex(s)
+	if loc == nil {
+		return 0, fmt.Errorf("no amount found in %q", s)
+	}
+	if strings.ContainsAny(s[:loc[0]], "-+\u2212") {
+		return 0, fmt.Errorf("signed amount %q is not allowed", s)
+	}
+	value, err := strconv.ParseFloat(strings.ReplaceAll(s[loc[0]:loc[1]], ",", ""), 64)
+	if err != nil {
+		return 0, err
+	}
+	return int64(math.Round(value * math.Pow10(minorUnits(currency)))), nil
+}
+
+// moneyRangePrefixes introduce an open range such as the campaign form's "Under $500"
+var moneyRangePrefixes = []string{"under", "over", "up to", "less than", "more than", "from"}
+
+// moneyCeilingPrefixes are the moneyRangePrefixes that give an upper bound
+var moneyCeilingPrefixes = []string{"under", "up to", "less than"}
+
+// isMoneyRange reports whether s describes a range rather than one amount,
+// e.g. the campaign form's "Under $500", "$1,000-$2,500" and "$25,000+"
+func isMoneyRange(s string) bool {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(moneyPattern.FindAllString(s, 2)) > 1 || strings.HasSuffix(s, "+") {
+		return true
+	}
+	for _, prefix := range moneyRangePrefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMoneyRange reads a range such as "$1,000-$2,500", "Under $500" or
+// "$25,000+" into minor units. high is 0 when the range has no upper bound.
+func parseMoneyRange(s, currency string) (low, high int64, err error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if !isMoneyRange(s) {
+		return 0, 0, fmt.Errorf("%q is not a range", s)
+	}
+	locs := moneyPattern.FindAllStringIndex(s, -1)
+	switch len(locs) {
+	case 1:
+		amount, err := parseMoney(s, currency)
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, prefix := range moneyCeilingPrefixes {
+			if strings.HasPrefix(s, prefix) {
+				low, high = 0, amount
+			}
+		}
+		if high == 0 {
+			low = amount
+		}
+	case 2:
+		if low, err = parseMoney(s[:locs[0][1]], currency); err != nil {
+			return 0, 0, err
+		}
+		if high, err = parseMoney(s[locs[1][0]:], currency); err != nil {
+			return 0, 0, err
+		}
+		if high < low {
+			return 0, 0, fmt.Errorf("range %q ends below its start", s)
+		}
+	default:
+		return 0, 0, fmt.Errorf("no range found in %q", s)
+	}
+	if low <= 0 && high <= 0 {
+		return 0, 0, fmt.Errorf("range %q holds no positive amount", s)
+	}
+	return low, high, nil
+}
+
+// parseAmount is parseMoney for text that must hold exactly one amount, such
+// as a fee. Ranges are rejected rather than read as one of their bounds.
+func parseAmount(s, currency string) (int64, error) {
+	if isMoneyRange(s) {
+		return 0, fmt.Errorf("%q is a range, not a single amount", s)
+	}
+	return parseMoney(s, currency)
+}
+
+// formatMoney renders minor units as a decimal string with the currency's number of decimals
+func formatMoney(amount int64, currency string) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	decimals := minorUnits(currency)
+	if decimals == 0 {
+		return fmt.Sprintf("%s%d", sign, amount)
+	}
+	scale := int64(math.Pow10(decimals))
+	return fmt.Sprintf("%s%d.%0*d", sign, amount/scale, decimals, amount%scale)
+}
+
+// campaignCurrency returns the campaign's currency code, defaulting to USD
+func campaignCurrency(campaign *Campaign) string {
+	currency := strings.ToUpper(strings.TrimSpace(campaign.Currency))
+	if currency == "" {
+		return defaultCurrency
+	}
+	return currency
+}
+
+// ledgerEntry builds an entry for one party's bucket
+func ledgerEntry(ownerType, ownerID, bucket string, amount int64) LedgerEntry {
+	return LedgerEntry{
+		Account:   fmt.Sprintf("%s:%s:%s", ownerType, ownerID, bucket),
+		OwnerType: ownerType,
+		OwnerID:   ownerID,
+		Bucket:    bucket,
+		Amount:    amount,
+	}
+}
+
+// ledgerEntries returns the postings for a transaction of the given kind.
+// Creator accounts are debited and brand accounts credited for the same
+// amounts, so each transaction balances.
+func ledgerEntries(kind, brandID, creatorID string, amount int64) []LedgerEntry {
+	move := func(from, to string) []LedgerEntry {
+		var entries []LedgerEntry
+		if from != "" {
+			entries = append(entries,
+				ledgerEntry("creator", creatorID, from, -amount),
+				ledgerEntry("brand", brandID, from, amount),
+			)
+		}
+		return append(entries,
+			ledgerEntry("creator", creatorID, to, amount),
+			ledgerEntry("brand", brandID, to, -amount),
+		)
+	}
+
+	switch kind {
+	case LedgerKindRelease:
+		return []LedgerEntry{
+			ledgerEntry("creator", creatorID, BucketCommitted, -amount),
+			ledgerEntry("brand", brandID, BucketCommitted, amount),
+		}
+	case LedgerKindCommitment:
+		return move("", BucketCommitted)
+	case LedgerKindMilestone:
+		return move(BucketCommitted, BucketReceivable)
+	case LedgerKindBonus:
+		return move("", BucketReceivable)
+	case LedgerKindPayout:
+		return move(BucketReceivable, BucketPaid)
+	}
+	return nil
+}
+
+// recordLedgerTransaction stores a balanced transaction. If a transaction
+// with the same idempotency key exists it is returned instead and created
+// is false.
+func recordLedgerTransaction(ctx context.Context, tx *LedgerTransaction) (*LedgerTransaction, bool, error) {
+	var sum int64
+	for _, entry := range tx.Entries {
+		sum += entry.Amount
+	}
+	if len(tx.Entries) == 0 || sum != 0 {
+		return nil, false, fmt.Errorf("ledger transaction %s is not balanced", tx.IdempotencyKey)
+	}
+
+	if tx.ID.IsZero() {
+		tx.ID = primitive.NewObjectID()
+	}
+	if tx.CreatedAt.IsZero() {
+		tx.CreatedAt = time.Now()
+	}
+
+	collection := database.Collection("ledger_transactions")
+	_, err := collection.InsertOne(ctx, tx)
+	if mongo.IsDuplicateKeyError(err) {
+		var existing LedgerTransaction
+		if err := collection.FindOne(ctx, bson.M{"idempotencyKey": tx.IdempotencyKey}).Decode(&existing); err != nil {
+			return nil, false, err
+		}
+		return &existing, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return tx, true, nil
+}
+
+// newApplicationTransaction builds a ledger transaction for an application
+func newApplicationTransaction(kind, key string, campaign *Campaign, application *Application, amount int64, description string) *LedgerTransaction {
+	return &LedgerTransaction{
+		IdempotencyKey: key,
+		Kind:           kind,
+		ApplicationID:  application.ID,
+		CampaignID:     campaign.ID,
+		BrandID:        campaign.BrandID,
+		CreatorID:      application.CreatorID,
+		Currency:       campaignCurrency(campaign),
+		Amount:         amount,
+		Description:    description,
+		Entries:        ledgerEntries(kind, campaign.BrandID, application.CreatorID, amount),
+	}
+}
+
+// commitmentKey identifies the commitment of the application's current approval
+func commitmentKey(application *Application) string {
+	return fmt.Sprintf("commitment:%s:%d", application.ID.Hex(), application.ApprovalRound)
+}
+
+// recordCommitment books the agreed fee as owed to an approved creator
+func recordCommitment(ctx context.Context, campaign *Campaign, application *Application) error {
+	amount := applicationFee(campaign, application)
+	if amount <= 0 {
+		return nil // No fixed fee (e.g. commission or product only)
+	}
+
+	tx := newApplicationTransaction(LedgerKindCommitment, commitmentKey(application), campaign, application, amount, "Fee for "+campaign.Title)
+	_, _, err := recordLedgerTransaction(ctx, tx)
+	return err
+}
+
+// releaseCommitment cancels whatever part of the commitment has not been earned yet
+func releaseCommitment(ctx context.Context, campaign *Campaign, application *Application) error {
+	committed, err := applicationCommitted(ctx, application, campaignCurrency(campaign))
+	if err != nil || committed <= 0 {
+		return err
+	}
+
+	key := fmt.Sprintf("release:%s:%d", application.ID.Hex(), application.ApprovalRound)
+	tx := newApplicationTransaction(LedgerKindRelease, key, campaign, application, committed, "Approval withdrawn for "+campaign.Title)
+	_, _, err = recordLedgerTransaction(ctx, tx)
+	return err
+}
+
+// recordMilestone moves a published deliverable's share of the commitment to receivable
+func recordMilestone(ctx context.Context, deliverable *Deliverable) error {
+	var commitment LedgerTransaction
+	err := database.Collection("ledger_transactions").FindOne(ctx, bson.M{
+		"applicationId": deliverable.ApplicationID,
+		"kind":          LedgerKindCommitment,
+	}, options.FindOne().SetSort(bson.D{{Key: "createdAt", Value: -1}})).Decode(&commitment)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	total, err := database.Collection("deliverables").CountDocuments(ctx, bson.M{"applicationId": deliverable.ApplicationID})
+	if err != nil {
+		return err
+	}
+	if total < 1 {
+		total = 1
+	}
+
+	// Split evenly; the last deliverable takes the rounding remainder
+	share := commitment.Amount / total
+	if int64(deliverable.Sequence) >= total {
+		share = commitment.Amount - share*(total-1)
+	}
+
+	// Never earn more than is still committed (e.g. after a release)
+	committed, err := applicationCommitted(ctx, &Application{ID: commitment.ApplicationID, CreatorID: commitment.CreatorID}, commitment.Currency)
+	if err != nil {
+		return err
+	}
+	if share > committed {
+		share = committed
+	}
+	if share <= 0 {
+		return nil
+	}
+
+	tx := &LedgerTransaction{
+		IdempotencyKey: "milestone:" + deliverable.ID.Hex(),
+		Kind:           LedgerKindMilestone,
+		ApplicationID:  commitment.ApplicationID,
+		CampaignID:     commitment.CampaignID,
+		BrandID:        commitment.BrandID,
+		CreatorID:      commitment.CreatorID,
+		Currency:       commitment.Currency,
+		Amount:         share,
+		Description:    deliverable.Title + " published",
+		Entries:        ledgerEntries(LedgerKindMilestone, commitment.BrandID, commitment.CreatorID, share),
+	}
+	_, _, err = recordLedgerTransaction(ctx, tx)
+	return err
+}
+
+// LedgerBalance is one party's balance per bucket in a currency
+type LedgerBalance struct {
+	Currency   string `json:"currency"`
+	Committed  int64  `json:"committed"`
+	Receivable int64  `json:"receivable"`
+	Paid       int64  `json:"paid"`
+}
+
+// ledgerBalances sums a party's entries per currency and bucket. Brand
+// balances are negated so both sides report positive amounts.
+func ledgerBalances(ctx context.Context, match bson.M, ownerType, ownerID string) ([]LedgerBalance, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$unwind", Value: "$entries"}},
+		{{Key: "$match", Value: bson.M{"entries.ownerType": ownerType, "entries.ownerId": ownerID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":    bson.M{"currency": "$currency", "bucket": "$entries.bucket"},
+			"amount": bson.M{"$sum": "$entries.amount"},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.currency", Value: 1}}}},
+	}
+
+	cursor, err := database.Collection("ledger_transactions").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		ID struct {
+			Currency string `bson:"currency"`
+			Bucket   string `bson:"bucket"`
+		} `bson:"_id"`
+		Amount int64 `bson:"amount"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	sign := int64(1)
+	if ownerType == "brand" {
+		sign = -1
+	}
+
+	balances := []LedgerBalance{}
+	index := make(map[string]int)
+	for _, row := range rows {
+		i, ok := index[row.ID.Currency]
+		if !ok {
+			i = len(balances)
+			index[row.ID.Currency] = i
+			balances = append(balances, LedgerBalance{Currency: row.ID.Currency})
+		}
+		switch row.ID.Bucket {
+		case BucketCommitted:
+			balances[i].Committed = sign * row.Amount
+		case BucketReceivable:
+			balances[i].Receivable = sign * row.Amount
+		case BucketPaid:
+			balances[i].Paid = sign * row.Amount
+		}
+	}
+	return balances, nil
+}
+
+// applicationBalance returns the creator's balance on an application in currency
+func applicationBalance(ctx context.Context, application *Application, currency string) (LedgerBalance, error) {
+	balances, err := ledgerBalances(ctx, bson.M{"applicationId": application.ID}, "creator", application.CreatorID)
+	if err != nil {
+		return LedgerBalance{}, err
+	}
+	for _, balance := range balances {
+		if balance.Currency == currency {
+			return balance, nil
+		}
+	}
+	return LedgerBalance{Currency: currency}, nil
+}
+
+// applicationReceivable returns how much is earned but unpaid on an application in currency
+func applicationReceivable(ctx context.Context, application *Application, currency string) (int64, error) {
+	balance, err := applicationBalance(ctx, application, currency)
+	return balance.Receivable, err
+}
+
+// applicationCommitted returns how much is agreed but not yet earned on an application in currency
+func applicationCommitted(ctx context.Context, application *Application, currency string) (int64, error) {
+	balance, err := applicationBalance(ctx, application, currency)
+	return balance.Committed, err
+}
+
+// Payment providers
+
+// PayoutRequest asks a payment provider to send money to a creator
+type PayoutRequest struct {
+	IdempotencyKey string
+	CreatorID      string
+	Currency       string
+	Amount         int64
+	Reference      string
+}
+
+// PayoutResult is a provider's answer to a payout request
+type PayoutResult struct {
+	ProviderRef string
+}
+
+// PaymentProvider sends payouts. Implementations must treat a repeated
+// IdempotencyKey as the same payout and return the original result.
+type PaymentProvider interface {
+	Name() string
+	CreatePayout(ctx context.Context, req PayoutRequest) (PayoutResult, error)
+}
+
+// FakePaymentProvider records payouts in memory; used for development and tests
+type FakePaymentProvider struct {
+	mu      sync.Mutex
+	payouts map[string]PayoutResult
+	FailFor map[string]error // Optional errors to return for specific idempotency keys
+}
+
+// NewFakePaymentProvider creates an empty fake provider
+func NewFakePaymentProvider() *FakePaymentProvider {
+	return &FakePaymentProvider{payouts: make(map[string]PayoutResult), FailFor: make(map[string]error)}
+}
+
+// Name implements PaymentProvider
+func (p *FakePaymentProvider) Name() string {
+	return "fake"
+}
+
+// CreatePayout implements PaymentProvider
+func (p *FakePaymentProvider) CreatePayout(ctx context.Context, req PayoutRequest) (PayoutResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err, ok := p.FailFor[req.IdempotencyKey]; ok {
+		return PayoutResult{}, err
+	}
+	if result, ok := p.payouts[req.IdempotencyKey]; ok {
+		return result, nil
+	}
+
+	result := PayoutResult{ProviderRef: "fake_po_" + primitive.NewObjectID().Hex()}
+	p.payouts[req.IdempotencyKey] = result
+	return result, nil
+}
+
+// paymentProvider is the process-wide payment provider, set up by initPayments
+var paymentProvider PaymentProvider
+
+// paymentProviders are the implementations payments.provider can name
+var paymentProviders = map[string]func() PaymentProvider{
+	"fake": func() PaymentProvider { return NewFakePaymentProvider() },
+}
+
+// paymentProviderNames lists the registered providers for error messages
+func paymentProviderNames() []string {
+	names := make([]string, 0, len(paymentProviders))
+	for name := range paymentProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// initPayments selects the payment provider (payments.provider) and creates ledger indexes
+func initPayments() error {
+	newProvider, ok := paymentProviders[appConfig.Payments.Provider]
+	if !ok {
+		return fmt.Errorf("unknown payment provider %q", appConfig.Payments.Provider) // Checked by Config.Validate
+	}
+	paymentProvider = newProvider()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := database.Collection("ledger_transactions").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "idempotencyKey", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "applicationId", Value: 1}}},
+		{Keys: bson.D{{Key: "entries.ownerId", Value: 1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create ledger indexes: %w", err)
+	}
+
+	_, err = database.Collection("payouts").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "idempotencyKey", Value: 1}}, Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create payout indexes: %w", err)
+	}
+
+	_, err = database.Collection("invoices").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "sourceTransactionId", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "brandId", Value: 1}, {Key: "sequence", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "creatorId", Value: 1}, {Key: "issuedAt", Value: -1}}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create invoice indexes: %w", err)
+	}
+
+	log.Println("Payments: using", paymentProvider.Name(), "provider")
+	return nil
+}
+
+// Handlers
+
+// getApplicationLedgerHandler returns an application's ledger transactions, payouts and balance
+func getApplicationLedgerHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	applicationId := vars["applicationId"]
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	// Convert application ID to ObjectID
+	appObjID, err := primitive.ObjectIDFromHex(applicationId)
+	if err != nil {
+		http.Error(w, "Invalid application ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	application, _, status, msg := loadApplicationForParticipant(ctx, appObjID, getUserIDFromClerkUser(user))
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	cursor, err := database.Collection("ledger_transactions").Find(ctx, bson.M{"applicationId": appObjID}, opts)
+	if err != nil {
+		http.Error(w, "Error fetching ledger", http.StatusInternalServerError)
+		return
+	}
+	transactions := []LedgerTransaction{}
+	if err = cursor.All(ctx, &transactions); err != nil {
+		http.Error(w, "Error decoding ledger", http.StatusInternalServerError)
+		return
+	}
+
+	cursor, err = database.Collection("payouts").Find(ctx, bson.M{"applicationId": appObjID}, opts)
+	if err != nil {
+		http.Error(w, "Error fetching payouts", http.StatusInternalServerError)
+		return
+	}
+	payouts := []Payout{}
+	if err = cursor.All(ctx, &payouts); err != nil {
+		http.Error(w, "Error decoding payouts", http.StatusInternalServerError)
+		return
+	}
+
+	balances, err := ledgerBalances(ctx, bson.M{"applicationId": appObjID}, "creator", application.CreatorID)
+	if err != nil {
+		http.Error(w, "Error calculating balances", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"transactions": transactions,
+		"payouts":      payouts,
+		"balances":     balances,
+	})
+}
+
+// getBalancesHandler returns the signed-in brand's or creator's balances by currency
+func getBalancesHandler(w http.ResponseWriter, r *http.Request) {
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	userID := getUserIDFromClerkUser(user)
+	ownerType, match := "creator", bson.M{"creatorId": userID}
+	if getUserTypeFromClerkUser(user) == "brand" {
+		ownerType, match = "brand", bson.M{"brandId": userID}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	balances, err := ledgerBalances(ctx, match, ownerType, userID)
+	if err != nil {
+		http.Error(w, "Error calculating balances", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balances)
+}
+
+// createBonusHandler lets the brand award a performance bonus or commission on an approved application
+func createBonusHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	applicationId := vars["applicationId"]
+
+	var req struct {
+		Amount         string `json:"amount"`
+		Reason         string `json:"reason"`
+		IdempotencyKey string `json:"idempotencyKey"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		req.IdempotencyKey = key
+	}
+	if req.IdempotencyKey == "" {
+		http.Error(w, "Idempotency-Key header is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	// Convert application ID to ObjectID
+	appObjID, err := primitive.ObjectIDFromHex(applicationId)
+	if err != nil {
+		http.Error(w, "Invalid application ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	userID := getUserIDFromClerkUser(user)
+	application, campaign, status, msg := loadApplicationForParticipant(ctx, appObjID, userID)
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	if campaign.BrandID != userID {
+		http.Error(w, "Only the brand can award bonuses", http.StatusForbidden)
+		return
+	}
+	amount, err := parseMoney(req.Amount, campaignCurrency(campaign))
+	if err != nil || amount <= 0 {
+		http.Error(w, "Amount must be a positive number", http.StatusBadRequest)
+		return
+	}
+	if application.Status != "approved" {
+		http.Error(w, "Bonuses can only be awarded on approved applications", http.StatusConflict)
+		return
+	}
+	if !campaign.PerformanceBonus && strings.TrimSpace(campaign.CommissionPercentage) == "" {
+		http.Error(w, "This campaign offers no performance bonus or commission", http.StatusConflict)
+		return
+	}
+
+	description := req.Reason
+	if description == "" {
+		description = "Performance bonus for " + campaign.Title
+	}
+
+	tx := newApplicationTransaction(LedgerKindBonus, "bonus:"+application.ID.Hex()+":"+req.IdempotencyKey,
+		campaign, application, amount, description)
+	recorded, created, err := recordLedgerTransaction(ctx, tx)
+	if err != nil {
+		http.Error(w, "Error recording bonus", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(recorded)
+}
+
+// createPayoutHandler pays a creator through the payment provider. The
+// Idempotency-Key header is required; repeating a request with the same key
+// returns the original payout instead of paying twice.
+func createPayoutHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	applicationId := vars["applicationId"]
+
+	var req struct {
+		Amount string `json:"amount"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	idempotencyKey := r.Header.Get("Idempotency-Key")
+	if idempotencyKey == "" {
+		http.Error(w, "Idempotency-Key header is required", http.StatusBadRequest)
+		return
+	}
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	// Convert application ID to ObjectID
+	appObjID, err := primitive.ObjectIDFromHex(applicationId)
+	if err != nil {
+		http.Error(w, "Invalid application ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	userID := getUserIDFromClerkUser(user)
+	application, campaign, status, msg := loadApplicationForParticipant(ctx, appObjID, userID)
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+
+	if campaign.BrandID != userID {
+		http.Error(w, "Only the brand can pay out", http.StatusForbidden)
+		return
+	}
+	amount, err := parseMoney(req.Amount, campaignCurrency(campaign))
+	if err != nil || amount <= 0 {
+		http.Error(w, "Amount must be a positive number", http.StatusBadRequest)
+		return
+	}
+
+	key := "payout:" + application.ID.Hex() + ":" + idempotencyKey
+	payout, status, msg := reservePayout(ctx, campaign, application, key, amount)
+	if status != http.StatusOK {
+		http.Error(w, msg, status)
+		return
+	}
+	if payout.Status == PayoutStatusPaid {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(payout)
+		return
+	}
+
+	payouts := database.Collection("payouts")
+	result, err := paymentProvider.CreatePayout(ctx, PayoutRequest{
+		IdempotencyKey: key,
+		CreatorID:      payout.CreatorID,
+		Currency:       payout.Currency,
+		Amount:         payout.Amount,
+		Reference:      "Payout for " + campaign.Title,
+	})
+	if err != nil {
+		_, updateErr := payouts.UpdateOne(ctx, bson.M{"_id": payout.ID}, bson.M{"$set": bson.M{
+			"status": PayoutStatusFailed, "error": err.Error(), "updatedAt": time.Now(),
+		}})
+		if updateErr != nil {
+			log.Printf("Error marking payout %s failed: %v", payout.ID.Hex(), updateErr)
+		}
+		http.Error(w, "Payment provider error: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	tx := newApplicationTransaction(LedgerKindPayout, key, campaign, application, payout.Amount,
+		fmt.Sprintf("Payout %s via %s", result.ProviderRef, payout.Provider))
+	if _, _, err := recordLedgerTransaction(ctx, tx); err != nil {
+		http.Error(w, "Error recording payout", http.StatusInternalServerError)
+		return
+	}
+
+	payout.Status = PayoutStatusPaid
+	payout.ProviderRef = result.ProviderRef
+	payout.Error = ""
+	payout.UpdatedAt = time.Now()
+	_, err = payouts.UpdateOne(ctx, bson.M{"_id": payout.ID}, bson.M{
+		"$set":   bson.M{"status": payout.Status, "providerRef": payout.ProviderRef, "updatedAt": payout.UpdatedAt},
+		"$unset": bson.M{"error": ""},
+	})
+	if err != nil {
+		http.Error(w, "Error updating payout", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(payout)
+}
+
+// reservePayout returns the payout for key, creating it as pending if it does
+// not exist. New payouts and retries of failed ones are checked against the
+// unpaid balance. The check and the write run in one transaction that also
+// bumps the application's payoutVersion, so concurrent payouts on the same
+// application conflict and are retried instead of both seeing the same
+// balance. Payouts that are already pending or paid are returned as they are.
+func reservePayout(ctx context.Context, campaign *Campaign, application *Application, key string, amount int64) (*Payout, int, string) {
+	currency := campaignCurrency(campaign)
+	payouts := database.Collection("payouts")
+
+	var payout Payout
+	var status int
+	var msg string
+	err := withTransaction(ctx, func(sc mongo.SessionContext) error {
+		payout, status, msg = Payout{}, http.StatusOK, ""
+
+		_, err := database.Collection("applications").UpdateOne(sc, bson.M{"_id": application.ID}, bson.M{"$inc": bson.M{"payoutVersion": 1}})
+		if err != nil {
+			return err
+		}
+
+		err = payouts.FindOne(sc, bson.M{"idempotencyKey": key}).Decode(&payout)
+		switch {
+		case err == nil:
+			if payout.Amount != amount {
+				status, msg = http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different amount"
+				return nil
+			}
+			if payout.Status != PayoutStatusFailed {
+				// Paid, or pending and already counted against the balance
+				return nil
+			}
+		case err == mongo.ErrNoDocuments:
+		default:
+			return err
+		}
+
+		receivable, err := applicationReceivable(sc, application, currency)
+		if err != nil {
+			return err
+		}
+		inFlight, err := unpaidPayoutTotal(sc, application.ID, payout.ID)
+		if err != nil {
+			return err
+		}
+		if available := receivable - inFlight; amount > available {
+			status, msg = http.StatusConflict, fmt.Sprintf("Amount exceeds the unpaid balance of %s %s", formatMoney(available, currency), currency)
+			return nil
+		}
+
+		now := time.Now()
+		if !payout.ID.IsZero() {
+			// Retry of a failed payout
+			payout.Status = PayoutStatusPending
+			payout.UpdatedAt = now
+			_, err = payouts.UpdateOne(sc, bson.M{"_id": payout.ID}, bson.M{"$set": bson.M{"status": payout.Status, "updatedAt": now}})
+			return err
+		}
+
+		payout = Payout{
+			ID:             primitive.NewObjectID(),
+			IdempotencyKey: key,
+			ApplicationID:  application.ID,
+			BrandID:        campaign.BrandID,
+			CreatorID:      application.CreatorID,
+			Currency:       currency,
+			Amount:         amount,
+			Status:         PayoutStatusPending,
+			Provider:       paymentProvider.Name(),
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		_, err = payouts.InsertOne(sc, payout)
+		return err
+	})
+	if err != nil {
+		return nil, http.StatusInternalServerError, "Error reserving payout"
+	}
+	if status != http.StatusOK {
+		return nil, status, msg
+	}
+	return &payout, http.StatusOK, ""
+}
+
+// unpaidPayoutTotal sums payouts on an application that have not reached the
+// ledger, other than exclude. Failed payouts are included: the provider may
+// still have sent the money, so their amount stays reserved until they are
+// retried with the same key.
+func unpaidPayoutTotal(ctx context.Context, applicationID, exclude primitive.ObjectID) (int64, error) {
+	cursor, err := database.Collection("payouts").Find(ctx, bson.M{
+		"applicationId": applicationID,
+		"status":        bson.M{"$ne": PayoutStatusPaid},
+	})
+	if err != nil {
+		return 0, err
+	}
+	var unpaid []Payout
+	if err := cursor.All(ctx, &unpaid); err != nil {
+		return 0, err
+	}
+	return sumUnpaidPayouts(unpaid, exclude), nil
+}
+
+// sumUnpaidPayouts adds up the amounts of payouts that are not paid, skipping exclude
+func sumUnpaidPayouts(payouts []Payout, exclude primitive.ObjectID) int64 {
+	var total int64
+	for _, p := range payouts {
+		if p.Status != PayoutStatusPaid && p.ID != exclude {
+			total += p.Amount
+		}
+	}
+	return total
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in       string
+		currency string
+		want     int64
+		wantErr  bool
+	}{
+		{"1500", "USD", 150000, false},
+		{"$1,500.00", "USD", 150000, false},
+		{"USD 19.99 per post", "USD", 1999, false},
+		{"0.005", "USD", 1, false},
+		{"Rate: 250", "EUR", 25000, false},
+		{"¥1,500", "JPY", 1500, false},
+		{"12.5", "JPY", 13, false},
+		{"1.234", "KWD", 1234, false},
+		{"10", "", 1000, false},
+		{"", "USD", 0, true},
+		{"negotiable", "USD", 0, true},
+		{"-50", "USD", 0, true},
+		{"$-50", "USD", 0, true},
+		{"+50", "USD", 0, true},
+		{"−50", "USD", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseMoney(tt.in, tt.currency)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseMoney(%q, %q) error = %v, wantErr %v", tt.in, tt.currency, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseMoney(%q, %q) = %d, want %d", tt.in, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"$1,500", 150000, false},
+		{"USD 19.99 per post", 1999, false},
+		{"$1,000-$2,500", 0, true},
+		{"$500 - $1,000", 0, true},
+		{"Under $500", 0, true},
+		{"$25,000+", 0, true},
+		{"up to 300", 0, true},
+		{"5% commission", 500, false}, // Only read as a fee for Fixed Payment campaigns
+	}
+	for _, tt := range tests {
+		got, err := parseAmount(tt.in, "USD")
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseAmount(%q) = %d, %v; want %d, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseMoneyRange(t *testing.T) {
+	tests := []struct {
+		in        string
+		low, high int64
+		wantErr   bool
+	}{
+		{"$1,000-$2,500", 100000, 250000, false},
+		{"$500 - $1,000", 50000, 100000, false},
+		{"Under $500", 0, 50000, false},
+		{"up to 300", 0, 30000, false},
+		{"$25,000+", 2500000, 0, false},
+		{"over $10", 1000, 0, false},
+		{"$2,500-$1,000", 0, 0, true},
+		{"-$500-$1,000", 0, 0, true},
+		{"Under $0", 0, 0, true},
+		{"$1,500", 0, 0, true},
+	}
+	for _, tt := range tests {
+		low, high, err := parseMoneyRange(tt.in, "USD")
+		if (err != nil) != tt.wantErr || low != tt.low || high != tt.high {
+			t.Errorf("parseMoneyRange(%q) = %d, %d, %v; want %d, %d, error %v", tt.in, low, high, err, tt.low, tt.high, tt.wantErr)
+		}
+	}
+}
+
+func TestCampaignBudgetLimit(t *testing.T) {
+	tests := []struct {
+		budget  string
+		limit   int64
+		limited bool
+	}{
+		{"", 0, false},
+		{"$5,000", 500000, true},
+		{"$1,000-$2,500", 250000, true},
+		{"Under $500", 50000, true},
+		{"$25,000+", 0, false},
+		{"flexible", 0, false},
+	}
+	for _, tt := range tests {
+		limit, limited := campaignBudgetLimit(&Campaign{Budget: tt.budget, Currency: "USD"})
+		if limit != tt.limit || limited != tt.limited {
+			t.Errorf("campaignBudgetLimit(%q) = %d, %v; want %d, %v", tt.budget, limit, limited, tt.limit, tt.limited)
+		}
+	}
+}
+
+func TestApplicationFee(t *testing.T) {
+	fixed := func(amount string) *Campaign {
+		return &Campaign{CompensationType: compensationFixedPayment, PaymentAmount: amount, Currency: "USD"}
+	}
+	tests := []struct {
+		name        string
+		campaign    *Campaign
+		application *Application
+		want        int64
+	}{
+		{"fixed payment", fixed("$1,200"), &Application{}, 120000},
+		{"agreed rate wins", fixed("$1,200"), &Application{AgreedTerms: &ApplicationTerms{Rate: "$900"}}, 90000},
+		{"range is no fee", fixed("$1,000-$2,500"), &Application{}, 0},
+		{"open range is no fee", fixed("Under $500"), &Application{}, 0},
+		{"agreed rate over a range", fixed("$25,000+"), &Application{AgreedTerms: &ApplicationTerms{Rate: "30000"}}, 3000000},
+		{"commission", &Campaign{CompensationType: "Commission/Affiliate", PaymentAmount: "5% commission"}, &Application{}, 0},
+		{"product only", &Campaign{CompensationType: "Product Only", PaymentAmount: "$200"},
+			&Application{AgreedTerms: &ApplicationTerms{Rate: "$200"}}, 0},
+	}
+	for _, tt := range tests {
+		if got := applicationFee(tt.campaign, tt.application); got != tt.want {
+			t.Errorf("%s: applicationFee = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		currency string
+		want     string
+	}{
+		{150000, "USD", "1500.00"},
+		{5, "EUR", "0.05"},
+		{-1999, "USD", "-19.99"},
+		{1500, "JPY", "1500"},
+		{-7, "KRW", "-7"},
+		{1234, "KWD", "1.234"},
+		{5, "BHD", "0.005"},
+		{1000, "", "10.00"},
+	}
+	for _, tt := range tests {
+		if got := formatMoney(tt.amount, tt.currency); got != tt.want {
+			t.Errorf("formatMoney(%d, %q) = %q, want %q", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestLedgerEntries(t *testing.T) {
+	tests := []struct {
+		kind    string
+		creator map[string]int64
+	}{
+		{LedgerKindCommitment, map[string]int64{BucketCommitted: 1000}},
+		{LedgerKindMilestone, map[string]int64{BucketCommitted: -1000, BucketReceivable: 1000}},
+		{LedgerKindBonus, map[string]int64{BucketReceivable: 1000}},
+		{LedgerKindPayout, map[string]int64{BucketReceivable: -1000, BucketPaid: 1000}},
+		{LedgerKindRelease, map[string]int64{BucketCommitted: -1000}},
+	}
+	for _, tt := range tests {
+		entries := ledgerEntries(tt.kind, "brand_1", "creator_1", 1000)
+		var sum int64
+		creator := map[string]int64{}
+		for _, entry := range entries {
+			sum += entry.Amount
+			switch entry.OwnerType {
+			case "creator":
+				creator[entry.Bucket] += entry.Amount
+			case "brand":
+				if entry.OwnerID != "brand_1" {
+					t.Errorf("%s: brand entry for %q", tt.kind, entry.OwnerID)
+				}
+			}
+		}
+		if sum != 0 {
+			t.Errorf("%s: entries sum to %d, want 0", tt.kind, sum)
+		}
+		if len(creator) != len(tt.creator) {
+			t.Errorf("%s: creator buckets = %v, want %v", tt.kind, creator, tt.creator)
+			continue
+		}
+		for bucket, want := range tt.creator {
+			if creator[bucket] != want {
+				t.Errorf("%s: creator %s = %d, want %d", tt.kind, bucket, creator[bucket], want)
+			}
+		}
+	}
+
+	if entries := ledgerEntries("unknown", "brand_1", "creator_1", 1000); entries != nil {
+		t.Errorf("unknown kind returned %v", entries)
+	}
+}
+
+func TestSumUnpaidPayouts(t *testing.T) {
+	retry := primitive.NewObjectID()
+	payouts := []Payout{
+		{ID: primitive.NewObjectID(), Status: PayoutStatusPending, Amount: 100},
+		{ID: primitive.NewObjectID(), Status: PayoutStatusFailed, Amount: 200},
+		{ID: primitive.NewObjectID(), Status: PayoutStatusPaid, Amount: 400},
+		{ID: retry, Status: PayoutStatusFailed, Amount: 800},
+	}
+	if got := sumUnpaidPayouts(payouts, primitive.NilObjectID); got != 1100 {
+		t.Errorf("new payout: got %d, want 1100", got)
+	}
+	if got := sumUnpaidPayouts(payouts, retry); got != 300 {
+		t.Errorf("retry: got %d, want 300", got)
+	}
+}
+
+func TestFakePaymentProvider(t *testing.T) {
+	ctx := context.Background()
+	provider := NewFakePaymentProvider()
+	req := PayoutRequest{IdempotencyKey: "payout:app:1", CreatorID: "creator_1", Currency: "USD", Amount: 5000}
+
+	first, err := provider.CreatePayout(ctx, req)
+	if err != nil {
+		t.Fatalf("first payout: %v", err)
+	}
+	again, err := provider.CreatePayout(ctx, req)
+	if err != nil {
+		t.Fatalf("repeated payout: %v", err)
+	}
+	if again != first {
+		t.Errorf("repeated payout returned %v, want %v", again, first)
+	}
+
+	other, err := provider.CreatePayout(ctx, PayoutRequest{IdempotencyKey: "payout:app:2", Amount: 5000})
+	if err != nil {
+		t.Fatalf("second key: %v", err)
+	}
+	if other == first {
+		t.Errorf("different keys returned the same payout %v", other)
+	}
+}
+
+func TestFakePaymentProviderFailure(t *testing.T) {
+	ctx := context.Background()
+	provider := NewFakePaymentProvider()
+	req := PayoutRequest{IdempotencyKey: "payout:app:1", Amount: 5000}
+	declined := errors.New("account closed")
+	provider.FailFor[req.IdempotencyKey] = declined
+
+	if _, err := provider.CreatePayout(ctx, req); !errors.Is(err, declined) {
+		t.Fatalf("failing payout: got %v, want %v", err, declined)
+	}
+	if _, err := provider.CreatePayout(ctx, req); !errors.Is(err, declined) {
+		t.Fatalf("retry while failing: got %v, want %v", err, declined)
+	}
+
+	// Once the provider recovers, a retry with the same key pays exactly once
+	delete(provider.FailFor, req.IdempotencyKey)
+	first, err := provider.CreatePayout(ctx, req)
+	if err != nil {
+		t.Fatalf("retry after recovery: %v", err)
+	}
+	again, err := provider.CreatePayout(ctx, req)
+	if err != nil || again != first {
+		t.Errorf("repeat after recovery = %v, %v; want %v", again, err, first)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Page layout for generated PDFs (A4, in points)
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMargin       = 50
+	pdfFontSize     = 10
+	pdfLineHeight   = 14
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLineHeight
+)
+
+// pdfLine is one line of text; bold lines use the bold font
+type pdfLine struct {
+	Text string
+	Bold bool
+}
+
+// PDFDocument builds a simple text-only PDF in a monospaced font, which is
+// enough for invoices and statements without pulling in a PDF library
+type PDFDocument struct {
+	Title string
+	lines []pdfLine
+}
+
+// Line adds a line of text
+func (d *PDFDocument) Line(format string, args ...interface{}) {
+	d.lines = append(d.lines, pdfLine{Text: fmt.Sprintf(format, args...)})
+}
+
+// Heading adds a line of bold text
+func (d *PDFDocument) Heading(format string, args ...interface{}) {
+	d.lines = append(d.lines, pdfLine{Text: fmt.Sprintf(format, args...), Bold: true})
+}
+
+// Blank adds an empty line
+func (d *PDFDocument) Blank() {
+	d.lines = append(d.lines, pdfLine{})
+}
+
+// winAnsiSpecials maps the runes WinAnsiEncoding places in 0x80-0x9F.
+// Latin-1 runes from U+00A0 to U+00FF keep their own code.
+var winAnsiSpecials = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// pdfEscape escapes a string for a PDF literal in WinAnsiEncoding, the
+// encoding of the standard fonts. Characters outside it become '?'.
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\t':
+			b.WriteString("    ")
+		case r >= 32 && r <= 126:
+			b.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&b, "\\%03o", r)
+		case winAnsiSpecials[r] != 0:
+			fmt.Fprintf(&b, "\\%03o", winAnsiSpecials[r])
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// Bytes renders the document
+func (d *PDFDocument) Bytes() []byte {
+	var pages [][]pdfLine
+	for start := 0; start < len(d.lines) || start == 0; start += pdfLinesPerPage {
+		end := start + pdfLinesPerPage
+		if end > len(d.lines) {
+			end = len(d.lines)
+		}
+		pages = append(pages, d.lines[start:end])
+	}
+
+	// Objects: 1 catalog, 2 page tree, 3 regular font, 4 bold font, 5 info,
+	// then a page and a content stream per page
+	var objects []string
+	pageIDs := make([]string, len(pages))
+	for i := range pages {
+		pageIDs[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(pageIDs, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Title (%s) /Producer (SponsorConnect) >>", pdfEscape(d.Title)),
+	)
+
+	for i, lines := range pages {
+		var content bytes.Buffer
+		content.WriteString("BT\n")
+		fmt.Fprintf(&content, "%d TL\n%d %d Td\n", pdfLineHeight, pdfMargin, pdfPageHeight-pdfMargin)
+		for _, line := range lines {
+			font := "F1"
+			if line.Bold {
+				font = "F2"
+			}
+			fmt.Fprintf(&content, "/%s %d Tf (%s) Tj T*\n", font, pdfFontSize, pdfEscape(line.Text))
+		}
+		fmt.Fprintf(&content, "/F1 8 Tf (Page %d of %d) Tj\nET\n", i+1, len(pages))
+
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				pdfPageWidth, pdfPageHeight, 7+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestPDFEscape(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Invoice (draft)", `Invoice \(draft\)`},
+		{`C:\path`, `C:\\path`},
+		{"a\tb", "a    b"},
+		{"Café", `Caf\351`},
+		{"ÿ and ñ", `\377 and \361`},
+		{"€5 – “ok”", `\2005 \226 \223ok\224`},
+		{"Œuvre™", `\214uvre\231`},
+		{"東京", "??"},
+		{"line\nbreak", "line?break"},
+	}
+	for _, tt := range tests {
+		if got := pdfEscape(tt.in); got != tt.want {
+			t.Errorf("pdfEscape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"html/template"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// publicBannerExpiry keeps banner links on public pages valid long enough for link previews
+const publicBannerExpiry = 24 * time.Hour
+
+// publicCampaignStatuses are the states in which a public campaign can be viewed
+var publicCampaignStatuses = []string{CampaignStatusScheduled, CampaignStatusActive, CampaignStatusPaused, CampaignStatusCompleted}
+
+// PublicCampaign is the read-only subset of a campaign shown without login
+type PublicCampaign struct {
+	ID               string    `json:"id"`
+	Slug             string    `json:"slug"`
+	Title            string    `json:"title"`
+	Description      string    `json:"description"`
+	Category         string    `json:"category"`
+	BrandName        string    `json:"brandName"`
+	Status           string    `json:"status"`
+	StartDate        string    `json:"startDate"`
+	EndDate          string    `json:"endDate"`
+	CampaignType     string    `json:"campaignType"`
+	Platforms        []string  `json:"platforms"`
+	ContentFormat    []string  `json:"contentFormat"`
+	NumberOfPosts    string    `json:"numberOfPosts"`
+	CreatorTier      string    `json:"creatorTier"`
+	MinimumFollowers string    `json:"minimumFollowers"`
+	Languages        []string  `json:"languages"`
+	TargetLocation   string    `json:"targetLocation"`
+	CompensationType string    `json:"compensationType"`
+	BannerImageURL   string    `json:"bannerImageUrl,omitempty"`
+	Budget           string    `json:"budget,omitempty"` // Only when the brand allows it
+	PaymentAmount    string    `json:"paymentAmount,omitempty"`
+	Currency         string    `json:"currency,omitempty"`
+	Views            int64     `json:"views"`
+	URL              string    `json:"url"`
+	OpenGraph        OpenGraph `json:"openGraph"`
+}
+
+// OpenGraph holds the link preview metadata for a public page
+type OpenGraph struct {
+	Title       string `json:"og:title"`
+	Description string `json:"og:description"`
+	Image       string `json:"og:image,omitempty"`
+	URL         string `json:"og:url"`
+	Type        string `json:"og:type"`
+	SiteName    string `json:"og:site_name"`
+}
+
+var slugInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// campaignSlug builds a readable slug from the title. The end of the
+// campaign ID keeps slugs unique when titles repeat.
+func campaignSlug(campaign *Campaign) string {
+	base := strings.Trim(slugInvalidChars.ReplaceAllString(strings.ToLower(campaign.Title), "-"), "-")
+	if len(base) > 60 {
+		base = strings.TrimRight(base[:60], "-")
+	}
+	if base == "" {
+		base = "campaign"
+	}
+	return base + "-" + campaign.ID.Hex()[18:]
+}
+
+// publicAPIURL is the base URL crawlers use to reach this API
+func publicAPIURL() string {
+	return strings.TrimRight(appConfig.App.PublicAPIURL, "/")
+}
+
+// isCrawler reports whether the request comes from a bot or link previewer,
+// which are not counted as views
+func isCrawler(r *http.Request) bool {
+	agent := strings.ToLower(r.UserAgent())
+	if agent == "" {
+		return true
+	}
+	for _, marker := range []string{"bot", "crawl", "spider", "slurp", "facebookexternalhit", "preview", "whatsapp", "telegram", "embedly"} {
+		if strings.Contains(agent, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// publicBannerURL returns a link to the banner that works without login
+func publicBannerURL(ctx context.Context, campaign *Campaign) string {
+	if campaign.BannerAssetID != nil {
+		var asset MediaAsset
+		if err := database.Collection("media_assets").FindOne(ctx, bson.M{"_id": campaign.BannerAssetID}).Decode(&asset); err == nil {
+			if url, err := blobStore.SignedURL(asset.Key, publicBannerExpiry); err == nil {
+				return url
+			}
+		}
+		return ""
+	}
+	// Only externally hosted banners; internal links need a login
+	if strings.HasPrefix(campaign.BannerImageURL, "http") {
+		return campaign.BannerImageURL
+	}
+	return ""
+}
+
+// newPublicCampaign strips a campaign down to what may be shown publicly
+func newPublicCampaign(ctx context.Context, campaign *Campaign) PublicCampaign {
+	page := PublicCampaign{
+		ID:               campaign.ID.Hex(),
+		Slug:             campaign.PublicSlug,
+		Title:            campaign.Title,
+		Description:      campaign.Description,
+		Category:         campaign.Category,
+		BrandName:        campaign.BrandName,
+		Status:           campaign.Status,
+		StartDate:        campaign.StartDate,
+		EndDate:          campaign.EndDate,
+		CampaignType:     campaign.CampaignType,
+		Platforms:        campaign.Platforms,
+		ContentFormat:    campaign.ContentFormat,
+		NumberOfPosts:    campaign.NumberOfPosts,
+		CreatorTier:      campaign.CreatorTier,
+		MinimumFollowers: campaign.MinimumFollowers,
+		Languages:        campaign.MinRequirements.Languages,
+		TargetLocation:   campaign.TargetAudience.Location,
+		CompensationType: campaign.CompensationType,
+		BannerImageURL:   publicBannerURL(ctx, campaign),
+		Views:            campaign.PublicViews,
+		URL:              notificationLink("/campaigns/" + campaign.PublicSlug),
+	}
+	if campaign.PublicShowBudget {
+		page.Budget = campaign.Budget
+		page.PaymentAmount = campaign.PaymentAmount
+		page.Currency = campaignCurrency(campaign)
+	}
+
+	description := campaign.Description
+	if description == "" {
+		description = campaign.BrandName + " is looking for creators"
+	}
+	page.OpenGraph = OpenGraph{
+		Title:       campaign.Title,
+		Description: truncate(strings.Join(strings.Fields(description), " "), 200),
+		Image:       page.BannerImageURL,
+		URL:         page.URL,
+		Type:        "website",
+		SiteName:    GetDisplayName(),
+	}
+	return page
+}
+
+// loadPublicCampaign finds a shared campaign by slug; unshared and draft campaigns are not found
+func loadPublicCampaign(ctx context.Context, slug string) (*Campaign, int, string) {
+	var campaign Campaign
+	err := database.Collection("campaigns").FindOne(ctx, bson.M{
+		"publicSlug": slug,
+		"isPublic":   true,
+		"status":     bson.M{"$in": publicCampaignStatuses},
+	}).Decode(&campaign)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, http.StatusNotFound, "Campaign not found"
+		}
+		return nil, http.StatusInternalServerError, "Error fetching campaign"
+	}
+	return &campaign, http.StatusOK, ""
+}
+
+// getPublicCampaignHandler serves the public view of a shared campaign and counts the view
+func getPublicCampaignHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	campaign, status, msg := loadPublicCampaign(ctx, vars["slug"])
+	if campaign == nil {
+		http.Error(w, msg, status)
+		return
+	}
+
+	if !isCrawler(r) {
+		_, err := database.Collection("campaigns").UpdateOne(ctx, bson.M{"_id": campaign.ID}, bson.M{"$inc": bson.M{"publicViews": 1}})
+		if err != nil {
+			log.Printf("Error counting view of campaign %s: %v", campaign.ID.Hex(), err)
+		} else {
+			campaign.PublicViews++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache") // Revalidate so repeat visits are counted
+	json.NewEncoder(w).Encode(newPublicCampaign(ctx, campaign))
+}
+
+// openGraphPage is served to link previewers, which do not run the frontend's JavaScript
+var openGraphPage = template.Must(template.New("og").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<meta name="description" content="{{.OpenGraph.Description}}">
+<meta property="og:title" content="{{.OpenGraph.Title}}">
+<meta property="og:description" content="{{.OpenGraph.Description}}">
+<meta property="og:type" content="{{.OpenGraph.Type}}">
+<meta property="og:url" content="{{.OpenGraph.URL}}">
+<meta property="og:site_name" content="{{.OpenGraph.SiteName}}">
+{{if .OpenGraph.Image}}<meta property="og:image" content="{{.OpenGraph.Image}}">
+<meta name="twitter:card" content="summary_large_image">
+{{else}}<meta name="twitter:card" content="summary">
+{{end}}<meta http-equiv="refresh" content="0; url={{.URL}}">
+</head>
+<body><a href="{{.URL}}">{{.Title}}</a></body>
+</html>
+`))
+
+// getPublicCampaignShareHandler serves the share link: Open Graph tags for
+// previews, then a redirect to the campaign's page in the frontend
+func getPublicCampaignShareHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	campaign, status, msg := loadPublicCampaign(ctx, vars["slug"])
+	if campaign == nil {
+		http.Error(w, msg, status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := openGraphPage.Execute(w, newPublicCampaign(ctx, campaign)); err != nil {
+		log.Printf("Error rendering share page for campaign %s: %v", campaign.ID.Hex(), err)
+	}
+}
+
+// updateCampaignSharingHandler turns the public page on or off for one of the brand's campaigns
+func updateCampaignSharingHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req struct {
+		Enabled    bool `json:"enabled"`
+		ShowBudget bool `json:"showBudget"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Get user from context
+	user, ok := getUserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "User not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	campaign, status, msg := loadOwnedCampaign(ctx, vars["campaignId"], getUserIDFromClerkUser(user))
+	if campaign == nil {
+		http.Error(w, msg, status)
+		return
+	}
+
+	set := bson.M{
+		"isPublic":         req.Enabled,
+		"publicShowBudget": req.ShowBudget,
+		"updatedAt":        time.Now(),
+	}
+	// The slug is assigned on first share so links keep working if the title changes
+	if campaign.PublicSlug == "" {
+		campaign.PublicSlug = campaignSlug(campaign)
+		taken, err := database.Collection("campaigns").CountDocuments(ctx, bson.M{"publicSlug": campaign.PublicSlug})
+		if err != nil {
+			http.Error(w, "Error checking slug", http.StatusInternalServerError)
+			return
+		}
+		if taken > 0 {
+			campaign.PublicSlug = strings.TrimSuffix(campaign.PublicSlug, campaign.ID.Hex()[18:]) + campaign.ID.Hex()
+		}
+		set["publicSlug"] = campaign.PublicSlug
+	}
+
+	_, err := database.Collection("campaigns").UpdateOne(ctx, bson.M{"_id": campaign.ID}, bson.M{"$set": set})
+	if err != nil {
+		http.Error(w, "Error updating campaign", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"isPublic":   req.Enabled,
+		"showBudget": req.ShowBudget,
+		"slug":       campaign.PublicSlug,
+		"url":        notificationLink("/campaigns/" + campaign.PublicSlug),
+		"shareUrl":   publicAPIURL() + "/api/public/campaigns/" + campaign.PublicSlug + "/share",
+		"views":      campaign.PublicViews,
+		"visible":    req.Enabled && campaign.Status != CampaignStatusDraft && campaign.Status != CampaignStatusCancelled,
+	})
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// RateLimit is a token bucket: Requests tokens refill evenly over Per, and
+// up to Burst tokens can be saved up (Burst defaults to Requests)
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+func (l RateLimit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+// policy describes the limit for the X-RateLimit-Policy header: "30;w=60;burst=10"
+func (l RateLimit) policy() string {
+	return fmt.Sprintf("%d;w=%d;burst=%d", l.Requests, int(l.Per.Seconds()), int(l.capacity()))
+}
+
+func (l RateLimit) String() string {
+	unit := map[time.Duration]string{time.Second: "s", time.Minute: "m", time.Hour: "h", 24 * time.Hour: "d"}[l.Per]
+	if unit == "" {
+		unit = l.Per.String()
+	}
+	if l.Burst > 0 {
+		return fmt.Sprintf("%d/%s:%d", l.Requests, unit, l.Burst)
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, unit)
+}
+
+// parseRateLimit reads "10/m", "100/h" or "5/s:20" (20 is the burst)
+func parseRateLimit(s string) (RateLimit, error) {
+	var limit RateLimit
+	spec, burst, hasBurst := strings.Cut(strings.TrimSpace(s), ":")
+	count, unit, ok := strings.Cut(spec, "/")
+	if !ok {
+		return limit, fmt.Errorf("rate limit %q must look like 10/m", s)
+	}
+
+	var err error
+	if limit.Requests, err = strconv.Atoi(count); err != nil || limit.Requests <= 0 {
+		return limit, fmt.Errorf("rate limit %q must start with a positive count", s)
+	}
+	switch unit {
+	case "s":
+		limit.Per = time.Second
+	case "m":
+		limit.Per = time.Minute
+	case "h":
+		limit.Per = time.Hour
+	case "d":
+		limit.Per = 24 * time.Hour
+	default:
+		return limit, fmt.Errorf("rate limit %q must use s, m, h or d", s)
+	}
+	if hasBurst {
+		if limit.Burst, err = strconv.Atoi(burst); err != nil || limit.Burst <= 0 {
+			return limit, fmt.Errorf("rate limit %q must have a positive burst", s)
+		}
+	}
+	return limit, nil
+}
+
+// RateLimitDecision is the outcome of taking a token
+type RateLimitDecision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	Reset      time.Duration // Until the bucket is full again
+}
+
+// RateLimitStore keeps the buckets. The in-memory store suits a single
+// instance; several instances behind a load balancer need a shared store.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit RateLimit, now time.Time) (RateLimitDecision, error)
+}
+
+type tokenBucket struct {
+	key    string
+	tokens float64
+	last   time.Time
+	full   time.Time // When the bucket will have refilled completely
+}
+
+// defaultRateLimitBuckets bounds the in-memory store, about 10 MB of buckets
+const defaultRateLimitBuckets = 100_000
+
+// MemoryRateLimitStore keeps buckets in a map guarded by a mutex. At most
+// MaxBuckets are kept; past that the least recently used bucket is dropped,
+// which only ever forgives a client that has gone quiet.
+type MemoryRateLimitStore struct {
+	MaxBuckets int
+
+	mu        sync.Mutex
+	buckets   map[string]*list.Element
+	recent    *list.List // Most recently used first
+	lastSweep time.Time
+}
+
+func NewMemoryRateLimitStore(maxBuckets int) *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		MaxBuckets: maxBuckets,
+		buckets:    make(map[string]*list.Element),
+		recent:     list.New(),
+	}
+}
+
+// Len reports how many buckets are held
+func (s *MemoryRateLimitStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.buckets)
+}
+
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, limit RateLimit, now time.Time) (RateLimitDecision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	capacity := limit.capacity()
+	rate := float64(limit.Requests) / limit.Per.Seconds() // Tokens per second
+
+	var bucket *tokenBucket
+	if element, ok := s.buckets[key]; ok {
+		bucket = element.Value.(*tokenBucket)
+		s.recent.MoveToFront(element)
+	} else {
+		bucket = &tokenBucket{key: key, tokens: capacity, last: now}
+		s.buckets[key] = s.recent.PushFront(bucket)
+		for s.MaxBuckets > 0 && len(s.buckets) > s.MaxBuckets {
+			oldest := s.recent.Back()
+			s.recent.Remove(oldest)
+			delete(s.buckets, oldest.Value.(*tokenBucket).key)
+		}
+	}
+	bucket.tokens = math.Min(capacity, bucket.tokens+now.Sub(bucket.last).Seconds()*rate)
+	bucket.last = now
+
+	decision := RateLimitDecision{Limit: limit.Requests}
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = time.Duration((1 - bucket.tokens) / rate * float64(time.Second))
+	}
+	decision.Remaining = int(bucket.tokens)
+	decision.Reset = time.Duration((capacity - bucket.tokens) / rate * float64(time.Second))
+	bucket.full = now.Add(decision.Reset)
+
+	s.sweep(now)
+	return decision, nil
+}
+
+// sweep drops buckets that have refilled completely, at most once a minute.
+// A full bucket is the same as no bucket, so this never changes a decision.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+	for key, element := range s.buckets {
+		if !now.Before(element.Value.(*tokenBucket).full) {
+			s.recent.Remove(element)
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// RateLimitPolicy holds a group's limits by user type ("brand", "influencer",
+// "anonymous"); "default" applies to signed-in users without their own entry
+type RateLimitPolicy map[string]RateLimit
+
+// rateLimitGroups are the built-in limits, overridable with rateLimits.overrides (RATE_LIMITS)
+var rateLimitGroups = map[string]RateLimitPolicy{
+	// Every API request, per client IP, as a coarse flood guard
+	"global": {"anonymous": {Requests: 600, Per: time.Minute}},
+	// Endpoints served without login
+	"public": {"anonymous": {Requests: 60, Per: time.Minute, Burst: 20}},
+	// Creating campaigns one by one, by import, duplication or template
+	"campaign-create": {"default": {Requests: 30, Per: time.Hour, Burst: 10}},
+	// Applying to campaigns
+	"apply": {"default": {Requests: 20, Per: time.Hour, Burst: 5}},
+	// Media uploads
+	"upload": {"default": {Requests: 60, Per: time.Hour, Burst: 10}},
+	// Messages, offers and other writes between brands and creators
+	"messages": {"default": {Requests: 30, Per: time.Minute, Burst: 10}},
+}
+
+var (
+	rateLimitStore   RateLimitStore
+	rateLimitEnabled = true
+)
+
+// parseRateLimitOverride reads one override such as "apply.influencer=10/h"
+func parseRateLimitOverride(entry string) (string, string, RateLimit, error) {
+	target, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+	group, userType, hasType := strings.Cut(target, ".")
+	if !ok || !hasType || group == "" || userType == "" {
+		return "", "", RateLimit{}, fmt.Errorf("rate limit override %q must look like group.userType=10/m", entry)
+	}
+	if rateLimitGroups[group] == nil {
+		return "", "", RateLimit{}, fmt.Errorf("rate limit override %q names unknown group %q (known: %s)", entry, group, strings.Join(rateLimitGroupNames(), ", "))
+	}
+	limit, err := parseRateLimit(value)
+	return group, userType, limit, err
+}
+
+// rateLimitGroupNames lists the built-in groups in order, for error messages
+func rateLimitGroupNames() []string {
+	names := make([]string, 0, len(rateLimitGroups))
+	for name := range rateLimitGroups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// initRateLimits applies the configured overrides, such as
+// "apply.influencer=10/h" or "campaign-create.brand=50/h:10", and sets up the store
+func initRateLimits() error {
+	if appConfig.RateLimits.Disabled {
+		rateLimitEnabled = false
+		log.Println("Rate limiting: disabled")
+		return nil
+	}
+
+	for _, entry := range appConfig.RateLimits.Overrides {
+		group, userType, limit, err := parseRateLimitOverride(entry)
+		if err != nil {
+			return err
+		}
+		rateLimitGroups[group][userType] = limit
+	}
+
+	rateLimitStore = NewMemoryRateLimitStore(defaultRateLimitBuckets)
+	return nil
+}
+
+// rateLimit limits a handler by the group's policy. Signed-in users are keyed
+// on their Clerk ID, so wrap the handler inside authMiddleware; anyone else is
+// keyed on client IP. Requests the policy does not cover pass straight through.
+func rateLimit(group string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !rateLimitEnabled || rateLimitStore == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key, userType := "ip:"+clientIP(r), "anonymous"
+		if user, ok := getUserFromContext(r.Context()); ok {
+			key, userType = "user:"+getUserIDFromClerkUser(user), getUserTypeFromClerkUser(user)
+		}
+
+		policy := rateLimitGroups[group]
+		limit, ok := policy[userType]
+		if !ok && userType != "anonymous" {
+			limit, ok = policy["default"]
+		}
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		decision, err := rateLimitStore.Take(r.Context(), group+":"+key, limit, time.Now())
+		if err != nil {
+			// Fail open: a broken store should not take the API down
+			log.Printf("rateLimit: Error checking limit: %v", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(decision.Limit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.Itoa(int(math.Ceil(decision.Reset.Seconds()))))
+		w.Header().Set("X-RateLimit-Policy", limit.policy())
+		if !decision.Allowed {
+			retryAfter := int(math.Ceil(decision.RetryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+			http.Error(w, "Too many requests, please slow down", http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+// rateLimitMiddleware applies a group's limits to every route of a router
+func rateLimitMiddleware(group string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return rateLimit(group, next.ServeHTTP)
+	}
+}
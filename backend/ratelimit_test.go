@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    RateLimit
+		wantErr bool
+	}{
+		{"10/m", RateLimit{Requests: 10, Per: time.Minute}, false},
+		{"100/h", RateLimit{Requests: 100, Per: time.Hour}, false},
+		{" 5/s:20 ", RateLimit{Requests: 5, Per: time.Second, Burst: 20}, false},
+		{"1/d", RateLimit{Requests: 1, Per: 24 * time.Hour}, false},
+		{"10", RateLimit{}, true},
+		{"0/m", RateLimit{}, true},
+		{"10/w", RateLimit{}, true},
+		{"10/m:0", RateLimit{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseRateLimit(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseRateLimit(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("parseRateLimit(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseRateLimitOverride(t *testing.T) {
+	group, userType, limit, err := parseRateLimitOverride(" apply.influencer=10/h ")
+	if err != nil || group != "apply" || userType != "influencer" || limit != (RateLimit{Requests: 10, Per: time.Hour}) {
+		t.Errorf("parseRateLimitOverride = %q, %q, %+v, %v", group, userType, limit, err)
+	}
+
+	for _, entry := range []string{"apply=10/h", ".brand=10/h", "apply.=10/h", "apply.brand", "apply.brand=ten", "aply.brand=10/h", "uploads.default=5/m"} {
+		if _, _, _, err := parseRateLimitOverride(entry); err == nil {
+			t.Errorf("parseRateLimitOverride(%q) was accepted", entry)
+		}
+	}
+}
+
+func TestTokenBucketRefill(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	// 60/m refills one token a second; the burst of 3 caps what can be saved
+	limit := RateLimit{Requests: 60, Per: time.Minute, Burst: 3}
+
+	tests := []struct {
+		at         time.Duration // Since start
+		allowed    bool
+		remaining  int
+		retryAfter time.Duration
+		reset      time.Duration
+	}{
+		{0, true, 2, 0, time.Second},
+		{0, true, 1, 0, 2 * time.Second},
+		{0, true, 0, 0, 3 * time.Second},
+		{0, false, 0, time.Second, 3 * time.Second},
+		{500 * time.Millisecond, false, 0, 500 * time.Millisecond, 2500 * time.Millisecond},
+		{time.Second, true, 0, 0, 3 * time.Second},
+		{3 * time.Second, true, 1, 0, 2 * time.Second},
+		// An hour of quiet refills only up to the burst
+		{time.Hour, true, 2, 0, time.Second},
+	}
+
+	store := NewMemoryRateLimitStore(0)
+	for i, tt := range tests {
+		got, err := store.Take(context.Background(), "ip:192.0.2.1", limit, start.Add(tt.at))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := RateLimitDecision{Allowed: tt.allowed, Limit: 60, Remaining: tt.remaining, RetryAfter: tt.retryAfter, Reset: tt.reset}
+		if got != want {
+			t.Errorf("take %d at %v = %+v, want %+v", i, tt.at, got, want)
+		}
+	}
+}
+
+func TestTokenBucketDefaultBurst(t *testing.T) {
+	store := NewMemoryRateLimitStore(0)
+	now := time.Now()
+	limit := RateLimit{Requests: 5, Per: time.Hour}
+	for i := 0; i < 5; i++ {
+		if d, _ := store.Take(context.Background(), "user:a", limit, now); !d.Allowed {
+			t.Fatalf("request %d was limited", i+1)
+		}
+	}
+	d, _ := store.Take(context.Background(), "user:a", limit, now)
+	if d.Allowed || d.RetryAfter != 12*time.Minute {
+		t.Errorf("sixth request = %+v, want limited for 12m", d)
+	}
+	// Other keys have their own bucket
+	if d, _ := store.Take(context.Background(), "user:b", limit, now); !d.Allowed {
+		t.Error("a different key was limited")
+	}
+}
+
+func TestMemoryRateLimitStoreBounded(t *testing.T) {
+	store := NewMemoryRateLimitStore(3)
+	now := time.Now()
+	limit := RateLimit{Requests: 1, Per: time.Hour}
+	take := func(key string) RateLimitDecision {
+		d, _ := store.Take(context.Background(), key, limit, now)
+		return d
+	}
+
+	for i := 0; i < 10; i++ {
+		take(fmt.Sprintf("ip:10.0.0.%d", i))
+	}
+	if n := store.Len(); n != 3 {
+		t.Fatalf("store holds %d buckets, want 3", n)
+	}
+
+	// The most recently used buckets survive, the oldest is evicted
+	if take("ip:10.0.0.9").Allowed {
+		t.Error("recent bucket was evicted")
+	}
+	take("ip:10.0.0.a")
+	if !take("ip:10.0.0.7").Allowed {
+		t.Error("least recently used bucket was kept")
+	}
+}
+
+func TestMemoryRateLimitStoreSweep(t *testing.T) {
+	store := NewMemoryRateLimitStore(0)
+	start := time.Now()
+	store.Take(context.Background(), "ip:a", RateLimit{Requests: 1, Per: time.Second}, start)
+	store.Take(context.Background(), "ip:b", RateLimit{Requests: 1, Per: time.Hour}, start)
+
+	// The sweep runs at most once a minute and drops only full buckets
+	store.Take(context.Background(), "ip:c", RateLimit{Requests: 1, Per: time.Hour}, start.Add(2*time.Minute))
+	if n := store.Len(); n != 2 {
+		t.Errorf("store holds %d buckets after sweep, want 2", n)
+	}
+}
+
+func TestRateLimitPolicy(t *testing.T) {
+	tests := []struct {
+		limit RateLimit
+		want  string
+	}{
+		{RateLimit{Requests: 30, Per: time.Minute, Burst: 10}, "30;w=60;burst=10"},
+		{RateLimit{Requests: 600, Per: time.Minute}, "600;w=60;burst=600"},
+		{RateLimit{Requests: 20, Per: time.Hour, Burst: 5}, "20;w=3600;burst=5"},
+	}
+	for _, tt := range tests {
+		if got := tt.limit.policy(); got != tt.want {
+			t.Errorf("policy(%v) = %q, want %q", tt.limit, got, tt.want)
+		}
+	}
+}
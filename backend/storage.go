@@ -0,0 +1,408 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// BlobStore stores uploaded files by key. Downloads go through short-lived
+// signed URLs so the API never has to proxy file contents.
+type BlobStore interface {
+	Put(ctx context.Context, key, contentType string, body io.Reader, size int64) error
+	Delete(ctx context.Context, key string) error
+	SignedURL(key string, expires time.Duration) (string, error)
+}
+
+// blobStore is the configured store, set by initStorage
+var blobStore BlobStore
+
+// signedURLExpiry is how long download links stay valid
+const signedURLExpiry = 15 * time.Minute
+
+// validBlobKey rejects keys that could escape the store's root
+func validBlobKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	return path.Clean(key) == key && !strings.HasPrefix(key, "../") && key != ".."
+}
+
+// LocalBlobStore keeps files on disk. Signed URLs point at
+// GET /api/media/blob/{key} and carry an HMAC of the key and expiry.
+// Intended for development and tests.
+type LocalBlobStore struct {
+	Dir     string
+	BaseURL string // Prefix for download links, e.g. "http://localhost:8080"
+	Secret  []byte
+}
+
+// NewLocalBlobStore creates dir if needed
+func NewLocalBlobStore(dir, baseURL string, secret []byte) (*LocalBlobStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &LocalBlobStore{Dir: dir, BaseURL: strings.TrimSuffix(baseURL, "/"), Secret: secret}, nil
+}
+
+func (s *LocalBlobStore) path(key string) (string, error) {
+	if !validBlobKey(key) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(key)), nil
+}
+
+func (s *LocalBlobStore) Put(ctx context.Context, key, contentType string, body io.Reader, size int64) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see a partial upload
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func (s *LocalBlobStore) Delete(ctx context.Context, key string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalBlobStore) signature(key string, expires int64) string {
+	mac := hmac.New(sha256.New, s.Secret)
+	fmt.Fprintf(mac, "%s\n%d", key, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *LocalBlobStore) SignedURL(key string, expires time.Duration) (string, error) {
+	if !validBlobKey(key) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	expiresAt := time.Now().Add(expires).Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expiresAt, 10))
+	query.Set("signature", s.signature(key, expiresAt))
+	return s.BaseURL + "/api/media/blob/" + key + "?" + query.Encode(), nil
+}
+
+// serveLocalBlobHandler serves a file from the local store to anyone holding a valid signed URL
+func serveLocalBlobHandler(w http.ResponseWriter, r *http.Request) {
+	store, ok := blobStore.(*LocalBlobStore)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	key := mux.Vars(r)["key"]
+	expires, err := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		http.Error(w, "Link has expired", http.StatusForbidden)
+		return
+	}
+	if !hmac.Equal([]byte(r.URL.Query().Get("signature")), []byte(store.signature(key, expires))) {
+		http.Error(w, "Invalid signature", http.StatusForbidden)
+		return
+	}
+
+	target, err := store.path(key)
+	if err != nil {
+		http.Error(w, "Invalid key", http.StatusBadRequest)
+		return
+	}
+	file, err := os.Open(target)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		http.Error(w, "Error reading file", http.StatusInternalServerError)
+		return
+	}
+
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	http.ServeContent(w, r, path.Base(key), info.ModTime(), file)
+}
+
+// S3BlobStore talks to any S3-compatible service (AWS S3, MinIO, R2, ...)
+// using Signature Version 4.
+type S3BlobStore struct {
+	Endpoint  string // e.g. "https://s3.eu-west-1.amazonaws.com" or "http://localhost:9000"
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool // Address the bucket in the path rather than the host name (MinIO)
+	Client    *http.Client
+}
+
+// s3UnsignedPayload skips hashing request bodies, which S3 allows over any transport
+const s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// objectURL returns the URL of key, bucket addressed per PathStyle
+func (s *S3BlobStore) objectURL(key string) (*url.URL, error) {
+	if !validBlobKey(key) {
+		return nil, fmt.Errorf("invalid blob key %q", key)
+	}
+	u, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if s.PathStyle {
+		u.Path = "/" + s.Bucket + "/" + key
+	} else {
+		u.Host = s.Bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	return u, nil
+}
+
+// s3Escape encodes a URI component the way SigV4 expects
+func s3Escape(s string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (keepSlash && c == '/') {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// sign computes a SigV4 signature. headers must be lower-cased and include
+// host; payloadHash is the body's hex SHA-256 or s3UnsignedPayload.
+func (s *S3BlobStore) sign(method string, u *url.URL, query url.Values, headers map[string]string, payloadHash string, now time.Time) (signature, signedHeaders, scope string) {
+	date := now.Format("20060102")
+	scope = date + "/" + s.Region + "/s3/aws4_request"
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders = strings.Join(names, ";")
+
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, s3Escape(k, false)+"="+s3Escape(query.Get(k), false))
+	}
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		s3Escape(u.Path, true),
+		strings.Join(pairs, "&"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + now.Format("20060102T150405Z") + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), date)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign)), signedHeaders, scope
+}
+
+// do sends a signed request for key
+func (s *S3BlobStore) do(ctx context.Context, method, key string, body io.Reader, size int64, extra map[string]string) (*http.Response, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	headers := map[string]string{
+		"host":                 u.Host,
+		"x-amz-content-sha256": s3UnsignedPayload,
+		"x-amz-date":           now.Format("20060102T150405Z"),
+	}
+	for name, value := range extra {
+		headers[name] = value
+	}
+	signature, signedHeaders, scope := s.sign(method, u, nil, headers, s3UnsignedPayload, now)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+	for name, value := range headers {
+		if name != "host" {
+			req.Header.Set(name, value)
+		}
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, signedHeaders, signature))
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+func (s *S3BlobStore) Put(ctx context.Context, key, contentType string, body io.Reader, size int64) error {
+	resp, err := s.do(ctx, http.MethodPut, key, body, size, map[string]string{"content-type": contentType})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("s3 put %s: %s: %s", key, resp.Status, detail)
+	}
+	return nil
+}
+
+func (s *S3BlobStore) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, 0, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("s3 delete %s: %s", key, resp.Status)
+	}
+	return nil
+}
+
+// SignedURL returns a presigned GET URL
+func (s *S3BlobStore) SignedURL(key string, expires time.Duration) (string, error) {
+	return s.presign(key, expires, time.Now().UTC())
+}
+
+// presign builds a GET URL for key signed at now
+func (s *S3BlobStore) presign(key string, expires time.Duration, now time.Time) (string, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.AccessKey+"/"+now.Format("20060102")+"/"+s.Region+"/s3/aws4_request")
+	query.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	signature, _, _ := s.sign(http.MethodGet, u, query, map[string]string{"host": u.Host}, s3UnsignedPayload, now)
+	query.Set("X-Amz-Signature", signature)
+
+	// Encode the query exactly as it was signed
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, s3Escape(k, false)+"="+s3Escape(query.Get(k), false))
+	}
+	u.RawQuery = strings.Join(pairs, "&")
+	return u.String(), nil
+}
+
+// initStorage configures blobStore from storage.provider ("local" or "s3")
+func initStorage() error {
+	settings := appConfig.Storage
+	switch provider := settings.Provider; provider {
+	case "", "local":
+		dir := settings.LocalDir
+		if dir == "" {
+			dir = "uploads"
+		}
+		if settings.SigningSecret == "" {
+			return fmt.Errorf("storage.signingSecret (STORAGE_SIGNING_SECRET) is required for the local storage provider")
+		}
+		store, err := NewLocalBlobStore(dir, settings.PublicURL, []byte(settings.SigningSecret))
+		if err != nil {
+			return err
+		}
+		blobStore = store
+		log.Printf("Storage: storing uploads in %s", dir)
+	case "s3":
+		store := &S3BlobStore{
+			Endpoint:  settings.S3.Endpoint,
+			Region:    settings.S3.Region,
+			Bucket:    settings.S3.Bucket,
+			AccessKey: settings.S3.AccessKeyID,
+			SecretKey: settings.S3.SecretAccessKey,
+			PathStyle: settings.S3.PathStyle,
+			Client:    &http.Client{Timeout: 5 * time.Minute},
+		}
+		if store.Region == "" {
+			store.Region = "us-east-1"
+		}
+		if store.Endpoint == "" {
+			store.Endpoint = "https://s3." + store.Region + ".amazonaws.com"
+		}
+		if store.Bucket == "" || store.AccessKey == "" || store.SecretKey == "" {
+			return fmt.Errorf("storage.s3 bucket, accessKeyId and secretAccessKey are required for the s3 storage provider")
+		}
+		blobStore = store
+		log.Printf("Storage: storing uploads in bucket %s at %s", store.Bucket, store.Endpoint)
+	default:
+		return fmt.Errorf("unknown storage provider %q", provider)
+	}
+	return nil
+}
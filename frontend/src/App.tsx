@@ -10,7 +10,8 @@ import {
   Campaigns,
   ManageCampaign,
   Applications,
-  UserTypeSelection
+  UserTypeSelection,
+  PublicCampaign
 } from './components';
 import GenericDashboardRedirect from './components/GenericDashboardRedirect';
 import CreatorDashboard from './components/creator/CreatorDashboard';
@@ -25,6 +26,9 @@ function App() {
         <Router>
           <Routes>
             <Route path="/" element={<LandingPage />} />
+
+            {/* Shared campaign page - public, opened from share links */}
+            <Route path="/campaigns/:slug" element={<PublicCampaign />} />
             
             {/* User Type Selection - for new users */}
             <Route path="/select-user-type" element={
@@ -0,0 +1,81 @@
+import React, { useEffect, useState } from 'react';
+import { Link, useParams } from 'react-router-dom';
+import campaignService, { PublicCampaign as PublicCampaignData } from '../services/campaignService';
+
+// Public page for a shared campaign, reached from share links without login
+const PublicCampaign: React.FC = () => {
+  const { slug } = useParams<{ slug: string }>();
+  const [campaign, setCampaign] = useState<PublicCampaignData | null>(null);
+  const [error, setError] = useState<string | null>(null);
+
+  useEffect(() => {
+    if (!slug) return;
+    campaignService.getPublicCampaign(slug)
+      .then(setCampaign)
+      .catch((err: Error) => setError(err.message));
+  }, [slug]);
+
+  if (error) {
+    return (
+      <div className="min-h-screen flex flex-col items-center justify-center gap-4 bg-background text-foreground">
+        <p className="text-lg">{error}</p>
+        <Link to="/" className="text-primary underline">Go to the home page</Link>
+      </div>
+    );
+  }
+
+  if (!campaign) {
+    return (
+      <div className="min-h-screen flex items-center justify-center bg-background text-muted-foreground">
+        Loading campaign...
+      </div>
+    );
+  }
+
+  const details: [string, string | undefined][] = [
+    ['Category', campaign.category],
+    ['Campaign type', campaign.campaignType],
+    ['Dates', [campaign.startDate, campaign.endDate].filter(Boolean).join(' - ')],
+    ['Platforms', (campaign.platforms || []).join(', ')],
+    ['Content formats', (campaign.contentFormat || []).join(', ')],
+    ['Number of posts', campaign.numberOfPosts],
+    ['Creator tier', campaign.creatorTier],
+    ['Minimum followers', campaign.minimumFollowers],
+    ['Languages', (campaign.languages || []).join(', ')],
+    ['Location', campaign.targetLocation],
+    ['Compensation', campaign.compensationType],
+    ['Budget', campaign.budget && `${campaign.budget} ${campaign.currency || ''}`.trim()],
+    ['Payment', campaign.paymentAmount && `${campaign.paymentAmount} ${campaign.currency || ''}`.trim()],
+  ];
+
+  return (
+    <div className="min-h-screen bg-background text-foreground">
+      {campaign.bannerImageUrl && (
+        <img src={campaign.bannerImageUrl} alt="" className="w-full h-64 object-cover" />
+      )}
+      <div className="max-w-3xl mx-auto px-4 py-8">
+        <p className="text-sm text-muted-foreground">{campaign.brandName}</p>
+        <h1 className="text-3xl font-bold mb-4">{campaign.title}</h1>
+        <p className="whitespace-pre-line mb-8">{campaign.description}</p>
+
+        <dl className="grid grid-cols-1 sm:grid-cols-2 gap-4 mb-8">
+          {details.filter(([, value]) => value).map(([label, value]) => (
+            <div key={label}>
+              <dt className="text-sm text-muted-foreground">{label}</dt>
+              <dd className="font-medium">{value}</dd>
+            </div>
+          ))}
+        </dl>
+
+        <Link
+          to="/dashboard"
+          className="inline-block px-6 py-3 bg-primary text-primary-foreground rounded-lg font-medium"
+        >
+          Sign in to apply
+        </Link>
+      </div>
+    </div>
+  );
+};
+
+export default PublicCampaign;
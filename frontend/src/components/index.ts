@@ -2,6 +2,7 @@
 export { default as LandingPage } from './LandingPage';
 export { default as ProtectedRoute } from './ProtectedRoute';
 export { default as UserTypeSelection } from './UserTypeSelection';
+export { default as PublicCampaign } from './PublicCampaign';
 
 // Brand Components
 export { default as BrandDashboard } from './brand/BrandDashboard';
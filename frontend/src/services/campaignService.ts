@@ -120,6 +120,34 @@ export interface PaymentRecord {
   dueDate: string;
 }
 
+// Read-only campaign shown on public share pages
+export interface PublicCampaign {
+  id: string;
+  slug: string;
+  title: string;
+  description: string;
+  category: string;
+  brandName: string;
+  status: string;
+  startDate: string;
+  endDate: string;
+  campaignType: string;
+  platforms: string[] | null;
+  contentFormat: string[] | null;
+  numberOfPosts: string;
+  creatorTier: string;
+  minimumFollowers: string;
+  languages: string[] | null;
+  targetLocation: string;
+  compensationType: string;
+  bannerImageUrl?: string;
+  budget?: string; // Only when the brand shares it
+  paymentAmount?: string;
+  currency?: string;
+  views: number;
+  url: string;
+}
+
 class CampaignService {
   // Get all active campaigns for browsing (for creators)
   async getAllCampaigns(token?: string): Promise<Campaign[]> {
@@ -431,6 +459,21 @@ class CampaignService {
       throw new Error(error || 'Failed to apply to campaign');
     }
   }
+
+  // Get a shared campaign by its public slug; no login needed
+  async getPublicCampaign(slug: string): Promise<PublicCampaign> {
+    const response = await fetch(`${API_BASE_URL}/public/campaigns/${encodeURIComponent(slug)}`);
+
+    if (!response.ok) {
+      if (response.status === 404) {
+        throw new Error('This campaign is not shared or no longer exists.');
+      }
+      const error = await response.text();
+      throw new Error(error || 'Failed to fetch campaign');
+    }
+
+    return response.json();
+  }
 }
 
 const campaignService = new CampaignService();